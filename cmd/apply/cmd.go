@@ -0,0 +1,144 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/manifest"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+const (
+	_actionCreated    = "created"
+	_actionConfigured = "configured"
+	_actionUnchanged  = "unchanged"
+)
+
+// NewCommand creates the apply sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply -f FILENAME",
+		Short: "Apply resource manifests to API7 Cloud",
+		Example: `
+cloud-cli apply -f resources.yaml
+cloud-cli apply -f ./manifests/ -f extra.json`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.CheckConfigurationAndInitCloudClient(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Apply.Validate(); err != nil {
+				output.Errorf(err.Error())
+			}
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			objects, err := manifest.Load(options.Global.Apply.Filenames...)
+			if err != nil {
+				output.Errorf(err.Error())
+			}
+			if len(objects) == 0 {
+				output.Errorf("no objects found in the manifests")
+			}
+			manifest.SortByDependency(objects)
+
+			cluster, err := cloud.Client().GetDefaultCluster()
+			if err != nil {
+				output.Errorf("Failed to get default cluster: %s", err)
+			}
+
+			var (
+				failures int
+				// services created in the dry run mode, routes which refer
+				// to them cannot be resolved.
+				plannedServices = make(map[string]struct{})
+			)
+			for _, obj := range objects {
+				action, err := apply(cloud.Client(), cluster.ID, obj, plannedServices)
+				if err != nil {
+					failures++
+					output.Warnf("%s (%s): %s", obj, obj.Source, err)
+					continue
+				}
+				if options.Global.DryRun {
+					fmt.Printf("%s %s (dry run)\n", obj, action)
+				} else {
+					fmt.Printf("%s %s\n", obj, action)
+				}
+			}
+			if failures > 0 {
+				output.Errorf("%d of %d objects failed to apply", failures, len(objects))
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Profile, "profile", "", "The profile of the configuration to use")
+	cmd.Flags().StringSliceVarP(&options.Global.Apply.Filenames, "filename", "f", nil, "The manifest files or directories which contain the resources, \"-\" means the standard input")
+
+	return cmd
+}
+
+func apply(api cloud.API, clusterID sdk.ID, obj *manifest.Object, plannedServices map[string]struct{}) (string, error) {
+	if obj.Kind == manifest.KindRoute && obj.Route.AppID == 0 {
+		if _, ok := plannedServices[obj.ServiceName]; ok {
+			return _actionCreated, nil
+		}
+		if err := manifest.ResolveServiceID(api, clusterID, obj); err != nil {
+			return "", err
+		}
+	}
+
+	live, err := manifest.FindLive(api, clusterID, obj)
+	if err != nil {
+		return "", err
+	}
+
+	if live == nil {
+		if options.Global.DryRun {
+			if obj.Kind == manifest.KindService {
+				plannedServices[obj.Service.Name] = struct{}{}
+			}
+			return _actionCreated, nil
+		}
+		if _, err = manifest.Create(api, clusterID, obj); err != nil {
+			return "", err
+		}
+		return _actionCreated, nil
+	}
+
+	unchanged, err := manifest.Unchanged(obj, live)
+	if err != nil {
+		return "", err
+	}
+	if unchanged {
+		return _actionUnchanged, nil
+	}
+	if !options.Global.DryRun {
+		if _, err = manifest.Update(api, clusterID, obj); err != nil {
+			return "", err
+		}
+	}
+	return _actionConfigured, nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
+)
+
+const _manifest = `
+kind: route
+name: list-users
+service: users
+methods: [GET]
+paths:
+- path: /users
+  path_type: Exact
+---
+kind: service
+name: users
+path_prefix: /v1
+---
+kind: consumer
+name: jack
+description: the first consumer
+`
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		mockCloud func(api *cloud.MockAPI)
+		outputs   []string
+	}{
+		{
+			name: "create, update and skip objects",
+			args: []string{"-f", filepath.Join(os.TempDir(), "apply-manifest.yaml")},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				gomock.InOrder(
+					api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(nil),
+					api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+						&sdk.Application{
+							ID:              2,
+							ApplicationSpec: sdk.ApplicationSpec{Name: "users", PathPrefix: "/v1"},
+						},
+					)),
+				)
+				api.EXPECT().CreateService(sdk.ID(1), gomock.Any()).Return(&sdk.Application{
+					ID:              2,
+					ApplicationSpec: sdk.ApplicationSpec{Name: "users", PathPrefix: "/v1"},
+				}, nil)
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{
+						ID:    3,
+						AppID: 2,
+						APISpec: sdk.APISpec{
+							Name:    "list-users",
+							Methods: []string{"GET"},
+							Paths:   []sdk.APIPath{{Path: "/users", PathType: "Exact"}},
+						},
+					},
+				))
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Consumer{ID: 4, Name: "jack"},
+				))
+				api.EXPECT().UpdateConsumer(sdk.ID(1), gomock.Any()).DoAndReturn(func(_ sdk.ID, consumer *sdk.Consumer) (*sdk.Consumer, error) {
+					if consumer.ID != 4 {
+						return nil, fmt.Errorf("unexpected consumer id %s", consumer.ID)
+					}
+					return consumer, nil
+				})
+			},
+			outputs: []string{
+				"service/users created\n",
+				"route/list-users unchanged\n",
+				"consumer/jack configured\n",
+			},
+		},
+		{
+			name: "dry run",
+			args: []string{"-f", filepath.Join(os.TempDir(), "apply-manifest.yaml"), "--dry-run"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(nil)
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).Return(nil)
+			},
+			outputs: []string{
+				"service/users created (dry run)\n",
+				"route/list-users created (dry run)\n",
+				"consumer/jack created (dry run)\n",
+			},
+		},
+		{
+			name: "partial failure",
+			args: []string{"-f", filepath.Join(os.TempDir(), "apply-manifest.yaml")},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(fmt.Errorf("mock error")).Times(2)
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).Return(nil)
+				api.EXPECT().CreateConsumer(sdk.ID(1), gomock.Any()).Return(&sdk.Consumer{ID: 4, Name: "jack"}, nil)
+			},
+			outputs: []string{
+				"WARNING: service/users (",
+				"failed to list services: mock error",
+				"consumer/jack created\n",
+				"ERROR: 2 of 3 objects failed to apply",
+			},
+		},
+		{
+			name:    "missing filename",
+			outputs: []string{"ERROR: --filename is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := persistence.SaveConfiguration(&persistence.CloudConfiguration{
+				DefaultProfile: "default",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "default",
+						Address: "https://console.api7.cloud",
+						User: persistence.User{
+							AccessToken: "test-token",
+						},
+					},
+				},
+			})
+			assert.NoError(t, err, "prepare fake cloud configuration")
+
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				if tc.mockCloud != nil {
+					tc.mockCloud(api)
+				}
+				cloud.NewClient = func(_ string, _ string, _ bool) (cloud.API, error) {
+					return api, nil
+				}
+				cloud.DefaultClient = api
+
+				cmd := NewCommand()
+				cmd.PersistentFlags().BoolVar(&options.Global.DryRun, "dry-run", false, "")
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			err = os.WriteFile(filepath.Join(os.TempDir(), "apply-manifest.yaml"), []byte(_manifest), 0600)
+			assert.NoError(t, err, "write manifest")
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+		})
+	}
+}
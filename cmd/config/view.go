@@ -15,10 +15,8 @@
 package config
 
 import (
-	"os"
 	"strconv"
 
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/cloud"
@@ -27,6 +25,28 @@ import (
 	"github.com/api7/cloud-cli/internal/persistence"
 )
 
+// profileView is the summary of a profile shown by `cloud-cli config view`.
+type profileView struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Cluster      string `json:"cluster"`
+	IsDefault    bool   `json:"is_default"`
+	Address      string `json:"address"`
+}
+
+func init() {
+	output.RegisterTableDefinition("profile", output.TableDefinition{
+		Columns: []output.Column{
+			output.NewColumn("Profile Name", false, func(p *profileView) string { return p.Name }),
+			output.NewColumn("Organization", false, func(p *profileView) string { return p.Organization }),
+			output.NewColumn("Cluster", false, func(p *profileView) string { return p.Cluster }),
+			output.NewColumn("Is Default", false, func(p *profileView) string { return strconv.FormatBool(p.IsDefault) }),
+			output.NewColumn("API7 Cloud Address", false, func(p *profileView) string { return p.Address }),
+		},
+		Name: func(obj interface{}) string { return obj.(*profileView).Name },
+	})
+}
+
 func newViewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "view",
@@ -44,8 +64,7 @@ func newViewCommand() *cobra.Command {
 				output.Errorf(err.Error())
 			}
 
-			// output as ascii table
-			rows := [][]string{}
+			var profiles []*profileView
 			for _, profile := range config.Profiles {
 				var (
 					clusterName = "-"
@@ -55,6 +74,12 @@ func newViewCommand() *cobra.Command {
 				if api, err := cloud.NewClient(profile.Address, profile.User.AccessToken, options.Global.Verbose); err != nil {
 					output.Warnf("Failed to create API7 Cloud client for profile %s: %s", profile.Name, err.Error())
 				} else {
+					if profile.Org != "" {
+						api.SelectOrganization(profile.Org)
+					}
+					if profile.Cluster != "" {
+						api.SelectCluster(profile.Cluster)
+					}
 					if cluster, err := api.GetDefaultCluster(); err != nil {
 						output.Warnf("Failed to get default cluster for profile %s: %s", profile.Name, err.Error())
 					} else {
@@ -68,17 +93,16 @@ func newViewCommand() *cobra.Command {
 					}
 				}
 
-				rows = append(rows, []string{profile.Name, orgName, clusterName, strconv.FormatBool(profile.Name == config.DefaultProfile), profile.Address})
+				profiles = append(profiles, &profileView{
+					Name:         profile.Name,
+					Organization: orgName,
+					Cluster:      clusterName,
+					IsDefault:    profile.Name == config.DefaultProfile,
+					Address:      profile.Address,
+				})
 			}
 
-			table := tablewriter.NewWriter(os.Stdout)
-
-			table.SetHeader([]string{"Profile Name", "Organization", "Cluster", "Is Default", "API7 Cloud Address"})
-
-			for _, r := range rows {
-				table.Append(r)
-			}
-			table.Render()
+			output.PrintResource("profile", profiles, output.FormatTable)
 		},
 	}
 
@@ -83,6 +83,20 @@ func NewCommand() *cobra.Command {
 				output.Errorf("failed to request api7 cloud: %s", err)
 			}
 
+			if options.Global.Org != "" {
+				cloud.Client().SelectOrganization(options.Global.Org)
+				if _, err := cloud.Client().GetDefaultOrganization(); err != nil {
+					output.Errorf("failed to select organization: %s", err)
+				}
+			}
+
+			if options.Global.Cluster != "" {
+				cloud.Client().SelectCluster(options.Global.Cluster)
+				if _, err := cloud.Client().GetDefaultCluster(); err != nil {
+					output.Errorf("failed to select cluster: %s", err)
+				}
+			}
+
 			profileName := options.Global.Configure.Profile
 
 			configuration, err := persistence.LoadConfiguration()
@@ -110,6 +124,8 @@ func NewCommand() *cobra.Command {
 				User: persistence.User{
 					AccessToken: options.Global.Configure.AccessToken,
 				},
+				Org:     options.Global.Org,
+				Cluster: options.Global.Cluster,
 			}
 			configuration.ConfigureProfile(newProfile)
 
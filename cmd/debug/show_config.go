@@ -17,6 +17,7 @@ package debug
 import (
 	"fmt"
 
+	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/cloud"
@@ -25,13 +26,52 @@ import (
 	"github.com/api7/cloud-cli/internal/persistence"
 )
 
+var (
+	// _resourceKinds maps the debug resources to the resource kinds.
+	_resourceKinds = map[string]string{
+		"application": "service",
+		"api":         "route",
+		"consumer":    "consumer",
+		"certificate": "ssl",
+	}
+)
+
+// resolveID resolves the ID of the resource by its name, the service is
+// the application name, it's only required for the api resource.
+func resolveID(resource, name, service string) sdk.ID {
+	kind, ok := _resourceKinds[resource]
+	if !ok {
+		output.Errorf("Resolving %s by name is not supported", resource)
+	}
+	var serviceID sdk.ID
+	if kind == "route" {
+		if service == "" {
+			output.Errorf("--service is required to show api by name")
+		}
+		id, err := cloud.DefaultResolver().ResolveDefault("service", service, 0)
+		if err != nil {
+			output.Errorf("Failed to resolve application: %s", err.Error())
+		}
+		serviceID = id
+	}
+	id, err := cloud.DefaultResolver().ResolveDefault(kind, name, serviceID)
+	if err != nil {
+		output.Errorf("Failed to resolve %s: %s", resource, err.Error())
+	}
+	return id
+}
+
 func newShowConfigCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "show-config [RESOURCE] [ARG...]",
 		Short: "Show translated Apache APISIX configurations related to the specify API7 Cloud resource.",
 		Example: `
 cloud-cli debug show-config api \
-	--id 0e3851a5f4a7`,
+	--id 0e3851a5f4a7
+
+cloud-cli debug show-config api \
+	--name list-users \
+	--service users`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if err := persistence.Init(); err != nil {
 				output.Errorf(err.Error())
@@ -43,9 +83,16 @@ cloud-cli debug show-config api \
 				output.Errorf("Please specify an API7 Cloud resource. Resource can be application, api, consumer and certificate.")
 			}
 
-			id := options.Global.Debug.ShowConfig.ID
+			opts := options.Global.Debug.ShowConfig
+			id := opts.ID
+			if opts.Name != "" {
+				if id != 0 {
+					output.Errorf("--id and --name are mutually exclusive")
+				}
+				id = resolveID(args[0], opts.Name, opts.Service)
+			}
 			if id == 0 && args[0] != "cluster_settings" {
-				output.Errorf("Empty resource ID, please specify --id option")
+				output.Errorf("Empty resource ID, please specify --id or --name option")
 			}
 
 			defaultCluster, err := cloud.DefaultClient.GetDefaultCluster()
@@ -62,6 +109,8 @@ cloud-cli debug show-config api \
 	}
 
 	cmd.PersistentFlags().Uint64Var((*uint64)(&options.Global.Debug.ShowConfig.ID), "id", 0, "Specify the API7 Cloud resource ID")
+	cmd.PersistentFlags().StringVar(&options.Global.Debug.ShowConfig.Name, "name", "", "Specify the API7 Cloud resource name, it's resolved to the resource ID")
+	cmd.PersistentFlags().StringVar(&options.Global.Debug.ShowConfig.Service, "service", "", "Specify the application name of the api, it's required when showing api by name")
 
 	return cmd
 }
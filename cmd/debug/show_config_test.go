@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/testutils"
 )
 
 func TestDebugShowConfig(t *testing.T) {
@@ -64,6 +65,32 @@ func TestDebugShowConfig(t *testing.T) {
 				cloud.DefaultClient = api
 			},
 		},
+		{
+			name: "show api by name",
+			args: []string{"show-config", "api", "--name", "list-users", "--service", "users"},
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 12345}, nil).Times(2)
+				api.EXPECT().IterateServices(sdk.ID(12345), &sdk.Filter{Search: "users"}, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 2, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+				))
+				api.EXPECT().IterateRoutes(sdk.ID(12345), sdk.ID(2), &sdk.Filter{Search: "list-users"}, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 3, AppID: 2, APISpec: sdk.APISpec{Name: "list-users"}},
+				))
+				api.EXPECT().DebugShowConfig(sdk.ID(12345), "api", sdk.ID(3)).Return(`{"routes": []}`, nil)
+				cloud.DefaultClient = api
+			},
+			output: `{"routes": []}`,
+		},
+		{
+			name:         "show api by name without service",
+			args:         []string{"show-config", "api", "--name", "list-users"},
+			errorMessage: "ERROR: --service is required to show api by name\n",
+			mockCloud: func(t *testing.T) {
+				cloud.DefaultClient = cloud.NewMockAPI(gomock.NewController(t))
+			},
+		},
 		{
 			name: "show application related APISIX objects",
 			args: []string{"show-config", "application", "--id", "123"},
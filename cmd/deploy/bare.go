@@ -17,20 +17,33 @@ package deploy
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
+	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/consts"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/persistence"
 )
 
+const (
+	// _defaultHTTPPort is the HTTP port that APISIX listens on by default.
+	_defaultHTTPPort = 9080
+)
+
 var (
+	// _apisixErrorLogFile is the error log file of APISIX deployed on bare
+	// metal.
+	_apisixErrorLogFile = "/usr/local/apisix/logs/error.log"
+
 	//go:embed manifest/install.sh
 	_installScript string
 	_installer     *template.Template
@@ -121,6 +134,25 @@ cloud-cli deploy bare \
 			}
 
 			deployOnBareMetal(context, &ctx, &opts, configFile)
+
+			apisixID := options.Global.Deploy.APISIXInstanceID
+			if apisixID == "" {
+				// The installer generates the APISIX ID if it's not specified.
+				if data, err = os.ReadFile(_apisixIDFileOnBareMetal); err == nil {
+					apisixID = strings.TrimSpace(string(data))
+				}
+			}
+			deployment := &persistence.Deployment{
+				Name:      consts.DefaultDeploymentName,
+				Target:    persistence.DeploymentBare,
+				Image:     opts.APISIXVersion,
+				ClusterID: ctx.Cluster.ID,
+			}
+			if apisixID != "" {
+				deployment.APISIXIDs = []string{apisixID}
+			}
+			recordDeployment(deployment, mergedConfig)
+			waitForDeployment(context, bareReadiness(httpListenPort(mergedConfig), ctx.Cluster.ID, apisixID))
 		},
 	}
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Bare.APISIXVersion, "apisix-version", "2.15.0", "Specifies the APISIX version, default value is 2.15.0")
@@ -130,3 +162,46 @@ cloud-cli deploy bare \
 
 	return cmd
 }
+
+// bareReadiness returns the readiness checks for APISIX on bare metal.
+func bareReadiness(httpPort int, clusterID sdk.ID, apisixID string) *readiness {
+	return &readiness{
+		state: func(ctx context.Context) (string, bool, error) {
+			pid, running, err := apisix.MasterProcess()
+			if err != nil {
+				return "unknown", false, err
+			}
+			if !running {
+				return "stopped", false, nil
+			}
+			return fmt.Sprintf("running (pid %d)", pid), true, nil
+		},
+		address: fmt.Sprintf("127.0.0.1:%d", httpPort),
+		logs: func(ctx context.Context) (string, error) {
+			return tailFile(_apisixErrorLogFile, _readinessLogLines)
+		},
+		apisixIDs: staticAPISIXIDs(apisixID),
+		clusterID: clusterID,
+	}
+}
+
+// httpListenPort returns the first HTTP port that APISIX listens on.
+func httpListenPort(config map[string]interface{}) int {
+	if port := firstListenPort(nodeListen(config)); port > 0 {
+		return port
+	}
+	return _defaultHTTPPort
+}
+
+// tailFile returns the last n lines of the file.
+func tailFile(filename string, n int) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n"), nil
+}
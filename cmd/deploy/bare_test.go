@@ -96,7 +96,7 @@ fi
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				{
@@ -169,7 +169,7 @@ fi
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				{
@@ -243,7 +243,7 @@ fi
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				{
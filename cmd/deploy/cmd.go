@@ -15,6 +15,8 @@
 package deploy
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/options"
@@ -38,6 +40,10 @@ func NewCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Name, "name", "apisix", "The identifier of this deployment, it would be the container name (on Docker), the helm release (on Kubernetes) and it's useless if APISIX is deployed on bare metal")
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.APISIXConfigFile, "apisix-config", "", "Specify the custom APISIX configuration file")
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.APISIXInstanceID, "apisix-id", "", "Specify the custom APISIX instance ID")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.ModuleBundle, "module-bundle", "", "Specify the cloud lua module bundle (in the tar.gz format) to use instead of downloading it from API7 Cloud, it's useful for air-gapped installations")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.ModuleChecksum, "module-checksum", "", "Specify the expected SHA-256 checksum of the cloud lua module (or the --module-bundle)")
+	cmd.PersistentFlags().BoolVar(&options.Global.Deploy.Wait, "wait", true, "Wait until APISIX is ready, i.e. the workload is running, the data plane port is reachable and the instance is registered to API7 Cloud")
+	cmd.PersistentFlags().DurationVar(&options.Global.Deploy.Timeout, "timeout", 3*time.Minute, "Specify the maximum duration to wait for APISIX to be ready, it only works with --wait")
 
 	cmd.AddCommand(newDockerCommand())
 	cmd.AddCommand(newBareCommand())
@@ -0,0 +1,241 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/container"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+// bindMount mounts a host file or directory into the container.
+type bindMount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+	// Shared indicates whether the source is shared with other containers,
+	// it affects the SELinux label on Podman.
+	Shared bool
+}
+
+// composeFile is the subset of the Compose specification which is used by
+// the generated file.
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+}
+
+// composeService is a service of the Compose file.
+type composeService struct {
+	Image         string          `yaml:"image"`
+	ContainerName string          `yaml:"container_name"`
+	Hostname      string          `yaml:"hostname"`
+	Restart       string          `yaml:"restart,omitempty"`
+	NetworkMode   string          `yaml:"network_mode,omitempty"`
+	Environment   []string        `yaml:"environment,omitempty"`
+	Labels        []string        `yaml:"labels,omitempty"`
+	ExtraHosts    []string        `yaml:"extra_hosts,omitempty"`
+	Ports         []quotedString  `yaml:"ports,omitempty"`
+	Volumes       []composeVolume `yaml:"volumes,omitempty"`
+}
+
+// composeVolume is a volume of the service in the long syntax.
+type composeVolume struct {
+	Type     string       `yaml:"type"`
+	Source   string       `yaml:"source,omitempty"`
+	Target   string       `yaml:"target"`
+	ReadOnly bool         `yaml:"read_only,omitempty"`
+	Bind     *composeBind `yaml:"bind,omitempty"`
+}
+
+type composeBind struct {
+	SELinux string `yaml:"selinux,omitempty"`
+}
+
+// quotedString is always quoted in YAML, the port mappings like 22:22 are
+// parsed as numbers in base 60 by the YAML 1.1 parsers if they're not quoted.
+type quotedString string
+
+func (s quotedString) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Style: yaml.DoubleQuotedStyle,
+		Value: string(s),
+	}, nil
+}
+
+// newComposeService creates the compose service of the APISIX container, it
+// has the same mounts, ports and (the supported) docker run arguments as the
+// container run by docker run.
+func newComposeService(runtime container.Runtime, name, image string, mounts []bindMount, ports []portMapping, runArgs []string) *composeService {
+	service := &composeService{
+		Image:         image,
+		ContainerName: name,
+		Hostname:      name,
+	}
+	var unsupported []string
+	for _, arg := range runArgs {
+		if !service.applyRunArg(arg) {
+			unsupported = append(unsupported, arg)
+		}
+	}
+	if len(unsupported) > 0 {
+		output.Warnf("The docker run arguments %s are not supported by the compose file, ignored", strings.Join(unsupported, ", "))
+	}
+
+	for _, mount := range mounts {
+		volume := composeVolume{
+			Type:     "bind",
+			Source:   mount.Source,
+			Target:   mount.Target,
+			ReadOnly: mount.ReadOnly,
+		}
+		if runtime == container.Podman {
+			// Keep the same SELinux labels as the container run by podman run.
+			volume.Bind = &composeBind{SELinux: "Z"}
+			if mount.Shared {
+				volume.Bind.SELinux = "z"
+			}
+		}
+		service.Volumes = append(service.Volumes, volume)
+	}
+	for _, port := range ports {
+		service.Ports = append(service.Ports, quotedString(port.String()))
+	}
+	return service
+}
+
+// applyRunArg translates the docker run argument (in the format of
+// name=value) to the service, it returns false if the argument is not
+// supported.
+func (s *composeService) applyRunArg(arg string) bool {
+	name, value, found := strings.Cut(arg, "=")
+	switch name {
+	case "--detach", "-d":
+		// The compose services are always run in the background by Cloud CLI.
+		return !found
+	case "--hostname", "-h", "--name":
+		// They're always the deployment name.
+		return found
+	}
+	if !found {
+		return false
+	}
+
+	switch name {
+	case "--env", "-e":
+		s.Environment = append(s.Environment, value)
+	case "--label", "-l":
+		s.Labels = append(s.Labels, value)
+	case "--add-host":
+		s.ExtraHosts = append(s.ExtraHosts, value)
+	case "--restart":
+		s.Restart = value
+	case "--network", "--net":
+		s.NetworkMode = value
+	case "--volume", "-v":
+		volume, ok := parseVolumeArg(value)
+		if !ok {
+			return false
+		}
+		s.Volumes = append(s.Volumes, volume)
+	case "--mount":
+		volume, ok := parseMountArg(value)
+		if !ok {
+			return false
+		}
+		s.Volumes = append(s.Volumes, volume)
+	default:
+		return false
+	}
+	return true
+}
+
+// parseVolumeArg parses the value of the docker run --volume option, i.e.
+// [source:]target[:options].
+func parseVolumeArg(value string) (composeVolume, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) == 1 {
+		return composeVolume{Type: "volume", Target: parts[0]}, true
+	}
+	if len(parts) > 3 {
+		return composeVolume{}, false
+	}
+	volume := composeVolume{
+		Type:   "volume",
+		Source: parts[0],
+		Target: parts[1],
+	}
+	if filepath.IsAbs(volume.Source) || strings.HasPrefix(volume.Source, ".") {
+		volume.Type = "bind"
+	}
+	if len(parts) == 3 {
+		for _, option := range strings.Split(parts[2], ",") {
+			switch option {
+			case "ro":
+				volume.ReadOnly = true
+			case "rw":
+			case "z", "Z":
+				volume.Bind = &composeBind{SELinux: option}
+			default:
+				return composeVolume{}, false
+			}
+		}
+	}
+	return volume, true
+}
+
+// parseMountArg parses the value of the docker run --mount option, e.g.
+// type=bind,source=/etc/hosts,target=/etc/hosts,readonly.
+func parseMountArg(value string) (composeVolume, bool) {
+	volume := composeVolume{Type: "volume"}
+	for _, field := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(field, "=")
+		switch key {
+		case "type":
+			volume.Type = val
+		case "source", "src":
+			volume.Source = val
+		case "target", "destination", "dst":
+			volume.Target = val
+		case "readonly", "ro":
+			volume.ReadOnly = val == "" || val == "true" || val == "1"
+		default:
+			return composeVolume{}, false
+		}
+	}
+	return volume, volume.Target != ""
+}
+
+// renderCompose renders the Compose file with the single service.
+func renderCompose(name string, service *composeService) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&composeFile{
+		Services: map[string]*composeService{
+			name: service,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal compose file")
+	}
+	return buf.Bytes(), nil
+}
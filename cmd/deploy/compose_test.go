@@ -0,0 +1,172 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/container"
+)
+
+func TestRenderCompose(t *testing.T) {
+	mounts := []bindMount{
+		{Source: "/opt/apisix-config.yaml", Target: "/usr/local/apisix/conf/config.yaml", ReadOnly: true},
+		{Source: "/root/.api7cloud/tls", Target: "/cloud/tls", ReadOnly: true, Shared: true},
+		{Source: "/opt/apisix.data", Target: "/usr/local/apisix/conf/apisix.data"},
+	}
+	ports := []portMapping{
+		{HostPort: 9080, ContainerPort: 9080, Protocol: "tcp"},
+		{HostPort: 9200, ContainerPort: 9200, Protocol: "udp"},
+	}
+	runArgs := []string{
+		"--detach",
+		"--env=APISIX_STAND_ALONE=false",
+		"--restart=unless-stopped",
+		"--add-host=upstream:10.0.0.1",
+		"--mount=type=bind,source=/etc/hosts,target=/etc/hosts,readonly",
+		"--volume=apisix-logs:/usr/local/apisix/logs",
+		"--hostname=apisix-1",
+		"--cpus=2",
+	}
+
+	testCases := []struct {
+		name     string
+		runtime  container.Runtime
+		expected string
+	}{
+		{
+			name:    "docker",
+			runtime: container.Docker,
+			expected: `services:
+  apisix:
+    image: apache/apisix:2.15.0-centos
+    container_name: apisix
+    hostname: apisix
+    restart: unless-stopped
+    environment:
+      - APISIX_STAND_ALONE=false
+    extra_hosts:
+      - upstream:10.0.0.1
+    ports:
+      - "9080:9080"
+      - "9200:9200/udp"
+    volumes:
+      - type: bind
+        source: /etc/hosts
+        target: /etc/hosts
+        read_only: true
+      - type: volume
+        source: apisix-logs
+        target: /usr/local/apisix/logs
+      - type: bind
+        source: /opt/apisix-config.yaml
+        target: /usr/local/apisix/conf/config.yaml
+        read_only: true
+      - type: bind
+        source: /root/.api7cloud/tls
+        target: /cloud/tls
+        read_only: true
+      - type: bind
+        source: /opt/apisix.data
+        target: /usr/local/apisix/conf/apisix.data
+`,
+		},
+		{
+			name:    "podman",
+			runtime: container.Podman,
+			expected: `services:
+  apisix:
+    image: apache/apisix:2.15.0-centos
+    container_name: apisix
+    hostname: apisix
+    restart: unless-stopped
+    environment:
+      - APISIX_STAND_ALONE=false
+    extra_hosts:
+      - upstream:10.0.0.1
+    ports:
+      - "9080:9080"
+      - "9200:9200/udp"
+    volumes:
+      - type: bind
+        source: /etc/hosts
+        target: /etc/hosts
+        read_only: true
+      - type: volume
+        source: apisix-logs
+        target: /usr/local/apisix/logs
+      - type: bind
+        source: /opt/apisix-config.yaml
+        target: /usr/local/apisix/conf/config.yaml
+        read_only: true
+        bind:
+          selinux: Z
+      - type: bind
+        source: /root/.api7cloud/tls
+        target: /cloud/tls
+        read_only: true
+        bind:
+          selinux: z
+      - type: bind
+        source: /opt/apisix.data
+        target: /usr/local/apisix/conf/apisix.data
+        bind:
+          selinux: Z
+`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			service := newComposeService(tc.runtime, "apisix", "apache/apisix:2.15.0-centos", mounts, ports, runArgs)
+			data, err := renderCompose("apisix", service)
+			assert.NoError(t, err, "render compose file")
+			assert.Equal(t, tc.expected, string(data), "check the compose file")
+		})
+	}
+}
+
+func TestComposeServiceApplyRunArg(t *testing.T) {
+	testCases := []struct {
+		arg       string
+		supported bool
+		expected  composeService
+	}{
+		{arg: "--detach", supported: true},
+		{arg: "--name=apisix-1", supported: true},
+		{arg: "-e=FOO=bar", supported: true, expected: composeService{Environment: []string{"FOO=bar"}}},
+		{arg: "--label=team=gateway", supported: true, expected: composeService{Labels: []string{"team=gateway"}}},
+		{arg: "--network=host", supported: true, expected: composeService{NetworkMode: "host"}},
+		{arg: "--volume=/data", supported: true, expected: composeService{Volumes: []composeVolume{{Type: "volume", Target: "/data"}}}},
+		{arg: "-v=./conf:/conf:ro,z", supported: true, expected: composeService{Volumes: []composeVolume{{Type: "bind", Source: "./conf", Target: "/conf", ReadOnly: true, Bind: &composeBind{SELinux: "z"}}}}},
+		{arg: "--volume=/a:/b:delegated"},
+		{arg: "--mount=type=tmpfs,destination=/tmp", supported: true, expected: composeService{Volumes: []composeVolume{{Type: "tmpfs", Target: "/tmp"}}}},
+		{arg: "--mount=type=bind,source=/a,target=/b,bind-propagation=rshared"},
+		{arg: "--env"},
+		{arg: "--privileged"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.arg, func(t *testing.T) {
+			var service composeService
+			assert.Equal(t, tc.supported, service.applyRunArg(tc.arg), "check if the arg is supported")
+			if tc.supported {
+				assert.Equal(t, tc.expected, service, "check the service")
+			}
+		})
+	}
+}
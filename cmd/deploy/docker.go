@@ -19,13 +19,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/apisix"
 	"github.com/api7/cloud-cli/internal/commands"
 	"github.com/api7/cloud-cli/internal/consts"
+	"github.com/api7/cloud-cli/internal/container"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/persistence"
@@ -34,23 +38,60 @@ import (
 
 func newDockerCommand() *cobra.Command {
 	var (
-		ctx deployContext
+		ctx   deployContext
+		ports *portPlan
 	)
 	cmd := &cobra.Command{
 		Use:   "docker [ARGS...]",
 		Short: "Deploy Apache APISIX to the Docker container",
+		Long: `Deploy Apache APISIX to the Docker container.
+It also supports Podman and containerd (by nerdctl) with the --runtime option,
+both of them can be rootless.`,
 		Example: `
 cloud-cli deploy docker \
 		--name apisix-0 \
 		--apisix-image apache/apisix:2.15.0-centos \
 		--docker-run-arg --detach \
 		--docker-run-arg --mount=type=bind,source=/etc/hosts,target=/etc/hosts,readonly \
-		--docker-run-arg --hostname=apisix-1`,
+		--docker-run-arg --hostname=apisix-1
+
+cloud-cli deploy docker \
+		--runtime podman \
+		--http-host-port 8080 \
+		--https-host-port 8443
+
+cloud-cli deploy docker \
+		--name apisix \
+		--replicas 3
+
+cloud-cli deploy docker \
+		--name apisix \
+		--compose-out ./docker-compose.yaml \
+		--compose-up`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if err := options.Global.Deploy.Docker.Validate(); err != nil {
 				output.Errorf(err.Error())
 				return
 			}
+			if options.Global.Deploy.Docker.Replicas > 1 && options.Global.Deploy.APISIXInstanceID != "" {
+				output.Errorf("--apisix-id cannot be used with --replicas, the APISIX instances should have different IDs")
+				return
+			}
+			runtime, err := container.ParseRuntime(options.Global.Deploy.Docker.Runtime)
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			if ports, err = newPortPlan(&options.Global.Deploy.Docker); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			ports.OverrideHTTP = cmd.Flags().Changed("http-port")
+			ports.OverrideHTTPS = cmd.Flags().Changed("https-port")
+			if err = ports.checkHostPorts(runtime); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
 
 			if err := persistence.Init(); err != nil {
 				output.Errorf(err.Error())
@@ -67,12 +108,13 @@ cloud-cli deploy docker \
 				err  error
 			)
 			opts := options.Global.Deploy.Docker
-			docker := getDockerCommand()
-			docker.AppendArgs("run")
+			// runArgs are recorded so that the container can be recreated
+			// by the upgrade command.
+			var runArgs []string
 			for _, args := range opts.DockerRunArgs {
-				docker.AppendArgs(strings.SplitN(args, "=", 2)...)
+				runArgs = append(runArgs, strings.SplitN(args, "=", 2)...)
 			}
-			docker.AppendArgs("--detach")
+			runArgs = append(runArgs, "--detach")
 
 			if options.Global.Deploy.APISIXConfigFile != "" {
 				data, err = os.ReadFile(options.Global.Deploy.APISIXConfigFile)
@@ -86,51 +128,49 @@ cloud-cli deploy docker \
 				output.Errorf(err.Error())
 				return
 			}
+			ports.applyTo(mergedConfig)
+			if unknown := ports.unknownListeners(mergedConfig); len(unknown) > 0 {
+				output.Warnf("APISIX doesn't listen on the container ports %s, please configure the listeners by --apisix-config", strings.Join(unknown, ", "))
+			}
+			runtime, _ := container.ParseRuntime(opts.Runtime)
+			name := options.Global.Deploy.Name
+			if name == "" {
+				name = consts.DefaultDeploymentName
+			}
+
+			var configFile string
 			if len(mergedConfig) > 0 {
-				configFile, err := apisix.SaveConfigToTemp(mergedConfig, "apisix-config-*.yaml")
-				if err != nil {
+				if configFile, err = saveDockerConfig(mergedConfig, name); err != nil {
 					output.Errorf(err.Error())
 					return
 				}
-				docker.AppendArgs("--mount", "type=bind,source="+configFile+",target=/usr/local/apisix/conf/config.yaml,readonly")
-			}
-			docker.AppendArgs("--mount", "type=bind,source="+ctx.cloudLuaModuleDir+",target=/cloud_lua_module,readonly")
-			docker.AppendArgs("--mount", "type=bind,source="+ctx.tlsDir+",target=/cloud/tls,readonly")
-			docker.AppendArgs("--mount", "type=bind,source="+ctx.apisixIDFile+",target=/usr/local/apisix/conf/apisix.uid,readonly")
-			// For cloud_lua_module/apisix/cli/*.lua, we have to mount them to the /usr/local/apisix/apisix/cli/ directory. Or
-			// they cannot be loaded as the /usr/local/apisix/apisix/cli/apisix.lua puts /usr/local/apisix as the first item
-			// in package.path.
-			{
-				sourcePath := filepath.Join(ctx.cloudLuaModuleDir, "apisix", "cli", "etcd.ljbc")
-				docker.AppendArgs("--mount", "type=bind,source="+sourcePath+",target=/usr/local/apisix/apisix/cli/etcd.lua,readonly")
 			}
-			{
-				sourcePath := filepath.Join(ctx.cloudLuaModuleDir, "apisix", "cli", "local_storage.ljbc")
-				docker.AppendArgs("--mount", "type=bind,source="+sourcePath+",target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly")
-			}
-
-			if options.Global.Deploy.Docker.LocalCacheBindPath != "" {
-				docker.AppendArgs("--mount", "type=bind,source="+options.Global.Deploy.Docker.LocalCacheBindPath+",target=/usr/local/apisix/conf/apisix.data")
-			}
-
-			// TODO support customization of the HTTP and HTTPS ports.
-			docker.AppendArgs("-p", fmt.Sprintf("%d:9080", options.Global.Deploy.Docker.HTTPHostPort))
-			docker.AppendArgs("-p", fmt.Sprintf("%d:9443", options.Global.Deploy.Docker.HTTPSHostPort))
-
-			if options.Global.Deploy.Name != "" {
-				docker.AppendArgs("--name", options.Global.Deploy.Name)
-				docker.AppendArgs("--hostname", options.Global.Deploy.Name)
-			} else {
-				docker.AppendArgs("--name", consts.DefaultDeploymentName)
-				docker.AppendArgs("--hostname", consts.DefaultDeploymentName)
-			}
-
-			docker.AppendArgs(opts.APISIXImage)
+			image := runtime.QualifyImage(opts.APISIXImage)
 
-			if options.Global.DryRun {
-				output.Infof("Running:\n%s\n", docker.String())
-			} else {
-				output.Verbosef("Running:\n%s\n", docker.String())
+			var instances []*dockerInstance
+			for i := 0; i < ports.Replicas; i++ {
+				instance := &dockerInstance{
+					name:  name,
+					ports: ports.forReplica(i),
+				}
+				if ports.Replicas > 1 {
+					instance.name = fmt.Sprintf("%s-%d", name, i)
+				}
+				var idFile string
+				instance.apisixID, idFile, err = saveDockerAPISIXID(instance.name, options.Global.Deploy.APISIXInstanceID)
+				if err != nil {
+					output.Errorf(err.Error())
+					return
+				}
+				instance.mounts = dockerMounts(&ctx, configFile, idFile, ports.Replicas > 1)
+				instance.runArgs = append([]string{}, runArgs...)
+				for _, mount := range instance.mounts {
+					instance.runArgs = append(instance.runArgs, runtime.BindMount(mount.Source, mount.Target, mount.ReadOnly, mount.Shared)...)
+				}
+				instance.runArgs = append(instance.runArgs, instance.ports.publishArgs()...)
+				instance.runArgs = append(instance.runArgs, "--name", instance.name)
+				instance.runArgs = append(instance.runArgs, "--hostname", instance.name)
+				instances = append(instances, instance)
 			}
 
 			newctx, cancel := context.WithCancel(context.TODO())
@@ -138,44 +178,221 @@ cloud-cli deploy docker \
 				cancel()
 			})
 
-			stdout, stderr, err := docker.Run(newctx)
-			if stderr != "" {
-				output.Warnf(stderr)
+			for _, instance := range instances {
+				var docker commands.Cmd
+				if opts.ComposeOut != "" {
+					service := newComposeService(runtime, instance.name, image, instance.mounts, instance.ports.mappings(), opts.DockerRunArgs)
+					if err = writeCompose(opts.ComposeOut, instance.name, service); err != nil {
+						output.Errorf(err.Error())
+						return
+					}
+					if !opts.ComposeUp {
+						return
+					}
+					docker = getDockerCommand()
+					docker.AppendArgs("compose", "--file", opts.ComposeOut, "up", "--detach")
+				} else {
+					docker = getDockerCommand()
+					docker.AppendArgs("run")
+					docker.AppendArgs(instance.runArgs...)
+					docker.AppendArgs(image)
+				}
+
+				if options.Global.DryRun {
+					output.Infof("Running:\n%s\n", docker.String())
+				} else {
+					output.Verbosef("Running:\n%s\n", docker.String())
+				}
+
+				stdout, stderr, err := docker.Run(newctx)
+				if stderr != "" {
+					output.Warnf(stderr)
+				}
+				if stdout != "" {
+					output.Verbosef(stdout)
+				}
+				if err != nil {
+					output.Errorf(err.Error())
+					return
+				}
+				// The deployment is recorded even if it's not ready, so that it
+				// can be inspected and stopped by other commands.
+				deployment := &persistence.Deployment{
+					Name:      instance.name,
+					Target:    persistence.DeploymentDocker,
+					Image:     image,
+					APISIXIDs: []string{instance.apisixID},
+					ClusterID: ctx.Cluster.ID,
+					Runtime:   string(runtime),
+					RunArgs:   instance.runArgs,
+				}
+				if ports.Replicas > 1 {
+					deployment.ReplicaOf = name
+				}
+				if opts.ComposeOut != "" {
+					// The container is managed by Docker Compose, it shouldn't
+					// be recreated by docker run.
+					deployment.RunArgs = nil
+					if deployment.ComposeFile, err = filepath.Abs(opts.ComposeOut); err != nil {
+						deployment.ComposeFile = opts.ComposeOut
+					}
+				}
+				recordDeployment(deployment, mergedConfig)
 			}
-			if stdout != "" {
-				output.Verbosef(stdout)
+			readinesses := make([]*readiness, 0, len(instances))
+			for _, instance := range instances {
+				readinesses = append(readinesses, dockerReadiness(runtime, instance.name, instance.ports.HTTP.HostPort, ctx.Cluster.ID, instance.apisixID))
 			}
-			if err != nil {
-				output.Errorf(err.Error())
-				return
+			waitForDeployment(newctx, readinesses...)
+			if len(instances) > 1 {
+				fmt.Printf("Congratulations! Your %d APISIX instances were deployed successfully\n", len(instances))
+			} else {
+				fmt.Println("Congratulations! Your APISIX instance was deployed successfully")
 			}
-			fmt.Println("Congratulations! Your APISIX instance was deployed successfully")
 
-			docker = getDockerCommand()
-			containerID, err := getDockerContainerIDByName(newctx, docker, options.Global.Deploy.Name)
-			if err != nil {
-				message := fmt.Sprintf("failed to get APISIX container ID: %s\nPlease check it via docker ps command\n", err)
-				output.Errorf(message)
-				return
+			for _, instance := range instances {
+				docker := getDockerCommand()
+				containerID, err := getDockerContainerIDByName(newctx, docker, instance.name)
+				if err != nil {
+					message := fmt.Sprintf("failed to get APISIX container ID: %s\nPlease check it via docker ps command\n", err)
+					output.Errorf(message)
+					return
+				}
+				if len(instances) > 1 {
+					fmt.Printf("Container Name: %s\n", instance.name)
+				}
+				fmt.Printf("Container ID: %s\n", containerID)
+				fmt.Printf("APISIX ID: %s\n", instance.apisixID)
 			}
-			fmt.Printf("Container ID: %s\n", containerID)
-			fmt.Printf("APISIX ID: %s\n", ctx.apisixID)
 		},
 	}
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.APISIXImage, "apisix-image", "apache/apisix:2.15.0-centos", "Specify the Apache APISIX image")
 	cmd.PersistentFlags().IntVar(&options.Global.Deploy.Docker.HTTPHostPort, "http-host-port", 9080, "Specify the host port for HTTP")
 	cmd.PersistentFlags().IntVar(&options.Global.Deploy.Docker.HTTPSHostPort, "https-host-port", 9443, "Specify the host port for HTTPS")
-	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command")
+	cmd.PersistentFlags().IntVar(&options.Global.Deploy.Docker.HTTPPort, "http-port", 9080, "Specify the port that APISIX listens on for HTTP in the container")
+	cmd.PersistentFlags().IntVar(&options.Global.Deploy.Docker.HTTPSPort, "https-port", 9443, "Specify the port that APISIX listens on for HTTPS in the container")
+	cmd.PersistentFlags().StringSliceVar(&options.Global.Deploy.Docker.Ports, "port", []string{}, "Specify the additional port mapping (in the format of host:container[/protocol], e.g. 9100:9100/udp) for listeners like the stream proxy, the control API and the Prometheus export server, it can be specified multiple times")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command (or the podman and nerdctl command for the corresponding runtime)")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.Runtime, "runtime", string(container.Docker), "Specify the container runtime, one of: "+strings.Join(container.Runtimes(), ", "))
 	cmd.PersistentFlags().StringSliceVar(&options.Global.Deploy.Docker.DockerRunArgs, "docker-run-arg", []string{}, "Specify the arguments (in the format of name=value, e.g. --mount=type=bind,source=/etc/hosts,target=/etc/hosts,readonly) for the docker run command")
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.LocalCacheBindPath, "local-cache-bind-path", "", "Specify the path to bind to the local cache directory in the container")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Docker.ComposeOut, "compose-out", "", "Write the Docker Compose file to the path instead of running the container by docker run, the APISIX configuration is saved next to it")
+	cmd.PersistentFlags().IntVar(&options.Global.Deploy.Docker.Replicas, "replicas", 1, "Specify the number of APISIX containers to deploy, they're named as <name>-<index> and the host ports are incremented for each one")
+	cmd.PersistentFlags().BoolVar(&options.Global.Deploy.Docker.ComposeUp, "compose-up", false, "Run the compose file by docker compose up, it only works with --compose-out")
 
 	return cmd
 }
 
+// dockerInstance is an APISIX container to deploy, there are several ones
+// if the --replicas option is specified.
+type dockerInstance struct {
+	name     string
+	apisixID string
+	ports    *portPlan
+	mounts   []bindMount
+	runArgs  []string
+}
+
+// dockerMounts returns the files and directories to mount into the APISIX
+// container. The configuration file and the local cache directory are shared
+// if there are several replicas.
+func dockerMounts(ctx *deployContext, configFile, apisixIDFile string, replicated bool) []bindMount {
+	var mounts []bindMount
+	if configFile != "" {
+		mounts = append(mounts, bindMount{Source: configFile, Target: "/usr/local/apisix/conf/config.yaml", ReadOnly: true, Shared: replicated})
+	}
+	mounts = append(mounts,
+		bindMount{Source: ctx.cloudLuaModuleDir, Target: "/cloud_lua_module", ReadOnly: true, Shared: true},
+		bindMount{Source: ctx.tlsDir, Target: "/cloud/tls", ReadOnly: true, Shared: true},
+		bindMount{Source: apisixIDFile, Target: "/usr/local/apisix/conf/apisix.uid", ReadOnly: true, Shared: true},
+		// For cloud_lua_module/apisix/cli/*.lua, we have to mount them to the /usr/local/apisix/apisix/cli/ directory. Or
+		// they cannot be loaded as the /usr/local/apisix/apisix/cli/apisix.lua puts /usr/local/apisix as the first item
+		// in package.path.
+		bindMount{Source: filepath.Join(ctx.cloudLuaModuleDir, "apisix", "cli", "etcd.ljbc"), Target: "/usr/local/apisix/apisix/cli/etcd.lua", ReadOnly: true, Shared: true},
+		bindMount{Source: filepath.Join(ctx.cloudLuaModuleDir, "apisix", "cli", "local_storage.ljbc"), Target: "/usr/local/apisix/apisix/cli/local_storage.lua", ReadOnly: true, Shared: true},
+	)
+	if options.Global.Deploy.Docker.LocalCacheBindPath != "" {
+		mounts = append(mounts, bindMount{Source: options.Global.Deploy.Docker.LocalCacheBindPath, Target: "/usr/local/apisix/conf/apisix.data", Shared: replicated})
+	}
+	return mounts
+}
+
+// saveDockerConfig saves the APISIX configuration for the container. It's
+// saved next to the compose file if it's generated, since the compose file
+// might be run later.
+func saveDockerConfig(config map[string]interface{}, name string) (string, error) {
+	composeOut := options.Global.Deploy.Docker.ComposeOut
+	if composeOut == "" {
+		return apisix.SaveConfigToTemp(config, "apisix-config-*.yaml")
+	}
+	configFile, err := filepath.Abs(filepath.Join(filepath.Dir(composeOut), name+"-config.yaml"))
+	if err != nil {
+		return "", err
+	}
+	if err = apisix.SaveConfig(config, configFile); err != nil {
+		return "", err
+	}
+	return configFile, nil
+}
+
+// writeCompose writes the compose file, it's printed instead in the dry run
+// mode.
+func writeCompose(filename, name string, service *composeService) error {
+	data, err := renderCompose(name, service)
+	if err != nil {
+		return err
+	}
+	if options.Global.DryRun {
+		output.Infof("Compose file %s:\n%s", filename, data)
+		return nil
+	}
+	if err = os.WriteFile(filename, data, 0644); err != nil {
+		return errors.Wrap(err, "write compose file")
+	}
+	output.Infof("The compose file is written to %s", filename)
+	return nil
+}
+
 func getDockerCommand() commands.Cmd {
 	opts := options.Global.Deploy.Docker
-	if opts.DockerCLIPath != "" {
-		return commands.New(opts.DockerCLIPath, options.Global.DryRun)
+	runtime, _ := container.ParseRuntime(opts.Runtime)
+	return runtime.Command(opts.DockerCLIPath, options.Global.DryRun)
+}
+
+// dockerReadiness returns the readiness checks for the APISIX container.
+func dockerReadiness(runtime container.Runtime, name string, httpHostPort int, clusterID sdk.ID, apisixID string) *readiness {
+	return &readiness{
+		state: func(ctx context.Context) (string, bool, error) {
+			state, err := runtime.InspectState(ctx, getDockerCommand(), name)
+			if err != nil {
+				if errors.Is(err, container.ErrNotFound) {
+					return "not found", false, workloadFailed("container %s is not found", name)
+				}
+				return "unknown", false, err
+			}
+			return dockerState(state)
+		},
+		address: fmt.Sprintf("127.0.0.1:%d", httpHostPort),
+		logs: func(ctx context.Context) (string, error) {
+			docker := getDockerCommand()
+			docker.AppendArgs("logs", "--tail", strconv.Itoa(_readinessLogLines), name)
+			stdout, stderr, err := docker.Run(ctx)
+			if err != nil {
+				return "", commandError(stderr, err)
+			}
+			// APISIX writes the error logs to the stderr.
+			return stdout + stderr, nil
+		},
+		apisixIDs: staticAPISIXIDs(apisixID),
+		clusterID: clusterID,
+	}
+}
+
+// dockerState checks if the container is ready, it fails if the container
+// has exited.
+func dockerState(state *container.State) (string, bool, error) {
+	if state.Exited() {
+		return state.String(), false, workloadFailed("container is %s", state.Status)
 	}
-	return commands.New("docker", options.Global.DryRun)
+	return state.String(), state.Healthy(), nil
 }
@@ -42,7 +42,7 @@ func TestDockerDeployCommand(t *testing.T) {
 		{
 			name:       "test deploy docker command",
 			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos"},
-			cmdPattern: `docker run --detach --mount type=bind,source=/.+?\/apisix-config-.+?\.yaml,target=/usr/local/apisix/conf/config\.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+?,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			cmdPattern: `docker run --detach --mount type=bind,source=/.+?\/apisix-config-.+?\.yaml,target=/usr/local/apisix/conf/config\.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+?,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
 			mockCloud: func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				api := cloud.NewMockAPI(ctrl)
@@ -58,7 +58,184 @@ func TestDockerDeployCommand(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with replicas",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--replicas", "2", "--port", "9100:9100/udp"},
+			cmdPattern: `(?s)docker run --detach .+?--mount type=bind,source=/.+?/\.api7cloud/uid/apisix-0\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly .+? -p 9080:9080 -p 9443:9443 -p 9100:9100/udp --name apisix-0 --hostname apisix-0 apache/apisix:2.15.0-centos.+?docker run --detach .+?--mount type=bind,source=/.+?/\.api7cloud/uid/apisix-1\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly .+? -p 9081:9080 -p 9444:9443 -p 9101:9100/udp --name apisix-1 --hostname apisix-1 apache/apisix:2.15.0-centos`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with compose out",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--compose-out", filepath.Join(os.TempDir(), ".api7cloud", "docker-compose.yaml"), "--docker-run-arg", "--env=FOO=bar"},
+			cmdPattern: `(?s)Compose file /.+?/\.api7cloud/docker-compose\.yaml:\nservices:\n  apisix:\n    image: apache/apisix:2\.15\.0-centos\n    container_name: apisix\n    hostname: apisix\n    environment:\n      - FOO=bar\n    ports:\n      - "9080:9080"\n      - "9443:9443"\n    volumes:\n      - type: bind\n        source: /.+?/\.api7cloud/apisix-config\.yaml\n        target: /usr/local/apisix/conf/config\.yaml\n        read_only: true\n      - type: bind\n        source: /.+?/\.api7cloud/cloud_lua_module_beta\n        target: /cloud_lua_module\n`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with compose up",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--compose-out", filepath.Join(os.TempDir(), ".api7cloud", "docker-compose.yaml"), "--compose-up"},
+			cmdPattern: `docker compose --file /.+?/\.api7cloud/docker-compose\.yaml up --detach`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with custom container ports",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--http-port", "8080", "--https-port", "8443", "--port", "9100:9100/udp"},
+			cmdPattern: `(?s)APISIX doesn't listen on the container ports 9100/udp.+-p 9080:8080 -p 9443:8443 -p 9100:9100/udp --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with podman runtime",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--runtime", "podman"},
+			cmdPattern: `podman run --detach --volume /.+?\/apisix-config-.+?\.yaml:/usr/local/apisix/conf/config\.yaml:ro,Z --volume /.+?/\.api7cloud/cloud_lua_module_beta:/cloud_lua_module:ro,z --volume /.+?/\.api7cloud/tls/.+?:/cloud/tls:ro,z --volume /.+?/\.api7cloud/uid/apisix\.uid:/usr/local/apisix/conf/apisix.uid:ro,z --volume /.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc:/usr/local/apisix/apisix/cli/etcd.lua:ro,z --volume /.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc:/usr/local/apisix/apisix/cli/local_storage.lua:ro,z -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix docker.io/apache/apisix:2.15.0-centos`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with nerdctl runtime",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--runtime", "nerdctl"},
+			cmdPattern: `nerdctl run --detach --volume /.+?\/apisix-config-.+?\.yaml:/usr/local/apisix/conf/config\.yaml:ro --volume /.+?/\.api7cloud/cloud_lua_module_beta:/cloud_lua_module:ro --volume /.+?/\.api7cloud/tls/.+?:/cloud/tls:ro --volume /.+?/\.api7cloud/uid/apisix\.uid:/usr/local/apisix/conf/apisix.uid:ro --volume /.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc:/usr/local/apisix/apisix/cli/etcd.lua:ro --volume /.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc:/usr/local/apisix/apisix/cli/local_storage.lua:ro -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+
+				cloud.DefaultClient = api
+			},
+		},
+		{
+			name:       "test deploy docker command with module bundle",
+			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--module-bundle", filepath.Join(os.TempDir(), "cloud-module-bundle.tar.gz")},
+			cmdPattern: `docker run --detach --mount type=bind,source=/.+?\/apisix-config-.+?\.yaml,target=/usr/local/apisix/conf/config\.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+?,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			mockCloud: func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 12345,
+					ClusterSpec: sdk.ClusterSpec{
+						OrganizationID: 1,
+					},
+				}, nil)
+				api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+					Certificate:   "1",
+					PrivateKey:    "1",
+					CACertificate: "1",
+				}, nil)
+
+				// The cloud lua module is not downloaded from API7 Cloud.
+				bundle := filepath.Join(os.TempDir(), "cloud-module-bundle.tar.gz")
+				assert.NoError(t, os.WriteFile(bundle, mockCloudModule(t), 0644), "write module bundle")
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				cloud.DefaultClient = api
@@ -67,7 +244,7 @@ func TestDockerDeployCommand(t *testing.T) {
 		{
 			name:       "test deploy docker command with custom http and https host ports",
 			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--http-host-port", "8080", "--https-host-port", "443"},
-			cmdPattern: `docker run --detach --mount type=bind,source=/.+?\/apisix-config-.+?\.yaml,target=/usr/local/apisix/conf/config\.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 8080:9080 -p 443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			cmdPattern: `docker run --detach --mount type=bind,source=/.+?\/apisix-config-.+?\.yaml,target=/usr/local/apisix/conf/config\.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 8080:9080 -p 443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
 			mockCloud: func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				api := cloud.NewMockAPI(ctrl)
@@ -83,7 +260,7 @@ func TestDockerDeployCommand(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				cloud.DefaultClient = api
@@ -92,7 +269,7 @@ func TestDockerDeployCommand(t *testing.T) {
 		{
 			name:       "test deploy docker command with apisix config",
 			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--apisix-config", "./testdata/apisix.yaml"},
-			cmdPattern: `docker run --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			cmdPattern: `docker run --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
 			mockCloud: func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				api := cloud.NewMockAPI(ctrl)
@@ -108,7 +285,7 @@ func TestDockerDeployCommand(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 				cloud.DefaultClient = api
 			},
@@ -116,7 +293,7 @@ func TestDockerDeployCommand(t *testing.T) {
 		{
 			name:       "test deploy docker command with complicated docker run arg",
 			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--docker-run-arg", "\"--mount=type=bind,source=/etc/hosts,target=/etc/hosts,readonly\""},
-			cmdPattern: `docker run --mount type=bind,source=/etc/hosts,target=/etc/hosts,readonly --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			cmdPattern: `docker run --mount type=bind,source=/etc/hosts,target=/etc/hosts,readonly --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
 			mockCloud: func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				api := cloud.NewMockAPI(ctrl)
@@ -132,7 +309,7 @@ func TestDockerDeployCommand(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				cloud.DefaultClient = api
@@ -141,7 +318,7 @@ func TestDockerDeployCommand(t *testing.T) {
 		{
 			name:       "test deploy docker command with local cache bind path",
 			args:       []string{"docker", "--apisix-image", "apache/apisix:2.15.0-centos", "--local-cache-bind-path", "/tmp/.api7cloud"},
-			cmdPattern: `docker run --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly --mount type=bind,source=/.+?/\.api7cloud,target=/usr/local/apisix/conf/apisix.data -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
+			cmdPattern: `docker run --detach --mount type=bind,source=/.+?/apisix-config-\d+.yaml,target=/usr/local/apisix/conf/config.yaml,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta,target=/cloud_lua_module,readonly --mount type=bind,source=/.+?/\.api7cloud/tls/.+,target=/cloud/tls,readonly --mount type=bind,source=/.+?/\.api7cloud/uid/apisix\.uid,target=/usr/local/apisix/conf/apisix.uid,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/etcd.ljbc,target=/usr/local/apisix/apisix/cli/etcd.lua,readonly --mount type=bind,source=/.+?/\.api7cloud/cloud_lua_module_beta/apisix/cli/local_storage.ljbc,target=/usr/local/apisix/apisix/cli/local_storage.lua,readonly --mount type=bind,source=/.+?/\.api7cloud,target=/usr/local/apisix/conf/apisix.data -p 9080:9080 -p 9443:9443 --name apisix --hostname apisix apache/apisix:2.15.0-centos`,
 			mockCloud: func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				api := cloud.NewMockAPI(ctrl)
@@ -157,7 +334,7 @@ func TestDockerDeployCommand(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 				api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
 				cloud.DefaultClient = api
@@ -195,3 +372,58 @@ func TestDockerDeployCommand(t *testing.T) {
 		})
 	}
 }
+
+// _fakeDocker runs the container successfully, but the container exits
+// immediately.
+const _fakeDocker = `#!/bin/sh
+case "$1" in
+run) echo 0123456789ab ;;
+inspect) echo "exited " ;;
+logs) echo "nginx: [emerg] bind() to 0.0.0.0:9080 failed (98: Address already in use)" >&2 ;;
+esac
+`
+
+func TestDockerDeployReadiness(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-deploy-readiness-test")
+	dockerPath := filepath.Join(dir, "docker")
+
+	persistence.HomeDir = filepath.Join(dir, ".api7cloud")
+	if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+		ctrl := gomock.NewController(t)
+		api := cloud.NewMockAPI(ctrl)
+		api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+			ID: 12345,
+			ClusterSpec: sdk.ClusterSpec{
+				OrganizationID: 1,
+			},
+		}, nil)
+		api.EXPECT().GetTLSBundle(gomock.Any()).Return(&sdk.TLSBundle{
+			Certificate:   "1",
+			PrivateKey:    "1",
+			CACertificate: "1",
+		}, nil)
+		api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
+		api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
+		cloud.DefaultClient = api
+
+		cmd := NewCommand()
+		cmd.SetArgs([]string{"docker", "--docker-cli-path", dockerPath, "--timeout", "5s"})
+		_ = cmd.Execute()
+		return
+	}
+
+	assert.NoError(t, os.MkdirAll(dir, 0755), "create the test directory")
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.WriteFile(dockerPath, []byte(_fakeDocker), 0755), "write the fake docker")
+
+	testutils.PrepareFakeConfiguration(t)
+	cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+	cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+	output, err := cmd.CombinedOutput()
+	assert.Error(t, err, "check if the command failed")
+
+	assert.Contains(t, string(output), "APISIX is not ready: container is exited", "check the failure report")
+	assert.Contains(t, string(output), "Workload:      exited", "check the workload state")
+	assert.Contains(t, string(output), "Recent logs:\nnginx: [emerg] bind() to 0.0.0.0:9080 failed", "check the recent logs")
+	assert.NotContains(t, string(output), "Congratulations!", "check the deployment is not reported as successful")
+}
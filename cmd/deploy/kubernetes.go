@@ -16,9 +16,14 @@ package deploy
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/apisix"
@@ -49,11 +54,22 @@ cloud-cli deploy kubernetes \
 		--name apisix \
 		--namespace apisix \
 		--apisix-image apache/apisix:2.15.0-centos \
+		--version 0.11.3 \
 		--helm-install-arg --output=table \
 		--helm-install-arg --set=apisix.image.tag=2.15.0-centos \
-		--helm-install-arg --wait`,
+		--helm-install-arg --wait
+
+cloud-cli deploy kubernetes \
+		--name apisix \
+		--namespace apisix \
+		--mode manifests \
+		--render-only`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			opts := &options.Global.Deploy.Kubernetes
+			if err := opts.Validate(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
 			if opts.KubectlCLIPath == "" {
 				opts.KubectlCLIPath = "kubectl"
 			}
@@ -76,6 +92,47 @@ cloud-cli deploy kubernetes \
 				err          error
 			)
 
+			var (
+				helmInstallArgs []string
+				customizeValues string
+			)
+			for _, args := range ctx.KubernetesOpts.HelmInstallArgs {
+				if strings.Contains(args, "--values=") {
+					kv := strings.Split(args, "=")
+					if len(kv) != 2 {
+						output.Errorf("invalid --values option")
+					}
+					customizeValues = kv[1]
+					continue
+				}
+				helmInstallArgs = append(helmInstallArgs, strings.SplitN(args, "=", 2)...)
+			}
+
+			if customizeValues != "" {
+				if data, err = os.ReadFile(customizeValues); err != nil {
+					output.Errorf("invalid --apisix-config-file option: %s", err)
+				}
+			}
+
+			if mergedConfig, err = apisix.MergeConfig(data, ctx.essentialConfig); err != nil {
+				output.Errorf(err.Error())
+			}
+			if err = injectCloudModuleMounts(&ctx, mergedConfig); err != nil {
+				output.Errorf("Failed to generate cloud lua module mounts: %s", err.Error())
+			}
+
+			if ctx.KubernetesOpts.OutputDir != "" {
+				if err = writeKustomization(&ctx, mergedConfig); err != nil {
+					output.Errorf("Failed to write kustomization: %s", err.Error())
+				}
+				return
+			}
+
+			if ctx.KubernetesOpts.Mode == options.KubernetesModeManifests {
+				deployManifestsOnKubernetes(&ctx, kubectl, mergedConfig)
+				return
+			}
+
 			if ctx.KubernetesOpts.HelmCLIPath == "" {
 				ctx.KubernetesOpts.HelmCLIPath = "helm"
 			}
@@ -100,38 +157,39 @@ cloud-cli deploy kubernetes \
 
 			{
 				helm = commands.New(ctx.KubernetesOpts.HelmCLIPath, options.Global.DryRun)
-				helm.AppendArgs("install", options.Global.Deploy.Name, "apisix/apisix")
+				// Use "upgrade --install" so that re-running the command updates
+				// the existing release instead of failing.
+				helm.AppendArgs("upgrade", "--install", options.Global.Deploy.Name, "apisix/apisix")
 				helm.AppendArgs("--namespace", ctx.KubernetesOpts.Namespace)
-
-				var customizeValues string
-				for _, args := range ctx.KubernetesOpts.HelmInstallArgs {
-					if strings.Contains(args, "--values=") {
-						kv := strings.Split(args, "=")
-						if len(kv) != 2 {
-							output.Errorf("invalid --values option")
-						}
-						customizeValues = kv[1]
-						continue
-					}
-					helm.AppendArgs(strings.SplitN(args, "=", 2)...)
-				}
-
-				if customizeValues != "" {
-					if data, err = os.ReadFile(customizeValues); err != nil {
-						output.Errorf("invalid --apisix-config-file option: %s", err)
-					}
+				if ctx.KubernetesOpts.ChartVersion != "" {
+					helm.AppendArgs("--version", ctx.KubernetesOpts.ChartVersion)
 				}
+				helm.AppendArgs(helmInstallArgs...)
 
-				if mergedConfig, err = apisix.MergeConfig(data, ctx.essentialConfig); err != nil {
-					output.Errorf(err.Error())
-				}
 				if configFile, err = apisix.SaveConfigToTemp(mergedConfig, "helm-values-*.yaml"); err != nil {
 					output.Errorf(err.Error())
 				}
 				helm.AppendArgs("--values", configFile)
 
 				helmRun(newCtx, helm)
-				printInstallDetailForKubernetes(kubectl)
+
+				// The readiness has its own timeout, it shouldn't be capped by
+				// the remaining time of the helm commands.
+				readinessCtx, cancelReadiness := context.WithCancel(context.TODO())
+				defer cancelReadiness()
+				go utils.WaitForSignal(func() {
+					cancelReadiness()
+				})
+				waitForDeployment(readinessCtx, kubernetesReadiness(ctx.KubernetesOpts.KubectlCLIPath, options.Global.Deploy.Name, ctx.KubernetesOpts.Namespace, ctx.Cluster.ID))
+				apisixIDs := printInstallDetailForKubernetes(kubectl)
+				recordDeployment(&persistence.Deployment{
+					Name:      options.Global.Deploy.Name,
+					Target:    persistence.DeploymentKubernetes,
+					Namespace: ctx.KubernetesOpts.Namespace,
+					Image:     ctx.KubernetesOpts.APISIXImage,
+					APISIXIDs: apisixIDs,
+					ClusterID: ctx.Cluster.ID,
+				}, mergedConfig)
 			}
 		},
 	}
@@ -143,6 +201,10 @@ cloud-cli deploy kubernetes \
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.KubectlCLIPath, "kubectl-cli-path", "", "Specify the filepath of the kubectl command")
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.HelmCLIPath, "helm-cli-path", "", "Specify the filepath of the helm command")
 	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.LocalCachePVC, "local-cache-pvc", "", "Specify the name of the PVC for local configuration cache")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.Mode, "mode", options.KubernetesModeHelm, "Specify the deployment mode, one of: helm (deploy by the APISIX Helm chart), manifests (deploy by the native Kubernetes manifests, without Helm)")
+	cmd.PersistentFlags().BoolVar(&options.Global.Deploy.Kubernetes.RenderOnly, "render-only", false, "Print the rendered manifests instead of applying them, only available in the manifests mode")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.OutputDir, "output-dir", "", "Write the kustomization to the directory instead of deploying it, so that it can be deployed in a GitOps way")
+	cmd.PersistentFlags().StringVar(&options.Global.Deploy.Kubernetes.ChartVersion, "version", "", "Specify the version of the APISIX Helm chart, the latest version will be used if it's not specified")
 
 	return cmd
 }
@@ -165,3 +227,119 @@ func helmRun(ctx context.Context, helm commands.Cmd) {
 		output.Errorf(err.Error())
 	}
 }
+
+// kubernetesPodList is the subset of the pod list which is used to check
+// the readiness.
+type kubernetesPodList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Phase             string `json:"phase"`
+			ContainerStatuses []struct {
+				Ready bool `json:"ready"`
+				State struct {
+					Waiting *struct {
+						Reason string `json:"reason"`
+					} `json:"waiting"`
+				} `json:"state"`
+			} `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// _unrecoverablePodReasons are the container waiting reasons that won't be
+// recovered without changes.
+var _unrecoverablePodReasons = map[string]struct{}{
+	"CrashLoopBackOff":           {},
+	"ErrImagePull":               {},
+	"ImagePullBackOff":           {},
+	"InvalidImageName":           {},
+	"CreateContainerConfigError": {},
+	"CreateContainerError":       {},
+}
+
+// kubernetesReadiness returns the readiness checks for the APISIX pods, the
+// data plane is verified by the readiness probes of the pods.
+func kubernetesReadiness(kubectlPath, release, namespace string, clusterID sdk.ID) *readiness {
+	selector := fmt.Sprintf("app.kubernetes.io/instance=%s", release)
+	return &readiness{
+		state: func(ctx context.Context) (string, bool, error) {
+			kubectl := commands.New(kubectlPath, false)
+			kubectl.AppendArgs("get", "pods", "-n", namespace, "-l", selector, "-o", "json")
+			stdout, stderr, err := kubectl.Run(ctx)
+			if err != nil {
+				return "unknown", false, commandError(stderr, err)
+			}
+			var pods kubernetesPodList
+			if err = json.Unmarshal([]byte(stdout), &pods); err != nil {
+				return "unknown", false, errors.Wrap(err, "unmarshal pods")
+			}
+			return kubernetesPodsState(&pods)
+		},
+		logs: func(ctx context.Context) (string, error) {
+			kubectl := commands.New(kubectlPath, false)
+			kubectl.AppendArgs("logs", "-n", namespace, "-l", selector, "--all-containers", "--prefix")
+			kubectl.AppendArgs("--tail", strconv.Itoa(_readinessLogLines))
+			stdout, stderr, err := kubectl.Run(ctx)
+			if err != nil {
+				return "", commandError(stderr, err)
+			}
+			return stdout, nil
+		},
+		apisixIDs: func(ctx context.Context) ([]string, error) {
+			podNames, err := utils.GetPodsNames(commands.New(kubectlPath, false), release, namespace)
+			if err != nil {
+				return nil, err
+			}
+			var ids []string
+			for _, podName := range podNames {
+				id, err := utils.GetAPISIXID(commands.New(kubectlPath, false), podName)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, strings.TrimSpace(id))
+			}
+			return ids, nil
+		},
+		clusterID: clusterID,
+	}
+}
+
+// kubernetesPodsState summarizes the pods state, the pods are running only
+// if all of them are ready.
+func kubernetesPodsState(pods *kubernetesPodList) (string, bool, error) {
+	if len(pods.Items) == 0 {
+		return "no pods", false, nil
+	}
+	var (
+		ready   int
+		reasons []string
+	)
+	for _, pod := range pods.Items {
+		podReady := pod.Status.Phase == "Running" && len(pod.Status.ContainerStatuses) > 0
+		for _, status := range pod.Status.ContainerStatuses {
+			if !status.Ready {
+				podReady = false
+			}
+			if status.State.Waiting == nil {
+				continue
+			}
+			reason := status.State.Waiting.Reason
+			if _, ok := _unrecoverablePodReasons[reason]; ok {
+				reasons = append(reasons, pod.Metadata.Name+": "+reason)
+			}
+		}
+		if podReady {
+			ready++
+		}
+	}
+
+	state := fmt.Sprintf("%d/%d pods ready", ready, len(pods.Items))
+	if len(reasons) > 0 {
+		state += " (" + strings.Join(reasons, ", ") + ")"
+		return state, false, workloadFailed("pods failed, %s", strings.Join(reasons, ", "))
+	}
+	return state, ready == len(pods.Items), nil
+}
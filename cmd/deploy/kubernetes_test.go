@@ -47,7 +47,7 @@ func TestKubernetesDeployCommand(t *testing.T) {
 			CACertificate: "1",
 		}, nil)
 
-		api.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+		api.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 		api.EXPECT().GetStartupConfig(sdk.ID(12345), cloud.HELM).Return(_helmStartupConfigTpl, nil)
 
 		cloud.DefaultClient = api
@@ -64,11 +64,12 @@ func TestKubernetesDeployCommand(t *testing.T) {
 			args: []string{"kubernetes"},
 			cmdPatterns: []string{
 				`kubectl create ns apisix`,
-				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix`,
-				`kubectl create configmap cloud-module --from-file cloud.ljbc=.*?cloud.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --namespace apisix`,
+				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix --dry-run=client --output yaml`,
+				`kubectl create configmap cloud-module --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud.ljbc=.*?cloud.ljbc --namespace apisix --dry-run=client --output yaml`,
+				`kubectl apply --filename .*?k8s-manifest-.*?.yaml --namespace apisix`,
 				`helm repo add apisix https://charts.apiseven.com`,
 				`helm repo update`,
-				`helm install apisix apisix/apisix --namespace apisix --values .*?.yaml`,
+				`helm upgrade --install apisix apisix/apisix --namespace apisix --values .*?.yaml`,
 				`The Helm release name is: apisix`,
 				`kubectl get deployment -n apisix -l app.kubernetes.io/instance=apisix -o jsonpath="\{.items\[0\].metadata.name\}"`,
 				`kubectl get pods -n apisix -l app.kubernetes.io/instance=apisix -o jsonpath="\{.items\[\*\].metadata.name\}"`,
@@ -85,11 +86,12 @@ func TestKubernetesDeployCommand(t *testing.T) {
 				"--helm-install-arg", "--output=table", "--helm-install-arg", "--wait"},
 			cmdPatterns: []string{
 				`/tmp/kubectl create ns my-apisix`,
-				`/tmp/kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace my-apisix`,
-				`/tmp/kubectl create configmap cloud-module --from-file cloud.ljbc=.*?cloud.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --namespace my-apisix`,
+				`/tmp/kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace my-apisix --dry-run=client --output yaml`,
+				`/tmp/kubectl create configmap cloud-module --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud.ljbc=.*?cloud.ljbc --namespace my-apisix --dry-run=client --output yaml`,
+				`/tmp/kubectl apply --filename .*?k8s-manifest-.*?.yaml --namespace my-apisix`,
 				`/tmp/helm repo add apisix https://charts.apiseven.com`,
 				`/tmp/helm repo update`,
-				`/tmp/helm install apisix-test apisix/apisix --namespace my-apisix --output table --wait --values .*?.yaml`,
+				`/tmp/helm upgrade --install apisix-test apisix/apisix --namespace my-apisix --output table --wait --values .*?.yaml`,
 				`Congratulations! Your APISIX cluster was deployed successfully on Kubernetes.`,
 				`The Helm release name is: apisix-test`,
 				`/tmp/kubectl get deployment -n my-apisix -l app.kubernetes.io/instance=apisix-test -o jsonpath="\{.items\[0\].metadata.name\}"`,
@@ -105,11 +107,12 @@ func TestKubernetesDeployCommand(t *testing.T) {
 			args: []string{"kubernetes", "--helm-install-arg", "--values=./testdata/apisix_chart_values.yaml"},
 			cmdPatterns: []string{
 				`kubectl create ns apisix`,
-				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix`,
-				`kubectl create configmap cloud-module --from-file cloud.ljbc=.*?cloud.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --namespace apisix`,
+				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix --dry-run=client --output yaml`,
+				`kubectl create configmap cloud-module --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud.ljbc=.*?cloud.ljbc --namespace apisix --dry-run=client --output yaml`,
+				`kubectl apply --filename .*?k8s-manifest-.*?.yaml --namespace apisix`,
 				`helm repo add apisix https://charts.apiseven.com`,
 				`helm repo update`,
-				`helm install apisix apisix/apisix --namespace apisix --values .*?.yaml`,
+				`helm upgrade --install apisix apisix/apisix --namespace apisix --values .*?.yaml`,
 				`Congratulations! Your APISIX cluster was deployed successfully on Kubernetes.`,
 				`The Helm release name is: apisix`,
 				`kubectl get deployment -n apisix -l app.kubernetes.io/instance=apisix -o jsonpath="\{.items\[0\].metadata.name\}"`,
@@ -120,16 +123,62 @@ func TestKubernetesDeployCommand(t *testing.T) {
 			},
 			mockCloud: defaultMockCloud,
 		},
+		{
+			name: "deploy on kubernetes with a pinned chart version",
+			args: []string{"kubernetes", "--version", "0.11.3"},
+			cmdPatterns: []string{
+				`helm repo add apisix https://charts.apiseven.com`,
+				`helm repo update`,
+				`helm upgrade --install apisix apisix/apisix --namespace apisix --version 0.11.3 --values .*?.yaml`,
+				`Congratulations! Your APISIX cluster was deployed successfully on Kubernetes.`,
+			},
+			mockCloud: defaultMockCloud,
+		},
+		{
+			name: "render manifests on kubernetes",
+			args: []string{"kubernetes", "--mode", "manifests", "--render-only"},
+			cmdPatterns: []string{
+				`kind: Namespace\n`,
+				`kind: Secret\n`,
+				`name: cloud-module\n`,
+				`name: apisix-config\n`,
+				`kind: Deployment\n`,
+				`image: "apache/apisix:2.15.0-centos"`,
+				`mountPath: "/lua-module-hook/cloud.ljbc"\n\s+subPath: "cloud.ljbc"`,
+				`kind: Service\n`,
+			},
+			mockCloud: defaultMockCloud,
+		},
+		{
+			name: "deploy manifests on kubernetes",
+			args: []string{"kubernetes", "--mode", "manifests"},
+			cmdPatterns: []string{
+				`kubectl apply --filename .*?k8s-manifest-.*?.yaml\n`,
+				`Congratulations! Your APISIX cluster was deployed successfully on Kubernetes.`,
+				`kubectl get deployment -n apisix -l app.kubernetes.io/instance=apisix`,
+			},
+			mockCloud: defaultMockCloud,
+		},
+		{
+			name: "write kustomization for kubernetes",
+			args: []string{"kubernetes", "--output-dir", filepath.Join(os.TempDir(), ".api7cloud", "kustomization")},
+			cmdPatterns: []string{
+				`The kustomization was written to .*?kustomization`,
+				`WARNING: The cloud-ssl Secret is stored in plain text`,
+			},
+			mockCloud: defaultMockCloud,
+		},
 		{
 			name: "deploy on kubernetes with customize helm install --set options",
 			args: []string{"kubernetes", "--helm-install-arg", "--set=apisix.ingress.enabled=false"},
 			cmdPatterns: []string{
 				`kubectl create ns apisix`,
-				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix`,
-				`kubectl create configmap cloud-module --from-file cloud.ljbc=.*?cloud.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --namespace apisix`,
+				`kubectl create secret generic cloud-ssl --from-file tls.crt=.*?tls.crt --from-file tls.key=.*?tls.key --from-file ca.crt=.*?ca.crt --namespace apisix --dry-run=client --output yaml`,
+				`kubectl create configmap cloud-module --from-file apisix-cli-etcd.ljbc=.*?etcd.ljbc --from-file apisix-cli-local-storage.ljbc=.*?local_storage.ljbc --from-file apisix-core-config-etcd.ljbc=.*?config_etcd.ljbc --from-file apisix-local-storage.ljbc=.*?local_storage.ljbc --from-file cloud-agent.ljbc=.*?agent.ljbc --from-file cloud-file.ljbc=.*?file.ljbc --from-file cloud-metrics.ljbc=.*?metrics.ljbc --from-file cloud-utils.ljbc=.*?utils.ljbc --from-file cloud.ljbc=.*?cloud.ljbc --namespace apisix --dry-run=client --output yaml`,
+				`kubectl apply --filename .*?k8s-manifest-.*?.yaml --namespace apisix`,
 				`helm repo add apisix https://charts.apiseven.com`,
 				`helm repo update`,
-				`helm install apisix apisix/apisix --namespace apisix --set apisix.ingress.enabled=false`,
+				`helm upgrade --install apisix apisix/apisix --namespace apisix --set apisix.ingress.enabled=false`,
 				`Congratulations! Your APISIX cluster was deployed successfully on Kubernetes.`,
 				`The Helm release name is: apisix`,
 				`kubectl get deployment -n apisix -l app.kubernetes.io/instance=apisix -o jsonpath="\{.items\[0\].metadata.name\}"`,
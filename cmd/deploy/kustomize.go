@@ -0,0 +1,157 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+const (
+	_kustomizationFile = "kustomization.yaml"
+	_namespaceFile     = "namespace.yaml"
+	_secretFile        = "cloud-ssl-secret.yaml"
+	_workloadsFile     = "apisix.yaml"
+	_valuesFile        = "values.yaml"
+	_cloudModuleDir    = "cloud-module"
+)
+
+var (
+	//go:embed manifest/kustomization.yaml
+	_kustomizationTemplate string
+	_kustomizationRenderer *template.Template
+)
+
+func init() {
+	_kustomizationRenderer = template.Must(template.New("kustomization").Funcs(_manifestsFuncs).Parse(_kustomizationTemplate))
+}
+
+type kustomizeHelmChart struct {
+	Repo        string
+	Version     string
+	ReleaseName string
+	Namespace   string
+	ValuesFile  string
+}
+
+type kustomizationContext struct {
+	Namespace           string
+	Labels              string
+	Resources           []string
+	ModuleConfigMapName string
+	ModuleFiles         []cloudModuleFile
+	Chart               *kustomizeHelmChart
+}
+
+// writeKustomization writes the kustomization of the deployment to the output
+// directory, so that it can be deployed in a GitOps way. The APISIX workloads
+// are either the APISIX Helm chart with the values overlay, or the native
+// manifests, which depends on the deployment mode.
+func writeKustomization(ctx *deployContext, values map[string]interface{}) error {
+	opts := ctx.KubernetesOpts
+	dir := opts.OutputDir
+
+	mctx, err := newManifestsContext(ctx, values)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Join(dir, _cloudModuleDir), 0755); err != nil {
+		return errors.Wrap(err, "create output directory")
+	}
+
+	kctx := &kustomizationContext{
+		Namespace:           opts.Namespace,
+		Labels:              mctx.Labels,
+		Resources:           []string{_namespaceFile, _secretFile},
+		ModuleConfigMapName: mctx.ModuleConfigMapName,
+	}
+
+	if err = writeManifest(filepath.Join(dir, _namespaceFile), "namespace", mctx, 0644); err != nil {
+		return err
+	}
+	// The secret contains the private key.
+	if err = writeManifest(filepath.Join(dir, _secretFile), "secret", mctx, 0600); err != nil {
+		return err
+	}
+
+	files, err := cloudModuleFiles(ctx.cloudLuaModuleDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return errors.Wrap(err, "read cloud lua module")
+		}
+		target := filepath.Join(_cloudModuleDir, file.Key)
+		if err = os.WriteFile(filepath.Join(dir, target), data, 0644); err != nil {
+			return errors.Wrap(err, "write cloud lua module")
+		}
+		kctx.ModuleFiles = append(kctx.ModuleFiles, cloudModuleFile{Key: file.Key, Path: target})
+	}
+
+	if opts.Mode == options.KubernetesModeManifests {
+		if err = writeManifest(filepath.Join(dir, _workloadsFile), "workloads", mctx, 0644); err != nil {
+			return err
+		}
+		kctx.Resources = append(kctx.Resources, _workloadsFile)
+	} else {
+		if err = apisix.SaveConfig(values, filepath.Join(dir, _valuesFile)); err != nil {
+			return err
+		}
+		kctx.Chart = &kustomizeHelmChart{
+			Repo:        defaultHelmChartsUrl,
+			Version:     opts.ChartVersion,
+			ReleaseName: options.Global.Deploy.Name,
+			Namespace:   opts.Namespace,
+			ValuesFile:  _valuesFile,
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = _kustomizationRenderer.Execute(buf, kctx); err != nil {
+		return errors.Wrap(err, "render kustomization")
+	}
+	if err = os.WriteFile(filepath.Join(dir, _kustomizationFile), buf.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "write kustomization")
+	}
+
+	fmt.Printf("The kustomization was written to %s\n", dir)
+	output.Warnf("The %s Secret is stored in plain text in %s, please seal it (e.g. by kubeseal) before committing it",
+		mctx.SecretName, filepath.Join(dir, _secretFile))
+	return nil
+}
+
+func writeManifest(filename, name string, mctx *manifestsContext, perm os.FileMode) error {
+	buf := bytes.NewBuffer(nil)
+	if err := _manifestsRenderer.ExecuteTemplate(buf, name, mctx); err != nil {
+		return errors.Wrapf(err, "render %s manifest", name)
+	}
+	buf.WriteString("\n")
+	if err := os.WriteFile(filename, buf.Bytes(), perm); err != nil {
+		return errors.Wrapf(err, "write %s manifest", name)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+func TestWriteKustomization(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mode         string
+		chartVersion string
+		resources    []interface{}
+		files        []string
+		helmCharts   interface{}
+	}{
+		{
+			name:         "helm mode",
+			mode:         options.KubernetesModeHelm,
+			chartVersion: "0.11.3",
+			resources:    []interface{}{"namespace.yaml", "cloud-ssl-secret.yaml"},
+			files:        []string{"values.yaml"},
+			helmCharts: []interface{}{
+				map[string]interface{}{
+					"name":        "apisix",
+					"repo":        "https://charts.apiseven.com",
+					"version":     "0.11.3",
+					"releaseName": "apisix-test",
+					"namespace":   "my-apisix",
+					"valuesFile":  "values.yaml",
+				},
+			},
+		},
+		{
+			name:      "manifests mode",
+			mode:      options.KubernetesModeManifests,
+			resources: []interface{}{"namespace.yaml", "cloud-ssl-secret.yaml", "apisix.yaml"},
+			files:     []string{"apisix.yaml"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, values := prepareManifests(t)
+			ctx.KubernetesOpts.Mode = tc.mode
+			ctx.KubernetesOpts.ChartVersion = tc.chartVersion
+			ctx.KubernetesOpts.OutputDir = filepath.Join(t.TempDir(), "apisix")
+
+			assert.NoError(t, writeKustomization(ctx, values), "write kustomization")
+
+			dir := ctx.KubernetesOpts.OutputDir
+			data, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+			assert.NoError(t, err, "read kustomization")
+			var kustomization map[string]interface{}
+			assert.NoError(t, yaml.Unmarshal(data, &kustomization), "unmarshal kustomization")
+
+			assert.Equal(t, "my-apisix", kustomization["namespace"], "check namespace")
+			assert.Equal(t, tc.resources, kustomization["resources"], "check resources")
+			assert.Equal(t, tc.helmCharts, kustomization["helmCharts"], "check helm charts")
+
+			generators := kustomization["configMapGenerator"].([]interface{})
+			assert.Len(t, generators, 1, "check configmap generators")
+			generator := generators[0].(map[string]interface{})
+			assert.Equal(t, "cloud-module", generator["name"], "check configmap name")
+			files := generator["files"].([]interface{})
+			assert.Len(t, files, 6, "check configmap files")
+			assert.Contains(t, files, "cloud-agent.ljbc=cloud-module/cloud-agent.ljbc", "check configmap files")
+
+			module, err := os.ReadFile(filepath.Join(dir, "cloud-module", "cloud-agent.ljbc"))
+			assert.NoError(t, err, "read cloud lua module")
+			assert.Equal(t, "cloud/agent.ljbc", string(module), "check cloud lua module")
+
+			info, err := os.Stat(filepath.Join(dir, "cloud-ssl-secret.yaml"))
+			assert.NoError(t, err, "stat secret")
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "check secret permission")
+
+			for _, file := range append(tc.resources, tc.files[0]) {
+				data, err := os.ReadFile(filepath.Join(dir, file.(string)))
+				assert.NoError(t, err, "read %s", file)
+				assert.NotEmpty(t, data, "check %s", file)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/consts"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+const (
+	// _etcdSSLDir is the directory to mount the etcd client certificate.
+	_etcdSSLDir = "/etcd-ssl"
+	// _caSSLDir is the directory to mount the CA certificate.
+	_caSSLDir = "/usr/local/apisix/conf/ssl"
+)
+
+var (
+	//go:embed manifest/kubernetes.yaml
+	_kubernetesManifests string
+	_manifestsRenderer   *template.Template
+
+	_manifestsFuncs = template.FuncMap{
+		"quote":  strconv.Quote,
+		"indent": indent,
+		"nindent": func(n int, s string) string {
+			return "\n" + indent(n, s)
+		},
+	}
+)
+
+func init() {
+	_manifestsRenderer = template.Must(template.New("kubernetes manifests").Funcs(_manifestsFuncs).Parse(_kubernetesManifests))
+}
+
+// chartValues contains the APISIX Helm chart values which are used to
+// render the native Kubernetes manifests.
+type chartValues struct {
+	APISIX struct {
+		Image struct {
+			Repository string `yaml:"repository"`
+			Tag        string `yaml:"tag"`
+		} `yaml:"image"`
+		ReplicaCount  uint `yaml:"replicaCount"`
+		LuaModuleHook struct {
+			Enabled      bool   `yaml:"enabled"`
+			LuaPath      string `yaml:"luaPath"`
+			HookPoint    string `yaml:"hookPoint"`
+			ConfigMapRef struct {
+				Name   string `yaml:"name"`
+				Mounts []struct {
+					Key  string `yaml:"key"`
+					Path string `yaml:"path"`
+				} `yaml:"mounts"`
+			} `yaml:"configMapRef"`
+		} `yaml:"luaModuleHook"`
+		CustomLuaSharedDicts []struct {
+			Name string `yaml:"name"`
+			Size string `yaml:"size"`
+		} `yaml:"customLuaSharedDicts"`
+	} `yaml:"apisix"`
+	Gateway struct {
+		TLS struct {
+			Enabled          bool   `yaml:"enabled"`
+			ExistingCASecret string `yaml:"existingCASecret"`
+			CertCAFilename   string `yaml:"certCAFilename"`
+		} `yaml:"tls"`
+	} `yaml:"gateway"`
+	Admin struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"admin"`
+	Etcd struct {
+		Enabled bool     `yaml:"enabled"`
+		Host    []string `yaml:"host"`
+		Prefix  string   `yaml:"prefix"`
+		Timeout int      `yaml:"timeout"`
+		Auth    struct {
+			TLS struct {
+				Enabled         bool   `yaml:"enabled"`
+				SNI             string `yaml:"sni"`
+				Verify          *bool  `yaml:"verify"`
+				ExistingSecret  string `yaml:"existingSecret"`
+				CertFilename    string `yaml:"certFilename"`
+				CertKeyFilename string `yaml:"certKeyFilename"`
+			} `yaml:"tls"`
+		} `yaml:"auth"`
+	} `yaml:"etcd"`
+}
+
+type manifestFile struct {
+	Key  string
+	Data string
+}
+
+type manifestsContext struct {
+	Name                string
+	Namespace           string
+	Labels              string
+	SelectorLabels      string
+	Image               string
+	ReplicaCount        uint
+	Config              string
+	Checksum            string
+	SecretName          string
+	TLSFiles            []manifestFile
+	EtcdSecretName      string
+	CASecretName        string
+	ModuleConfigMapName string
+	ModuleFiles         []manifestFile
+	ModuleMounts        []cloudModuleFile
+}
+
+// deployManifestsOnKubernetes renders the native Kubernetes manifests from
+// the Helm chart values, and prints or applies them.
+func deployManifestsOnKubernetes(ctx *deployContext, kubectl commands.Cmd, values map[string]interface{}) {
+	manifests, err := renderKubernetesManifests(ctx, values)
+	if err != nil {
+		output.Errorf(err.Error())
+		return
+	}
+	if ctx.KubernetesOpts.RenderOnly {
+		fmt.Print(string(manifests))
+		return
+	}
+
+	manifestsFile, err := saveManifestToTemp(manifests)
+	if err != nil {
+		output.Errorf(err.Error())
+		return
+	}
+	defer os.Remove(manifestsFile)
+
+	kubectl.AppendArgs("apply", "--filename", manifestsFile)
+	stdout, err := kubectlRun(kubectl)
+	if err != nil {
+		output.Errorf("Failed to apply manifests on kubernetes: %s", err.Error())
+		return
+	}
+	if stdout != "" {
+		output.Verbosef(stdout)
+	}
+
+	readinessCtx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go utils.WaitForSignal(func() {
+		cancel()
+	})
+	waitForDeployment(readinessCtx, kubernetesReadiness(ctx.KubernetesOpts.KubectlCLIPath, options.Global.Deploy.Name, ctx.KubernetesOpts.Namespace, ctx.Cluster.ID))
+	apisixIDs := printInstallDetailForKubernetes(kubectl)
+	recordDeployment(&persistence.Deployment{
+		Name:      options.Global.Deploy.Name,
+		Target:    persistence.DeploymentKubernetes,
+		Namespace: ctx.KubernetesOpts.Namespace,
+		Image:     ctx.KubernetesOpts.APISIXImage,
+		APISIXIDs: apisixIDs,
+		ClusterID: ctx.Cluster.ID,
+	}, values)
+}
+
+// renderKubernetesManifests renders the Namespace, Secret, ConfigMap,
+// Deployment and Service manifests from the Helm chart values.
+func renderKubernetesManifests(ctx *deployContext, values map[string]interface{}) ([]byte, error) {
+	mctx, err := newManifestsContext(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(nil)
+	if err = _manifestsRenderer.Execute(buf, mctx); err != nil {
+		return nil, errors.Wrap(err, "render kubernetes manifests")
+	}
+	return buf.Bytes(), nil
+}
+
+// newManifestsContext prepares the data to render the manifests from the
+// Helm chart values.
+func newManifestsContext(ctx *deployContext, values map[string]interface{}) (*manifestsContext, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal helm values")
+	}
+	var v chartValues
+	if err = yaml.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, "unmarshal helm values")
+	}
+	if v.Etcd.Enabled {
+		return nil, fmt.Errorf("the bundled etcd is not supported in the %s mode", options.KubernetesModeManifests)
+	}
+
+	name := options.Global.Deploy.Name
+	mctx := &manifestsContext{
+		Name:      name,
+		Namespace: ctx.KubernetesOpts.Namespace,
+		Labels: strings.Join([]string{
+			"app.kubernetes.io/name: apisix",
+			"app.kubernetes.io/instance: " + name,
+			"app.kubernetes.io/managed-by: cloud-cli",
+		}, "\n"),
+		SelectorLabels: strings.Join([]string{
+			"app.kubernetes.io/name: apisix",
+			"app.kubernetes.io/instance: " + name,
+		}, "\n"),
+		Image:               ctx.KubernetesOpts.APISIXImage,
+		ReplicaCount:        ctx.KubernetesOpts.ReplicaCount,
+		SecretName:          consts.DefaultSecretName,
+		ModuleConfigMapName: consts.DefaultConfigMapName,
+	}
+	if v.APISIX.Image.Repository != "" {
+		mctx.Image = v.APISIX.Image.Repository
+		if v.APISIX.Image.Tag != "" {
+			mctx.Image += ":" + v.APISIX.Image.Tag
+		}
+	}
+	if v.APISIX.ReplicaCount > 0 {
+		mctx.ReplicaCount = v.APISIX.ReplicaCount
+	}
+	if v.Etcd.Auth.TLS.Enabled {
+		mctx.EtcdSecretName = v.Etcd.Auth.TLS.ExistingSecret
+	}
+	if v.Gateway.TLS.Enabled {
+		mctx.CASecretName = v.Gateway.TLS.ExistingCASecret
+	}
+	if v.APISIX.LuaModuleHook.Enabled {
+		if v.APISIX.LuaModuleHook.ConfigMapRef.Name != "" {
+			mctx.ModuleConfigMapName = v.APISIX.LuaModuleHook.ConfigMapRef.Name
+		}
+		for _, mount := range v.APISIX.LuaModuleHook.ConfigMapRef.Mounts {
+			mctx.ModuleMounts = append(mctx.ModuleMounts, cloudModuleFile{Key: mount.Key, Path: mount.Path})
+		}
+	}
+
+	configBuf := bytes.NewBuffer(nil)
+	encoder := yaml.NewEncoder(configBuf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(apisixConfigFromValues(&v)); err != nil {
+		return nil, errors.Wrap(err, "marshal apisix config")
+	}
+	config := configBuf.Bytes()
+	mctx.Config = string(config)
+
+	hash := sha256.New()
+	hash.Write(config)
+	for _, file := range []string{"tls.crt", "tls.key", "ca.crt"} {
+		data, err := os.ReadFile(filepath.Join(ctx.tlsDir, file))
+		if err != nil {
+			return nil, errors.Wrap(err, "read tls bundle")
+		}
+		hash.Write(data)
+		mctx.TLSFiles = append(mctx.TLSFiles, manifestFile{Key: file, Data: base64.StdEncoding.EncodeToString(data)})
+	}
+	files, err := cloudModuleFiles(ctx.cloudLuaModuleDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, "read cloud lua module")
+		}
+		hash.Write(data)
+		mctx.ModuleFiles = append(mctx.ModuleFiles, manifestFile{Key: file.Key, Data: base64.StdEncoding.EncodeToString(data)})
+	}
+	// The checksum annotation rolls the pods once the configurations change.
+	mctx.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return mctx, nil
+}
+
+// apisixConfigFromValues generates the APISIX configuration from the Helm
+// chart values, like what the APISIX Helm chart does.
+func apisixConfigFromValues(v *chartValues) map[string]interface{} {
+	apisixConfig := map[string]interface{}{
+		"enable_admin": v.Admin.Enabled,
+	}
+	if v.APISIX.LuaModuleHook.Enabled {
+		apisixConfig["lua_module_hook"] = v.APISIX.LuaModuleHook.HookPoint
+		apisixConfig["extra_lua_path"] = v.APISIX.LuaModuleHook.LuaPath
+	}
+	if v.Gateway.TLS.Enabled && v.Gateway.TLS.ExistingCASecret != "" {
+		apisixConfig["ssl"] = map[string]interface{}{
+			"ssl_trusted_certificate": path.Join(_caSSLDir, v.Gateway.TLS.CertCAFilename),
+		}
+	}
+	config := map[string]interface{}{
+		"apisix": apisixConfig,
+	}
+
+	if len(v.APISIX.CustomLuaSharedDicts) > 0 {
+		dicts := make(map[string]interface{}, len(v.APISIX.CustomLuaSharedDicts))
+		for _, dict := range v.APISIX.CustomLuaSharedDicts {
+			dicts[dict.Name] = dict.Size
+		}
+		config["nginx_config"] = map[string]interface{}{
+			"http": map[string]interface{}{
+				"custom_lua_shared_dict": dicts,
+			},
+		}
+	}
+
+	etcd := map[string]interface{}{
+		"host": v.Etcd.Host,
+	}
+	if v.Etcd.Prefix != "" {
+		etcd["prefix"] = v.Etcd.Prefix
+	}
+	if v.Etcd.Timeout > 0 {
+		etcd["timeout"] = v.Etcd.Timeout
+	}
+	if tls := v.Etcd.Auth.TLS; tls.Enabled {
+		verify := true
+		if tls.Verify != nil {
+			verify = *tls.Verify
+		}
+		etcdTLS := map[string]interface{}{
+			"cert":   path.Join(_etcdSSLDir, tls.CertFilename),
+			"key":    path.Join(_etcdSSLDir, tls.CertKeyFilename),
+			"verify": verify,
+		}
+		if tls.SNI != "" {
+			etcdTLS["sni"] = tls.SNI
+		}
+		etcd["tls"] = etcdTLS
+	}
+	config["etcd"] = etcd
+	return config
+}
+
+// saveManifestToTemp saves the manifest to a temporary file and returns its
+// filepath.
+func saveManifestToTemp(manifest []byte) (string, error) {
+	f, err := os.CreateTemp("", "k8s-manifest-*.yaml")
+	if err != nil {
+		return "", errors.Wrap(err, "create temporary manifest file")
+	}
+	_, err = f.Write(manifest)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "write manifest file")
+	}
+	return f.Name(), nil
+}
+
+func indent(n int, s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	pad := strings.Repeat(" ", n)
+	for i := range lines {
+		lines[i] = pad + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
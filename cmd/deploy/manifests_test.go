@@ -0,0 +1,131 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+// prepareManifests prepares the TLS bundle, cloud lua module and Helm values
+// for rendering manifests.
+func prepareManifests(t *testing.T) (*deployContext, map[string]interface{}) {
+	dir := t.TempDir()
+	tlsDir := filepath.Join(dir, "tls")
+	moduleDir := filepath.Join(dir, "cloud_lua_module")
+	assert.NoError(t, os.MkdirAll(tlsDir, 0755), "create tls dir")
+	for _, file := range []string{"tls.crt", "tls.key", "ca.crt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(tlsDir, file), []byte(file), 0644), "write tls file")
+	}
+	for _, file := range []string{"cloud.ljbc", "cloud/agent.ljbc", "cloud/metrics.ljbc", "cloud/utils.ljbc",
+		"apisix/cli/etcd.ljbc", "apisix/core/config_etcd.ljbc"} {
+		filename := filepath.Join(moduleDir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755), "create module dir")
+		assert.NoError(t, os.WriteFile(filename, []byte(file), 0644), "write module file")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err := template.Must(template.New("").Parse(_helmStartupConfigTpl)).Execute(buf, helmConfig{
+		ImageRepository: "apache/apisix",
+		ImageTag:        "2.15.0-centos",
+		ReplicaCount:    3,
+	})
+	assert.NoError(t, err, "render helm values")
+
+	options.Global.Deploy.Name = "apisix-test"
+	ctx := &deployContext{
+		tlsDir:            tlsDir,
+		cloudLuaModuleDir: moduleDir,
+		KubernetesOpts: &options.KubernetesDeployOptions{
+			Namespace: "my-apisix",
+		},
+	}
+
+	values, err := apisix.MergeConfig(nil, buf.Bytes())
+	assert.NoError(t, err, "merge helm values")
+	assert.NoError(t, injectCloudModuleMounts(ctx, values), "inject cloud lua module mounts")
+	return ctx, values
+}
+
+func TestRenderKubernetesManifests(t *testing.T) {
+	ctx, values := prepareManifests(t)
+
+	manifests, err := renderKubernetesManifests(ctx, values)
+	assert.NoError(t, err, "render manifests")
+
+	objects := make(map[string]map[string]interface{})
+	decoder := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			assert.True(t, errors.Is(err, io.EOF), "decode manifests")
+			break
+		}
+		metadata := obj["metadata"].(map[string]interface{})
+		objects[obj["kind"].(string)+"/"+metadata["name"].(string)] = obj
+	}
+	assert.Len(t, objects, 6, "check the number of objects")
+	for _, key := range []string{"Namespace/my-apisix", "Secret/cloud-ssl", "ConfigMap/cloud-module",
+		"ConfigMap/apisix-test-config", "Deployment/apisix-test", "Service/apisix-test-gateway"} {
+		assert.Contains(t, objects, key, "check object")
+	}
+
+	secret := objects["Secret/cloud-ssl"]["data"].(map[string]interface{})
+	assert.Equal(t, "Y2EuY3J0", secret["ca.crt"], "check the CA certificate")
+	module := objects["ConfigMap/cloud-module"]["binaryData"].(map[string]interface{})
+	assert.Len(t, module, 6, "check the cloud lua module")
+	assert.Equal(t, "Y2xvdWQvYWdlbnQubGpiYw==", module["cloud-agent.ljbc"], "check the cloud lua module")
+
+	var config map[string]interface{}
+	data := objects["ConfigMap/apisix-test-config"]["data"].(map[string]interface{})
+	assert.NoError(t, yaml.Unmarshal([]byte(data["config.yaml"].(string)), &config), "unmarshal apisix config")
+	assert.Equal(t, map[string]interface{}{
+		"enable_admin":    false,
+		"extra_lua_path":  "/lua-module-hook/?.ljbc",
+		"lua_module_hook": "cloud",
+		"ssl": map[string]interface{}{
+			"ssl_trusted_certificate": "/usr/local/apisix/conf/ssl/ca.crt",
+		},
+	}, config["apisix"], "check the apisix config")
+	assert.Equal(t, map[string]interface{}{
+		"host":    []interface{}{"https://foo.com:443"},
+		"timeout": 30,
+		"tls": map[string]interface{}{
+			"cert":   "/etcd-ssl/tls.crt",
+			"key":    "/etcd-ssl/tls.key",
+			"sni":    "foo.com",
+			"verify": true,
+		},
+	}, config["etcd"], "check the etcd config")
+
+	spec := objects["Deployment/apisix-test"]["spec"].(map[string]interface{})
+	assert.Equal(t, 3, spec["replicas"], "check replicas")
+	podSpec := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "apache/apisix:2.15.0-centos", container["image"], "check image")
+	// The APISIX config, APISIX ID, two TLS secrets and six cloud lua module files.
+	assert.Len(t, container["volumeMounts"], 10, "check volume mounts")
+}
@@ -0,0 +1,344 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/container"
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+const (
+	_protocolTCP = "tcp"
+	_protocolUDP = "udp"
+)
+
+// portMapping publishes a container port to the host.
+type portMapping struct {
+	HostPort      int
+	ContainerPort int
+	Protocol      string
+}
+
+// String returns the mapping in the format of the docker run -p option.
+func (m portMapping) String() string {
+	mapping := fmt.Sprintf("%d:%d", m.HostPort, m.ContainerPort)
+	if m.Protocol != _protocolTCP {
+		mapping += "/" + m.Protocol
+	}
+	return mapping
+}
+
+// parsePortMapping parses the port mapping in the format of
+// host:container[/protocol], the protocol is tcp by default.
+func parsePortMapping(value string) (portMapping, error) {
+	mapping := portMapping{
+		Protocol: _protocolTCP,
+	}
+	ports, protocol, found := strings.Cut(value, "/")
+	if found {
+		mapping.Protocol = strings.ToLower(protocol)
+		if mapping.Protocol != _protocolTCP && mapping.Protocol != _protocolUDP {
+			return mapping, fmt.Errorf("invalid port mapping %s: unknown protocol %s", value, protocol)
+		}
+	}
+	host, containerPort, found := strings.Cut(ports, ":")
+	if !found {
+		return mapping, fmt.Errorf("invalid port mapping %s: should be in the format of host:container[/protocol]", value)
+	}
+	var err error
+	if mapping.HostPort, err = parsePort(host); err != nil {
+		return mapping, errors.Wrapf(err, "invalid port mapping %s", value)
+	}
+	if mapping.ContainerPort, err = parsePort(containerPort); err != nil {
+		return mapping, errors.Wrapf(err, "invalid port mapping %s", value)
+	}
+	return mapping, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %s", value)
+	}
+	return port, nil
+}
+
+// portPlan contains the ports that APISIX listens on in the container and
+// the ones published to the host, both the APISIX configuration and the
+// docker run arguments are generated from it.
+type portPlan struct {
+	HTTP  portMapping
+	HTTPS portMapping
+	// Extra contains the additional listeners, e.g. the stream proxy and
+	// the Prometheus export server.
+	Extra []portMapping
+	// Replicas is the number of containers, the host ports of the i-th
+	// replica are incremented by i.
+	Replicas int
+	// OverrideHTTP and OverrideHTTPS indicate whether the container ports
+	// are specified by --http-port and --https-port, the ones configured
+	// in the APISIX configuration are followed otherwise.
+	OverrideHTTP  bool
+	OverrideHTTPS bool
+}
+
+// newPortPlan creates the port plan from the docker deploy options.
+func newPortPlan(opts *options.DockerDeployOptions) (*portPlan, error) {
+	plan := &portPlan{
+		HTTP: portMapping{
+			HostPort:      opts.HTTPHostPort,
+			ContainerPort: opts.HTTPPort,
+			Protocol:      _protocolTCP,
+		},
+		HTTPS: portMapping{
+			HostPort:      opts.HTTPSHostPort,
+			ContainerPort: opts.HTTPSPort,
+			Protocol:      _protocolTCP,
+		},
+		Replicas: opts.Replicas,
+	}
+	if plan.Replicas < 1 {
+		plan.Replicas = 1
+	}
+	for _, value := range opts.Ports {
+		mapping, err := parsePortMapping(value)
+		if err != nil {
+			return nil, err
+		}
+		plan.Extra = append(plan.Extra, mapping)
+	}
+
+	containerPorts := make(map[string]struct{})
+	for _, mapping := range plan.mappings() {
+		containerPort := fmt.Sprintf("%d/%s", mapping.ContainerPort, mapping.Protocol)
+		if _, ok := containerPorts[containerPort]; ok {
+			return nil, fmt.Errorf("container port %s is published more than once", containerPort)
+		}
+		containerPorts[containerPort] = struct{}{}
+	}
+
+	hostPorts := make(map[string]struct{})
+	for i := 0; i < plan.Replicas; i++ {
+		for _, mapping := range plan.forReplica(i).mappings() {
+			if mapping.HostPort > 65535 {
+				return nil, fmt.Errorf("host port %d of replica %d is out of range", mapping.HostPort, i)
+			}
+			hostPort := fmt.Sprintf("%d/%s", mapping.HostPort, mapping.Protocol)
+			if _, ok := hostPorts[hostPort]; ok {
+				if i > 0 {
+					return nil, fmt.Errorf("host port %s is published more than once, the host ports are incremented for each replica", hostPort)
+				}
+				return nil, fmt.Errorf("host port %s is published more than once", hostPort)
+			}
+			hostPorts[hostPort] = struct{}{}
+		}
+	}
+	return plan, nil
+}
+
+// forReplica returns the port plan of the i-th replica, whose host ports are
+// incremented by i so that all the replicas can be published on the host.
+func (p *portPlan) forReplica(i int) *portPlan {
+	shift := func(mapping portMapping) portMapping {
+		mapping.HostPort += i
+		return mapping
+	}
+	plan := &portPlan{
+		HTTP:          shift(p.HTTP),
+		HTTPS:         shift(p.HTTPS),
+		Replicas:      1,
+		OverrideHTTP:  p.OverrideHTTP,
+		OverrideHTTPS: p.OverrideHTTPS,
+	}
+	for _, mapping := range p.Extra {
+		plan.Extra = append(plan.Extra, shift(mapping))
+	}
+	return plan
+}
+
+func (p *portPlan) mappings() []portMapping {
+	return append([]portMapping{p.HTTP, p.HTTPS}, p.Extra...)
+}
+
+// checkHostPorts checks if all the host ports can be published by the
+// runtime.
+func (p *portPlan) checkHostPorts(runtime container.Runtime) error {
+	for i := 0; i < p.Replicas; i++ {
+		for _, mapping := range p.forReplica(i).mappings() {
+			if err := runtime.CheckHostPort(mapping.HostPort); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// publishArgs returns the docker run arguments to publish the ports.
+func (p *portPlan) publishArgs() []string {
+	var args []string
+	for _, mapping := range p.mappings() {
+		args = append(args, "-p", mapping.String())
+	}
+	return args
+}
+
+// applyTo sets the HTTP and HTTPS listen ports in the APISIX configuration
+// if they're overridden, other options of the first listener (e.g.
+// enable_http2) and other listeners are kept. Otherwise, the container ports
+// follow the first listeners in the configuration (if any).
+func (p *portPlan) applyTo(config map[string]interface{}) {
+	if p.OverrideHTTP {
+		apisixConfig := childMap(config, "apisix")
+		apisixConfig["node_listen"] = listenWithPort(apisixConfig["node_listen"], p.HTTP.ContainerPort, false)
+	} else if port := firstListenPort(nodeListen(config)); port > 0 {
+		p.HTTP.ContainerPort = port
+	}
+	if p.OverrideHTTPS {
+		sslConfig := childMap(childMap(config, "apisix"), "ssl")
+		sslConfig["listen"] = listenWithPort(sslConfig["listen"], p.HTTPS.ContainerPort, true)
+	} else if port := firstListenPort(sslListen(config)); port > 0 {
+		p.HTTPS.ContainerPort = port
+	}
+}
+
+func nodeListen(config map[string]interface{}) interface{} {
+	apisixConfig, _ := config["apisix"].(map[string]interface{})
+	return apisixConfig["node_listen"]
+}
+
+func sslListen(config map[string]interface{}) interface{} {
+	apisixConfig, _ := config["apisix"].(map[string]interface{})
+	sslConfig, _ := apisixConfig["ssl"].(map[string]interface{})
+	return sslConfig["listen"]
+}
+
+// firstListenPort returns the port of the first listener, which might be
+// a port number, an address or an object with the port field. Zero is
+// returned if there is no listener.
+func firstListenPort(listen interface{}) int {
+	if entries, ok := listen.([]interface{}); ok {
+		if len(entries) == 0 {
+			return 0
+		}
+		listen = entries[0]
+	}
+	if listener, ok := listen.(map[string]interface{}); ok {
+		listen = listener["port"]
+	}
+	return portOf(listen)
+}
+
+// unknownListeners returns the extra ports that APISIX doesn't listen on
+// according to the configuration.
+func (p *portPlan) unknownListeners(config map[string]interface{}) []string {
+	listeners := configuredListeners(config)
+	var unknown []string
+	for _, mapping := range p.Extra {
+		port := fmt.Sprintf("%d/%s", mapping.ContainerPort, mapping.Protocol)
+		if _, ok := listeners[port]; !ok {
+			unknown = append(unknown, port)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// configuredListeners collects the ports (in the format of port/protocol)
+// of the stream proxy, the control API and the Prometheus export server.
+func configuredListeners(config map[string]interface{}) map[string]struct{} {
+	listeners := make(map[string]struct{})
+	add := func(value interface{}, protocol string) {
+		if port := portOf(value); port > 0 {
+			listeners[fmt.Sprintf("%d/%s", port, protocol)] = struct{}{}
+		}
+	}
+
+	apisixConfig, _ := config["apisix"].(map[string]interface{})
+	streamProxy, _ := apisixConfig["stream_proxy"].(map[string]interface{})
+	for protocol, key := range map[string]string{_protocolTCP: "tcp", _protocolUDP: "udp"} {
+		entries, _ := streamProxy[key].([]interface{})
+		for _, entry := range entries {
+			if m, ok := entry.(map[string]interface{}); ok {
+				entry = m["addr"]
+			}
+			add(entry, protocol)
+		}
+	}
+	if control, ok := apisixConfig["control"].(map[string]interface{}); ok {
+		add(control["port"], _protocolTCP)
+	}
+	pluginAttr, _ := config["plugin_attr"].(map[string]interface{})
+	prometheus, _ := pluginAttr["prometheus"].(map[string]interface{})
+	if exportAddr, ok := prometheus["export_addr"].(map[string]interface{}); ok {
+		add(exportAddr["port"], _protocolTCP)
+	}
+	return listeners
+}
+
+// portOf returns the port of the listener, which might be a port number or
+// an address like "127.0.0.1:9100".
+func portOf(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case string:
+		if _, port, err := net.SplitHostPort(v); err == nil {
+			v = port
+		}
+		port, _ := strconv.Atoi(v)
+		return port
+	default:
+		return 0
+	}
+}
+
+// listenWithPort replaces the port of the first listener, the rest of the
+// listeners are kept.
+func listenWithPort(current interface{}, port int, object bool) []interface{} {
+	entries, _ := current.([]interface{})
+	var first interface{} = port
+	if object {
+		first = map[string]interface{}{"port": port}
+	}
+	if len(entries) > 0 {
+		if listener, ok := entries[0].(map[string]interface{}); ok {
+			copied := make(map[string]interface{}, len(listener))
+			for k, v := range listener {
+				copied[k] = v
+			}
+			copied["port"] = port
+			first = copied
+		}
+		return append([]interface{}{first}, entries[1:]...)
+	}
+	return []interface{}{first}
+}
+
+func childMap(parent map[string]interface{}, key string) map[string]interface{} {
+	child, ok := parent[key].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		parent[key] = child
+	}
+	return child
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+func TestParsePortMapping(t *testing.T) {
+	testCases := []struct {
+		value       string
+		want        portMapping
+		errorReason string
+	}{
+		{
+			value: "9100:9101",
+			want:  portMapping{HostPort: 9100, ContainerPort: 9101, Protocol: "tcp"},
+		},
+		{
+			value: "9200:9200/UDP",
+			want:  portMapping{HostPort: 9200, ContainerPort: 9200, Protocol: "udp"},
+		},
+		{
+			value:       "9100",
+			errorReason: "invalid port mapping 9100: should be in the format of host:container[/protocol]",
+		},
+		{
+			value:       "9100:9100/sctp",
+			errorReason: "invalid port mapping 9100:9100/sctp: unknown protocol sctp",
+		},
+		{
+			value:       "0:9100",
+			errorReason: "invalid port mapping 0:9100: invalid port 0",
+		},
+		{
+			value:       "9100:abc",
+			errorReason: "invalid port mapping 9100:abc: invalid port abc",
+		},
+	}
+	for _, tc := range testCases {
+		mapping, err := parsePortMapping(tc.value)
+		if tc.errorReason != "" {
+			assert.EqualError(t, err, tc.errorReason, "parse %s", tc.value)
+			continue
+		}
+		assert.NoError(t, err, "parse %s", tc.value)
+		assert.Equal(t, tc.want, mapping, "parse %s", tc.value)
+	}
+}
+
+func TestPortPlan(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ports       []string
+		errorReason string
+		publishArgs []string
+	}{
+		{
+			name:        "default ports",
+			publishArgs: []string{"-p", "80:9080", "-p", "443:9443"},
+		},
+		{
+			name:        "extra ports",
+			ports:       []string{"9100:9100", "9100:9100/udp", "9091:9091/tcp"},
+			publishArgs: []string{"-p", "80:9080", "-p", "443:9443", "-p", "9100:9100", "-p", "9100:9100/udp", "-p", "9091:9091"},
+		},
+		{
+			name:        "duplicated host port",
+			ports:       []string{"443:9100"},
+			errorReason: "host port 443/tcp is published more than once",
+		},
+		{
+			name:        "duplicated container port",
+			ports:       []string{"8080:9080"},
+			errorReason: "container port 9080/tcp is published more than once",
+		},
+		{
+			name:        "invalid port mapping",
+			ports:       []string{"9100"},
+			errorReason: "invalid port mapping 9100",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := newPortPlan(&options.DockerDeployOptions{
+				HTTPHostPort:  80,
+				HTTPSHostPort: 443,
+				HTTPPort:      9080,
+				HTTPSPort:     9443,
+				Ports:         tc.ports,
+			})
+			if tc.errorReason != "" {
+				assert.Error(t, err, "checking error")
+				assert.Contains(t, err.Error(), tc.errorReason, "checking error reason")
+				return
+			}
+			assert.NoError(t, err, "checking error")
+			assert.Equal(t, tc.publishArgs, plan.publishArgs(), "checking publish args")
+		})
+	}
+}
+
+func TestPortPlanForReplica(t *testing.T) {
+	plan, err := newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  9080,
+		HTTPSHostPort: 9443,
+		HTTPPort:      9080,
+		HTTPSPort:     9443,
+		Ports:         []string{"9100:9100/udp"},
+		Replicas:      3,
+	})
+	assert.NoError(t, err, "create port plan")
+	assert.Equal(t, []string{"-p", "9080:9080", "-p", "9443:9443", "-p", "9100:9100/udp"}, plan.forReplica(0).publishArgs(), "checking publish args of replica 0")
+	assert.Equal(t, []string{"-p", "9082:9080", "-p", "9445:9443", "-p", "9102:9100/udp"}, plan.forReplica(2).publishArgs(), "checking publish args of replica 2")
+
+	_, err = newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  9080,
+		HTTPSHostPort: 9081,
+		HTTPPort:      9080,
+		HTTPSPort:     9443,
+		Replicas:      2,
+	})
+	assert.EqualError(t, err, "host port 9081/tcp is published more than once, the host ports are incremented for each replica", "checking overlapped host ports")
+
+	_, err = newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  65535,
+		HTTPSHostPort: 9443,
+		HTTPPort:      9080,
+		HTTPSPort:     9443,
+		Replicas:      2,
+	})
+	assert.EqualError(t, err, "host port 65536 of replica 1 is out of range", "checking out of range host port")
+}
+
+func TestPortPlanApplyTo(t *testing.T) {
+	plan, err := newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  80,
+		HTTPSHostPort: 443,
+		HTTPPort:      8080,
+		HTTPSPort:     8443,
+		Ports:         []string{"9100:9100", "9200:9200/udp", "9091:9091", "9090:9090", "9300:9300/udp"},
+	})
+	assert.NoError(t, err, "create port plan")
+	plan.OverrideHTTP = true
+	plan.OverrideHTTPS = true
+
+	var config map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+apisix:
+  ssl:
+    listen:
+      - port: 9443
+        enable_http2: true
+  stream_proxy:
+    tcp:
+      - 9100
+    udp:
+      - "0.0.0.0:9200"
+plugin_attr:
+  prometheus:
+    export_addr:
+      ip: 0.0.0.0
+      port: 9091
+`), &config), "unmarshal config")
+
+	plan.applyTo(config)
+	apisixConfig := config["apisix"].(map[string]interface{})
+	assert.Equal(t, []interface{}{8080}, apisixConfig["node_listen"], "checking node_listen")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"port": 8443, "enable_http2": true},
+	}, apisixConfig["ssl"].(map[string]interface{})["listen"], "checking ssl.listen")
+
+	assert.Equal(t, []string{"9090/tcp", "9300/udp"}, plan.unknownListeners(config), "checking unknown listeners")
+
+	config = map[string]interface{}{}
+	plan.applyTo(config)
+	assert.Equal(t, map[string]interface{}{
+		"apisix": map[string]interface{}{
+			"node_listen": []interface{}{8080},
+			"ssl": map[string]interface{}{
+				"listen": []interface{}{map[string]interface{}{"port": 8443}},
+			},
+		},
+	}, config, "checking empty config")
+}
+
+func TestPortPlanApplyToMultipleListeners(t *testing.T) {
+	config := func() map[string]interface{} {
+		var config map[string]interface{}
+		assert.NoError(t, yaml.Unmarshal([]byte(`
+apisix:
+  node_listen:
+    - 8000
+    - 8001
+  ssl:
+    listen:
+      - port: 8443
+        enable_http2: true
+      - port: 8444
+`), &config), "unmarshal config")
+		return config
+	}
+
+	// The container ports follow the configuration if they're not specified.
+	plan, err := newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  9080,
+		HTTPSHostPort: 9443,
+		HTTPPort:      9080,
+		HTTPSPort:     9443,
+	})
+	assert.NoError(t, err, "create port plan")
+	unchanged := config()
+	plan.applyTo(unchanged)
+	assert.Equal(t, config(), unchanged, "checking the configuration is not changed")
+	assert.Equal(t, []string{"-p", "9080:8000", "-p", "9443:8443"}, plan.publishArgs(), "checking publish args")
+
+	// Only the first listeners are changed if the container ports are specified.
+	plan, err = newPortPlan(&options.DockerDeployOptions{
+		HTTPHostPort:  9080,
+		HTTPSHostPort: 9443,
+		HTTPPort:      9080,
+		HTTPSPort:     9443,
+	})
+	assert.NoError(t, err, "create port plan")
+	plan.OverrideHTTP = true
+	plan.OverrideHTTPS = true
+	changed := config()
+	plan.applyTo(changed)
+	apisixConfig := changed["apisix"].(map[string]interface{})
+	assert.Equal(t, []interface{}{9080, 8001}, apisixConfig["node_listen"], "checking node_listen")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"port": 9443, "enable_http2": true},
+		map[string]interface{}{"port": 8444},
+	}, apisixConfig["ssl"].(map[string]interface{})["listen"], "checking ssl.listen")
+	assert.Equal(t, []string{"-p", "9080:9080", "-p", "9443:9443"}, plan.publishArgs(), "checking publish args")
+}
@@ -0,0 +1,274 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+const (
+	// _readinessLogLines is the number of log lines in the failure report.
+	_readinessLogLines = 20
+)
+
+var (
+	// _readinessInterval is the interval between two readiness checks.
+	_readinessInterval = 2 * time.Second
+	// _probe probes the data plane address.
+	_probe = func(ctx context.Context, address string) error {
+		return apisix.Probe(ctx, address, _readinessInterval)
+	}
+)
+
+// workloadFailedError indicates the workload fails and won't recover by
+// itself, so there is no need to wait any longer.
+type workloadFailedError struct {
+	reason string
+}
+
+func (e *workloadFailedError) Error() string {
+	return e.reason
+}
+
+func workloadFailed(format string, args ...interface{}) error {
+	return &workloadFailedError{reason: fmt.Sprintf(format, args...)}
+}
+
+// readiness verifies that a deployment is ready after it's created. It
+// inspects the workload state, probes the data plane port, and checks if
+// the APISIX instances are registered to API7 Cloud, in order.
+type readiness struct {
+	// state returns the current state of the workload and whether it's
+	// running. The error is a workloadFailedError if the workload fails.
+	state func(ctx context.Context) (string, bool, error)
+	// address is the data plane address to probe, it's skipped if empty.
+	address string
+	// logs returns the recent logs of the workload for the failure report.
+	logs func(ctx context.Context) (string, error)
+	// apisixIDs returns the APISIX IDs to verify, it's called after the
+	// workload is running.
+	apisixIDs func(ctx context.Context) ([]string, error)
+	clusterID sdk.ID
+}
+
+// readinessCheck is the result of a single readiness check.
+type readinessCheck struct {
+	ok     bool
+	detail string
+}
+
+func (c readinessCheck) String() string {
+	if c.ok {
+		return "OK (" + c.detail + ")"
+	}
+	if c.detail == "" {
+		return "not checked"
+	}
+	return c.detail
+}
+
+// readinessReport records the latest results of the readiness checks.
+type readinessReport struct {
+	Workload     readinessCheck
+	DataPlane    readinessCheck
+	ControlPlane readinessCheck
+	// APISIXIDs are the APISIX IDs that were verified.
+	APISIXIDs []string
+	// Logs are the recent logs of the workload, it's only fetched when
+	// the deployment is not ready.
+	Logs string
+	// Err is the reason why the deployment is not ready.
+	Err error
+}
+
+// Ready returns whether the deployment is ready.
+func (r *readinessReport) Ready() bool {
+	return r.Err == nil
+}
+
+// String renders the failure report.
+func (r *readinessReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "APISIX is not ready: %s\n", r.Err)
+	fmt.Fprintf(&b, "  Workload:      %s\n", r.Workload)
+	fmt.Fprintf(&b, "  Data plane:    %s\n", r.DataPlane)
+	fmt.Fprintf(&b, "  Control plane: %s\n", r.ControlPlane)
+	if r.Logs != "" {
+		fmt.Fprintf(&b, "\nRecent logs:\n%s\n", strings.TrimRight(r.Logs, "\n"))
+	}
+	return b.String()
+}
+
+// wait polls the readiness checks until all of them pass, the workload
+// fails or the timeout is reached.
+func (r *readiness) wait(ctx context.Context, timeout time.Duration) *readinessReport {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		report  = &readinessReport{}
+		lastErr error
+	)
+	for {
+		current := *report
+		err := r.check(ctx, &current)
+		if err == nil {
+			return &current
+		}
+		// The deadline might be reached in the middle of a check, whose
+		// error would hide the real reason and fail the passed checks, so
+		// the results of the previous round are kept in this case.
+		if ctx.Err() == nil || lastErr == nil {
+			*report = current
+			lastErr = err
+		}
+		var failed *workloadFailedError
+		if errors.As(lastErr, &failed) {
+			report.Err = lastErr
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			report.Err = errors.Errorf("timed out after %s, %s", timeout, lastErr)
+		case <-time.After(_readinessInterval):
+			continue
+		}
+		break
+	}
+
+	if r.logs != nil {
+		// The context might be done, the logs are fetched with a fresh one.
+		logCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		logs, err := r.logs(logCtx)
+		if err != nil {
+			logs = fmt.Sprintf("failed to fetch logs: %s", err)
+		}
+		report.Logs = logs
+	}
+	return report
+}
+
+// check runs the readiness checks once, it stops at the first failed check.
+func (r *readiness) check(ctx context.Context, report *readinessReport) error {
+	state, running, err := r.state(ctx)
+	report.Workload = readinessCheck{ok: running, detail: state}
+	if err != nil {
+		return err
+	}
+	if !running {
+		return errors.Errorf("workload is %s", state)
+	}
+
+	if r.address != "" {
+		if err = _probe(ctx, r.address); err != nil {
+			report.DataPlane = readinessCheck{detail: err.Error()}
+			return errors.Wrap(err, "data plane is unreachable")
+		}
+		report.DataPlane = readinessCheck{ok: true, detail: r.address}
+	} else {
+		report.DataPlane = readinessCheck{ok: true, detail: "verified by the workload state"}
+	}
+
+	if len(report.APISIXIDs) == 0 {
+		ids, err := r.apisixIDs(ctx)
+		if err != nil {
+			report.ControlPlane = readinessCheck{detail: err.Error()}
+			return errors.Wrap(err, "failed to get APISIX ID")
+		}
+		report.APISIXIDs = ids
+	}
+	if err = r.checkRegistration(report.APISIXIDs); err != nil {
+		report.ControlPlane = readinessCheck{detail: err.Error()}
+		return err
+	}
+	report.ControlPlane = readinessCheck{ok: true, detail: "registered as " + strings.Join(report.APISIXIDs, ", ")}
+	return nil
+}
+
+// checkRegistration checks if all the instances are registered to API7 Cloud
+// and are healthy.
+func (r *readiness) checkRegistration(ids []string) error {
+	if len(ids) == 0 {
+		return errors.New("no APISIX ID to verify")
+	}
+	instances, err := cloud.Client().ListGatewayInstances(r.clusterID)
+	if err != nil {
+		return err
+	}
+	status := make(map[string]sdk.GatewayInstanceStatus, len(instances))
+	for _, instance := range instances {
+		status[instance.ID] = instance.Status
+	}
+	for _, id := range ids {
+		s, ok := status[id]
+		if !ok {
+			return errors.Errorf("instance %s is not registered to API7 Cloud", id)
+		}
+		if s != sdk.GatewayInstanceHealthy {
+			return errors.Errorf("instance %s is %s on API7 Cloud", id, s)
+		}
+	}
+	return nil
+}
+
+// waitForDeployment waits for the deployment instances to be ready if it's
+// required, it exits with the failure report if any of them is not ready.
+// All the instances share the same deadline.
+func waitForDeployment(ctx context.Context, rs ...*readiness) {
+	opts := options.Global.Deploy
+	if !opts.Wait || options.Global.DryRun {
+		return
+	}
+	output.Infof("Waiting for APISIX to be ready (timeout: %s)", opts.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	for _, r := range rs {
+		report := r.wait(ctx, opts.Timeout)
+		if !report.Ready() {
+			output.Errorf("%s", report)
+			return
+		}
+		output.Infof("APISIX is ready and registered to API7 Cloud as %s", strings.Join(report.APISIXIDs, ", "))
+	}
+}
+
+// staticAPISIXIDs returns the apisixIDs function of readiness for the known
+// APISIX IDs.
+func staticAPISIXIDs(ids ...string) func(ctx context.Context) ([]string, error) {
+	return func(ctx context.Context) ([]string, error) {
+		return ids, nil
+	}
+}
+
+func commandError(stderr string, err error) error {
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		return errors.Wrap(err, stderr)
+	}
+	return err
+}
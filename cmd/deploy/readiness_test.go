@@ -0,0 +1,338 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/container"
+)
+
+func TestReadinessWait(t *testing.T) {
+	interval := _readinessInterval
+	_readinessInterval = 10 * time.Millisecond
+	defer func() {
+		_readinessInterval = interval
+	}()
+
+	probe := _probe
+	defer func() {
+		_probe = probe
+	}()
+
+	running := func(ctx context.Context) (string, bool, error) {
+		return "running", true, nil
+	}
+	logs := func(ctx context.Context) (string, error) {
+		return "recent logs", nil
+	}
+	reachable := func(ctx context.Context, address string) error {
+		return nil
+	}
+
+	testCases := []struct {
+		name          string
+		state         func(ctx context.Context) (string, bool, error)
+		probe         func(ctx context.Context, address string) error
+		instances     []sdk.GatewayInstance
+		listTimes     int
+		expectedError string
+		expectedLogs  string
+		// dataPlaneOK indicates the data plane check passes in the report.
+		dataPlaneOK bool
+	}{
+		{
+			name:  "ready",
+			state: running,
+			probe: reachable,
+			instances: []sdk.GatewayInstance{
+				{
+					GatewayInstancePayload: sdk.GatewayInstancePayload{ID: "apisix-1"},
+					Status:                 sdk.GatewayInstanceHealthy,
+				},
+			},
+			listTimes: 1,
+		},
+		{
+			name: "workload failed",
+			state: func(ctx context.Context) (string, bool, error) {
+				return "exited", false, workloadFailed("container is exited")
+			},
+			probe:         reachable,
+			expectedError: "container is exited",
+			expectedLogs:  "recent logs",
+		},
+		{
+			name: "workload not running",
+			state: func(ctx context.Context) (string, bool, error) {
+				return "created", false, nil
+			},
+			probe:         reachable,
+			expectedError: "timed out after 100ms, workload is created",
+			expectedLogs:  "recent logs",
+		},
+		{
+			name:  "data plane unreachable",
+			state: running,
+			probe: func(ctx context.Context, address string) error {
+				return errors.New("connection refused")
+			},
+			expectedError: "data plane is unreachable",
+			expectedLogs:  "recent logs",
+		},
+		{
+			name:  "not registered",
+			state: running,
+			probe: reachable,
+			instances: []sdk.GatewayInstance{
+				{
+					GatewayInstancePayload: sdk.GatewayInstancePayload{ID: "apisix-2"},
+					Status:                 sdk.GatewayInstanceHealthy,
+				},
+			},
+			listTimes:     -1,
+			expectedError: "instance apisix-1 is not registered to API7 Cloud",
+			expectedLogs:  "recent logs",
+			dataPlaneOK:   true,
+		},
+		{
+			name:  "only heartbeats",
+			state: running,
+			probe: reachable,
+			instances: []sdk.GatewayInstance{
+				{
+					GatewayInstancePayload: sdk.GatewayInstancePayload{ID: "apisix-1"},
+					Status:                 sdk.GatewayInstanceOnlyHeartbeats,
+				},
+			},
+			listTimes:     -1,
+			expectedError: "instance apisix-1 is Only Heartbeats on API7 Cloud",
+			expectedLogs:  "recent logs",
+			dataPlaneOK:   true,
+		},
+		{
+			name:  "timed out during the probe",
+			state: running,
+			probe: func() func(ctx context.Context, address string) error {
+				var probed bool
+				return func(ctx context.Context, address string) error {
+					if !probed {
+						probed = true
+						return nil
+					}
+					// The deadline is reached in the middle of the probe.
+					<-ctx.Done()
+					return ctx.Err()
+				}
+			}(),
+			instances: []sdk.GatewayInstance{
+				{
+					GatewayInstancePayload: sdk.GatewayInstancePayload{ID: "apisix-2"},
+					Status:                 sdk.GatewayInstanceHealthy,
+				},
+			},
+			listTimes:     1,
+			expectedError: "timed out after 100ms, instance apisix-1 is not registered to API7 Cloud",
+			expectedLogs:  "recent logs",
+			dataPlaneOK:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			api := cloud.NewMockAPI(gomock.NewController(t))
+			call := api.EXPECT().ListGatewayInstances(sdk.ID(1)).Return(tc.instances, nil)
+			if tc.listTimes >= 0 {
+				call.Times(tc.listTimes)
+			} else {
+				call.AnyTimes()
+			}
+			cloud.DefaultClient = api
+
+			_probe = tc.probe
+			r := &readiness{
+				state:     tc.state,
+				address:   "127.0.0.1:9080",
+				logs:      logs,
+				apisixIDs: staticAPISIXIDs("apisix-1"),
+				clusterID: 1,
+			}
+			report := r.wait(context.Background(), 100*time.Millisecond)
+			if tc.expectedError == "" {
+				assert.True(t, report.Ready(), "check if the deployment is ready")
+				assert.Equal(t, []string{"apisix-1"}, report.APISIXIDs, "check the apisix ids")
+				assert.Empty(t, report.Logs, "check the logs")
+				return
+			}
+			assert.False(t, report.Ready(), "check if the deployment is ready")
+			assert.Contains(t, report.Err.Error(), tc.expectedError, "check the error")
+			assert.Equal(t, tc.dataPlaneOK, report.DataPlane.ok, "check the data plane")
+			assert.Equal(t, tc.expectedLogs, report.Logs, "check the logs")
+			assert.Contains(t, report.String(), "Recent logs:\n"+tc.expectedLogs, "check the report")
+		})
+	}
+}
+
+func TestReadinessReport(t *testing.T) {
+	report := &readinessReport{
+		Workload:     readinessCheck{ok: true, detail: "running"},
+		DataPlane:    readinessCheck{detail: "connection refused"},
+		ControlPlane: readinessCheck{},
+		Err:          workloadFailed("data plane is unreachable"),
+	}
+	expected := `APISIX is not ready: data plane is unreachable
+  Workload:      OK (running)
+  Data plane:    connection refused
+  Control plane: not checked
+`
+	assert.Equal(t, expected, report.String(), "check the report")
+}
+
+func TestDockerState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stdout        string
+		state         string
+		running       bool
+		expectedError string
+	}{
+		{name: "running", stdout: "running \n", state: "running", running: true},
+		{name: "healthy", stdout: "running healthy\n", state: "running", running: true},
+		{name: "starting", stdout: "running starting\n", state: "running (starting)"},
+		{name: "restarting", stdout: "restarting\n", state: "restarting"},
+		{name: "exited", stdout: "exited \n", state: "exited", expectedError: "container is exited"},
+		{name: "empty", stdout: "", state: "unknown"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			state, running, err := dockerState(container.ParseState(tc.stdout))
+			assert.Equal(t, tc.state, state, "check the state")
+			assert.Equal(t, tc.running, running, "check if it's running")
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError, "check the error")
+			} else {
+				assert.NoError(t, err, "check the error")
+			}
+		})
+	}
+}
+
+func TestKubernetesPodsState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pods          string
+		state         string
+		running       bool
+		expectedError string
+	}{
+		{
+			name:  "no pods",
+			pods:  `{"items": []}`,
+			state: "no pods",
+		},
+		{
+			name: "all ready",
+			pods: `{"items": [
+				{"metadata": {"name": "apisix-1"}, "status": {"phase": "Running", "containerStatuses": [{"ready": true}]}},
+				{"metadata": {"name": "apisix-2"}, "status": {"phase": "Running", "containerStatuses": [{"ready": true}]}}
+			]}`,
+			state:   "2/2 pods ready",
+			running: true,
+		},
+		{
+			name: "pending",
+			pods: `{"items": [
+				{"metadata": {"name": "apisix-1"}, "status": {"phase": "Running", "containerStatuses": [{"ready": true}]}},
+				{"metadata": {"name": "apisix-2"}, "status": {"phase": "Pending"}}
+			]}`,
+			state: "1/2 pods ready",
+		},
+		{
+			name: "crash loop",
+			pods: `{"items": [
+				{"metadata": {"name": "apisix-1"}, "status": {"phase": "Running", "containerStatuses": [{"ready": false, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}]}}
+			]}`,
+			state:         "0/1 pods ready (apisix-1: CrashLoopBackOff)",
+			expectedError: "pods failed, apisix-1: CrashLoopBackOff",
+		},
+		{
+			name: "container creating",
+			pods: `{"items": [
+				{"metadata": {"name": "apisix-1"}, "status": {"phase": "Pending", "containerStatuses": [{"ready": false, "state": {"waiting": {"reason": "ContainerCreating"}}}]}}
+			]}`,
+			state: "0/1 pods ready",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var pods kubernetesPodList
+			assert.NoError(t, json.Unmarshal([]byte(tc.pods), &pods), "unmarshal pods")
+			state, running, err := kubernetesPodsState(&pods)
+			assert.Equal(t, tc.state, state, "check the state")
+			assert.Equal(t, tc.running, running, "check if it's running")
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError, "check the error")
+			} else {
+				assert.NoError(t, err, "check the error")
+			}
+		})
+	}
+}
+
+func TestHTTPListenPort(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config map[string]interface{}
+		port   int
+	}{
+		{name: "default", config: map[string]interface{}{}, port: 9080},
+		{name: "single port", config: map[string]interface{}{"apisix": map[string]interface{}{"node_listen": 8080}}, port: 8080},
+		{name: "port list", config: map[string]interface{}{"apisix": map[string]interface{}{"node_listen": []interface{}{8081, 8082}}}, port: 8081},
+		{name: "listener object", config: map[string]interface{}{"apisix": map[string]interface{}{"node_listen": []interface{}{map[string]interface{}{"port": 8083, "enable_http2": true}}}}, port: 8083},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.port, httpListenPort(tc.config), "check the http port")
+		})
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "error.log")
+	assert.NoError(t, os.WriteFile(filename, []byte("1\n2\n3\n4\n"), 0644), "write the log file")
+
+	logs, err := tailFile(filename, 2)
+	assert.NoError(t, err, "tail the log file")
+	assert.Equal(t, "3\n4", logs, "check the logs")
+
+	logs, err = tailFile(filename, 10)
+	assert.NoError(t, err, "tail the log file")
+	assert.Equal(t, "1\n2\n3\n4", logs, "check the logs")
+}
@@ -18,17 +18,23 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/api7/cloud-cli/internal/apisix"
 	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/commands"
 	"github.com/api7/cloud-cli/internal/consts"
@@ -39,9 +45,20 @@ import (
 	"github.com/api7/cloud-cli/internal/utils"
 )
 
+const (
+	// _configMapSizeLimit is the size limit of the configmap data.
+	_configMapSizeLimit = 1 << 20
+	// _defaultLuaModuleHookDir is the default directory to mount the cloud
+	// lua module files on Kubernetes.
+	_defaultLuaModuleHookDir = "/lua-module-hook"
+)
+
 var (
+	_configMapKeyRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
 	_targetApisixCliEtcdPath         = "/usr/local/apisix/apisix/cli/etcd.lua"
 	_targetApisixCliLocalStoragePath = "/usr/local/apisix/apisix/cli/local_storage.lua"
+	_apisixIDFileOnBareMetal         = "/usr/local/apisix/conf/apisix.uid"
 )
 
 type deployContext struct {
@@ -49,8 +66,6 @@ type deployContext struct {
 	tlsDir            string
 	apisixConfigDir   string
 	essentialConfig   []byte
-	apisixIDFile      string
-	apisixID          string
 	// Cluster is the current cluster.
 	Cluster        *sdk.Cluster
 	KubernetesOpts *options.KubernetesDeployOptions
@@ -103,22 +118,29 @@ func deployPreRunForDocker(ctx *deployContext) error {
 
 	ctx.essentialConfig = buf.Bytes()
 
-	// We generate the APISIX instance ID and mount to /usr/local/apisix/conf/apisix.uid
-	ctx.apisixID = options.Global.Deploy.APISIXInstanceID
-	if ctx.apisixID == "" {
+	return nil
+}
+
+// saveDockerAPISIXID generates the APISIX instance ID for the container (if
+// it's not specified) and saves it to a file owned by the container, which
+// will be mounted to /usr/local/apisix/conf/apisix.uid.
+func saveDockerAPISIXID(name, apisixID string) (string, string, error) {
+	if apisixID == "" {
 		id, err := uuid.NewRandom()
 		if err != nil {
-			return errors.Wrap(err, "failed to generate APISIX instance ID")
+			return "", "", errors.Wrap(err, "failed to generate APISIX instance ID")
 		}
-		ctx.apisixID = id.String()
+		apisixID = id.String()
 	}
 
-	ctx.apisixIDFile = filepath.Join(persistence.HomeDir, "apisix.uid")
-	if err := os.WriteFile(ctx.apisixIDFile, []byte(ctx.apisixID), 0644); err != nil {
-		return errors.Wrap(err, "failed to save APISIX instance ID")
+	idFile := filepath.Join(persistence.HomeDir, "uid", name+".uid")
+	if err := os.MkdirAll(filepath.Dir(idFile), 0755); err != nil {
+		return "", "", errors.Wrap(err, "failed to create APISIX instance ID directory")
 	}
-
-	return nil
+	if err := os.WriteFile(idFile, []byte(apisixID), 0644); err != nil {
+		return "", "", errors.Wrap(err, "failed to save APISIX instance ID")
+	}
+	return apisixID, idFile, nil
 }
 
 func deployPreRunForBare(ctx *deployContext) error {
@@ -162,7 +184,7 @@ func deployPreRun(ctx *deployContext) error {
 		return errors.Wrap(err, "change apisix config directory permission")
 	}
 
-	cloudLuaModuleDir, err := persistence.SaveCloudLuaModule()
+	cloudLuaModuleDir, err := persistence.SaveCloudLuaModule(options.Global.Deploy.ModuleBundle, options.Global.Deploy.ModuleChecksum)
 	if err != nil {
 		return fmt.Errorf("Failed to save cloud lua module: %s", err)
 	}
@@ -193,7 +215,7 @@ func deployPreRunForKubernetes(ctx *deployContext, kubectl commands.Cmd) error {
 	}
 	ctx.tlsDir = filepath.Join(persistence.TLSDir, cluster.ID.String())
 
-	cloudLuaModuleDir, err := persistence.SaveCloudLuaModule()
+	cloudLuaModuleDir, err := persistence.SaveCloudLuaModule(options.Global.Deploy.ModuleBundle, options.Global.Deploy.ModuleChecksum)
 	if err != nil {
 		return fmt.Errorf("Failed to save cloud lua module: %s", err.Error())
 	}
@@ -218,6 +240,12 @@ func deployPreRunForKubernetes(ctx *deployContext, kubectl commands.Cmd) error {
 	}
 	ctx.essentialConfig = buf.Bytes()
 
+	// The namespace, secret and configmap are parts of the rendered
+	// manifests in the manifests mode, or the kustomization.
+	if opts.Mode == options.KubernetesModeManifests || opts.OutputDir != "" {
+		return nil
+	}
+
 	if err = createOnKubernetes(ctx, types.Namespace, kubectl); err != nil {
 		return fmt.Errorf("Failed to create namespace on kubernetes: %s", err.Error())
 	}
@@ -231,11 +259,143 @@ func deployPreRunForKubernetes(ctx *deployContext, kubectl commands.Cmd) error {
 	return nil
 }
 
-// createOnKubernetes create namespace, secret or configmap on Kubernetes
+// cloudModuleFile is a file of the cloud lua module which is stored in the
+// cloud-module configmap.
+type cloudModuleFile struct {
+	// Key is the key of the file in the configmap.
+	Key string
+	// Path is the filepath on the local filesystem.
+	Path string
+}
+
+// cloudModuleFiles walks the cloud lua module directory and returns the files
+// which should be stored in the cloud-module configmap, in lexical order.
+// The key of a file is its relative path with "/" and "_" replaced by "-",
+// e.g. the key of cloud/agent.ljbc is cloud-agent.ljbc.
+func cloudModuleFiles(dir string) ([]cloudModuleFile, error) {
+	var (
+		files []cloudModuleFile
+		size  int
+		paths = make(map[string]string)
+	)
+	err := filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		key := strings.NewReplacer("/", "-", "_", "-").Replace(rel)
+		if !_configMapKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid configmap key %s for file %s", key, rel)
+		}
+		if conflict, ok := paths[key]; ok {
+			return fmt.Errorf("files %s and %s have the same configmap key %s", conflict, rel, key)
+		}
+		paths[key] = rel
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// The binary data is base64 encoded in the configmap.
+		size += len(key) + base64.StdEncoding.EncodedLen(int(info.Size()))
+		files = append(files, cloudModuleFile{Key: key, Path: filename})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk cloud lua module")
+	}
+	if size > _configMapSizeLimit {
+		return nil, fmt.Errorf("the cloud lua module (%d bytes) exceeds the configmap size limit (%d bytes)", size, _configMapSizeLimit)
+	}
+	return files, nil
+}
+
+// cloudModuleMounts returns the mounts of the cloud lua module files, the
+// files are mounted to the directory of the lua path with their relative
+// paths, e.g. cloud/agent.ljbc is mounted to /lua-module-hook/cloud/agent.ljbc
+// if the lua path is /lua-module-hook/?.ljbc.
+func cloudModuleMounts(luaPath string, dir string, files []cloudModuleFile) ([]cloudModuleFile, error) {
+	mountDir := _defaultLuaModuleHookDir
+	for _, item := range strings.Split(luaPath, ";") {
+		if strings.Contains(item, "?") {
+			mountDir = path.Dir(item)
+			break
+		}
+	}
+
+	mounts := make([]cloudModuleFile, 0, len(files))
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, cloudModuleFile{Key: file.Key, Path: path.Join(mountDir, filepath.ToSlash(rel))})
+	}
+	return mounts, nil
+}
+
+// injectCloudModuleMounts adds the mounts of the cloud lua module files
+// to the Helm values, mounts which already exist in the values are kept.
+func injectCloudModuleMounts(ctx *deployContext, values map[string]interface{}) error {
+	apisixValues, _ := values["apisix"].(map[string]interface{})
+	hook, _ := apisixValues["luaModuleHook"].(map[string]interface{})
+	if enabled, _ := hook["enabled"].(bool); !enabled {
+		return nil
+	}
+	configMapRef, ok := hook["configMapRef"].(map[string]interface{})
+	if !ok {
+		configMapRef = map[string]interface{}{
+			"name": consts.DefaultConfigMapName,
+		}
+		hook["configMapRef"] = configMapRef
+	}
+
+	files, err := cloudModuleFiles(ctx.cloudLuaModuleDir)
+	if err != nil {
+		return err
+	}
+	luaPath, _ := hook["luaPath"].(string)
+	generated, err := cloudModuleMounts(luaPath, ctx.cloudLuaModuleDir, files)
+	if err != nil {
+		return err
+	}
+
+	mounts, _ := configMapRef["mounts"].([]interface{})
+	existing := make(map[string]struct{}, len(mounts))
+	for _, mount := range mounts {
+		if m, ok := mount.(map[string]interface{}); ok {
+			if p, ok := m["path"].(string); ok {
+				existing[p] = struct{}{}
+			}
+		}
+	}
+	for _, mount := range generated {
+		if _, ok := existing[mount.Path]; ok {
+			continue
+		}
+		mounts = append(mounts, map[string]interface{}{
+			"key":  mount.Key,
+			"path": mount.Path,
+		})
+	}
+	configMapRef["mounts"] = mounts
+	return nil
+}
+
+// createOnKubernetes create namespace, secret or configmap on Kubernetes.
+// The secret and configmap are rendered locally and then applied, so that
+// their contents are refreshed if they already exist.
 func createOnKubernetes(ctx *deployContext, k types.K8sResourceKind, kubectl commands.Cmd) error {
 	var (
-		err  error
-		opts = ctx.KubernetesOpts
+		refresh bool
+		opts    = ctx.KubernetesOpts
 	)
 
 	switch k {
@@ -245,25 +405,54 @@ func createOnKubernetes(ctx *deployContext, k types.K8sResourceKind, kubectl com
 		kubectl.AppendArgs("--from-file", fmt.Sprintf("tls.key=%s", filepath.Join(ctx.tlsDir, "tls.key")))
 		kubectl.AppendArgs("--from-file", fmt.Sprintf("ca.crt=%s", filepath.Join(ctx.tlsDir, "ca.crt")))
 		kubectl.AppendArgs("--namespace", opts.Namespace)
+		refresh = true
 	case types.ConfigMap:
 		kubectl.AppendArgs("create", "configmap", consts.DefaultConfigMapName)
-		// TODO: dynamic list files in cloud lua module instead of hard code maybe better
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("cloud.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "cloud.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("cloud-agent.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "cloud/agent.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("cloud-metrics.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "cloud/metrics.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("cloud-utils.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "cloud/utils.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("cloud-file.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "cloud/file.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("apisix-local-storage.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "apisix/local_storage.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("apisix-core-config-etcd.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "apisix/core/config_etcd.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("apisix-cli-etcd.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "apisix/cli/etcd.ljbc")))
-		kubectl.AppendArgs("--from-file", fmt.Sprintf("apisix-cli-local-storage.ljbc=%s", filepath.Join(ctx.cloudLuaModuleDir, "apisix/cli/local_storage.ljbc")))
+		files, err := cloudModuleFiles(ctx.cloudLuaModuleDir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			kubectl.AppendArgs("--from-file", fmt.Sprintf("%s=%s", file.Key, file.Path))
+		}
 		kubectl.AppendArgs("--namespace", opts.Namespace)
+		refresh = true
 	case types.Namespace:
 		kubectl.AppendArgs("create", "ns", opts.Namespace)
 	default:
 		panic(fmt.Sprintf("invaild kind: %d", k))
 	}
 
+	if !refresh {
+		stdout, err := kubectlRun(kubectl)
+		if stdout != "" {
+			output.Verbosef(stdout)
+		}
+		return err
+	}
+
+	kubectl.AppendArgs("--dry-run=client", "--output", "yaml")
+	manifest, err := kubectlRun(kubectl)
+	if err != nil {
+		return err
+	}
+
+	manifestFile, err := saveManifestToTemp([]byte(manifest))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(manifestFile)
+
+	kubectl.AppendArgs("apply", "--filename", manifestFile, "--namespace", opts.Namespace)
+	stdout, err := kubectlRun(kubectl)
+	if stdout != "" {
+		output.Verbosef(stdout)
+	}
+	return err
+}
+
+// kubectlRun runs the kubectl command and returns its stdout.
+func kubectlRun(kubectl commands.Cmd) (string, error) {
 	if options.Global.DryRun {
 		output.Infof("Running:\n%s\n", kubectl.String())
 	} else {
@@ -281,22 +470,21 @@ func createOnKubernetes(ctx *deployContext, k types.K8sResourceKind, kubectl com
 			err = nil
 		}
 	}
-	if stdout != "" {
-		output.Verbosef(stdout)
-	}
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	return nil
+	return stdout, nil
 }
 
-func printInstallDetailForKubernetes(kubectl commands.Cmd) {
+// printInstallDetailForKubernetes prints the installation details and
+// returns the APISIX IDs of the pods.
+func printInstallDetailForKubernetes(kubectl commands.Cmd) []string {
 	var (
 		deploymentName string
 		serviceName    string
 		podsNames      []string
 		APISIXID       string
+		APISIXIDs      []string
 		err            error
 	)
 
@@ -307,37 +495,41 @@ func printInstallDetailForKubernetes(kubectl commands.Cmd) {
 	}()
 
 	output.Infof("\nCongratulations! Your APISIX cluster was deployed successfully on Kubernetes.\n")
-	output.Infof("The Helm release name is: %s", options.Global.Deploy.Name)
+	if options.Global.Deploy.Kubernetes.Mode != options.KubernetesModeManifests {
+		output.Infof("The Helm release name is: %s", options.Global.Deploy.Name)
+	}
 
 	if deploymentName, err = utils.GetDeploymentName(kubectl); err != nil {
 		output.Warnf("Failed to get Deployment: %s", err.Error())
-		return
+		return nil
 	}
 	output.Infof("The APISIX Deployment name is: %s", deploymentName)
 
 	if serviceName, err = utils.GetServiceName(kubectl); err != nil {
 		output.Warnf("Failed to get Service: %s", err.Error())
-		return
+		return nil
 	}
 	output.Infof("The APISIX Service name is: %s", serviceName)
 
 	output.Infof("\nWorkloads:")
-	if podsNames, err = utils.GetPodsNames(kubectl); err != nil {
+	if podsNames, err = utils.GetPodsNames(kubectl, options.Global.Deploy.Name, options.Global.Deploy.Kubernetes.Namespace); err != nil {
 		output.Warnf("Failed to get pods: %s", err.Error())
-		return
+		return nil
 	}
 
 	for _, podName := range podsNames {
 		if APISIXID, err = utils.GetAPISIXID(kubectl, podName); err != nil {
 			output.Warnf("Failed to get APISIXID: %s", err.Error())
-			return
+			return nil
 		}
 		output.Infof("Pod Name: %s APISIX ID: %s", podName, APISIXID)
+		APISIXIDs = append(APISIXIDs, APISIXID)
 	}
+	return APISIXIDs
 }
 
 func getDockerContainerIDByName(ctx context.Context, docker commands.Cmd, name string) (string, error) {
-	docker.AppendArgs("ps", "--filter", "name="+name, "--format", "{{.ID}}")
+	docker.AppendArgs("ps", "--filter", "name=^"+name+"$", "--format", "{{.ID}}")
 	stdout, stderr, err := docker.Run(ctx)
 	if err != nil {
 		return "", err
@@ -407,3 +599,22 @@ func copyFileTo(source, target string) error {
 	}
 	return nil
 }
+
+// recordDeployment saves the deployment to the local registry so that it
+// can be inspected by the status command. The deployment has been made, so
+// failures are only warned.
+func recordDeployment(deployment *persistence.Deployment, config map[string]interface{}) {
+	if options.Global.DryRun {
+		return
+	}
+	hash, err := apisix.ConfigHash(config)
+	if err != nil {
+		output.Warnf("Failed to record the deployment: %s", err)
+		return
+	}
+	deployment.ConfigHash = hash
+	deployment.DeployedAt = time.Now()
+	if err = persistence.SaveDeployment(deployment); err != nil {
+		output.Warnf("Failed to record the deployment: %s", err)
+	}
+}
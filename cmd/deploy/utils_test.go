@@ -128,6 +128,40 @@ func mockCloudModule(t *testing.T) []byte {
 			Name: "cloud_lua_module_beta/apisix/cli/local_storage.ljbc",
 			Body: "this is apisix.cli.local_storage",
 		},
+		{
+			Name: "cloud_lua_module_beta/apisix/core",
+		},
+		{
+			Name: "cloud_lua_module_beta/apisix/core/config_etcd.ljbc",
+			Body: "this is apisix.core.config_etcd",
+		},
+		{
+			Name: "cloud_lua_module_beta/apisix/local_storage.ljbc",
+			Body: "this is apisix.local_storage",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud.ljbc",
+			Body: "this is cloud",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud/agent.ljbc",
+			Body: "this is cloud.agent",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud/metrics.ljbc",
+			Body: "this is cloud.metrics",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud/utils.ljbc",
+			Body: "this is cloud.utils",
+		},
+		{
+			Name: "cloud_lua_module_beta/cloud/file.ljbc",
+			Body: "this is cloud.file",
+		},
 	}
 	tarWriter := tar.NewWriter(gzipWriter)
 	for _, file := range files {
@@ -200,7 +234,7 @@ func TestDeployPreRunForDocker(t *testing.T) {
 					PrivateKey:    "1",
 					CACertificate: "1",
 				}, nil)
-				mockClient.EXPECT().GetCloudLuaModule().Return(nil, errors.New("mock error"))
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(nil, errors.New("mock error"))
 				cloud.DefaultClient = mockClient
 			},
 		},
@@ -222,7 +256,7 @@ func TestDeployPreRunForDocker(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.APISIX).Return("", errors.New("mock error"))
 
@@ -247,7 +281,7 @@ func TestDeployPreRunForDocker(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 				cloud.DefaultClient = mockClient
@@ -286,6 +320,7 @@ etcd:
 
 			defer func() {
 				os.RemoveAll(filepath.Join(persistence.HomeDir, "tls"))
+				os.RemoveAll(filepath.Join(persistence.HomeDir, "modules"))
 			}()
 			ctx := &deployContext{}
 			tc.mockFn(t)
@@ -301,18 +336,37 @@ etcd:
 				assert.NoError(t, err, "check error")
 				assert.Equal(t, tc.filledContext.cloudLuaModuleDir, ctx.cloudLuaModuleDir, "check cloud lua module dir")
 				assert.Equal(t, string(tc.filledContext.essentialConfig), string(ctx.essentialConfig), "check essential config")
-
-				id, err := os.ReadFile(filepath.Join(persistence.HomeDir, "apisix.uid"))
-				assert.Nil(t, err, "read apisix.uid")
-				// We cannot add an assertion if the ID was generated randomly.
-				if tc.specifiedAPISIXID != "" {
-					assert.Equal(t, tc.specifiedAPISIXID, string(id), "check apisix id")
-				}
 			}
 		})
 	}
 }
 
+func TestSaveDockerAPISIXID(t *testing.T) {
+	persistence.HomeDir = filepath.Join(os.TempDir(), ".api7cloud-apisix-id-test")
+	defer os.RemoveAll(persistence.HomeDir)
+
+	id, idFile, err := saveDockerAPISIXID("apisix-0", "abcabc")
+	assert.NoError(t, err, "save specified apisix id")
+	assert.Equal(t, "abcabc", id, "check apisix id")
+	assert.Equal(t, filepath.Join(persistence.HomeDir, "uid", "apisix-0.uid"), idFile, "check apisix id file")
+	data, err := os.ReadFile(idFile)
+	assert.NoError(t, err, "read apisix id file")
+	assert.Equal(t, "abcabc", string(data), "check apisix id file content")
+
+	// Each container has its own ID file.
+	id, idFile, err = saveDockerAPISIXID("apisix-1", "")
+	assert.NoError(t, err, "save generated apisix id")
+	assert.NotEmpty(t, id, "check generated apisix id")
+	assert.NotEqual(t, "abcabc", id, "check generated apisix id")
+	data, err = os.ReadFile(idFile)
+	assert.NoError(t, err, "read apisix id file")
+	assert.Equal(t, id, string(data), "check apisix id file content")
+
+	data, err = os.ReadFile(filepath.Join(persistence.HomeDir, "uid", "apisix-0.uid"))
+	assert.NoError(t, err, "read apisix id file")
+	assert.Equal(t, "abcabc", string(data), "check apisix id file is not overwritten")
+}
+
 func TestDeployPreRunForBare(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -357,7 +411,7 @@ func TestDeployPreRunForBare(t *testing.T) {
 					PrivateKey:    "1",
 					CACertificate: "1",
 				}, nil)
-				mockClient.EXPECT().GetCloudLuaModule().Return(nil, errors.New("mock error"))
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(nil, errors.New("mock error"))
 				cloud.DefaultClient = mockClient
 			},
 		},
@@ -379,7 +433,7 @@ func TestDeployPreRunForBare(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.APISIX).Return("", errors.New("mock error"))
 
@@ -403,7 +457,7 @@ func TestDeployPreRunForBare(t *testing.T) {
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.APISIX).Return(_apisixStartupConfigTpl, nil)
 
@@ -454,6 +508,7 @@ etcd:
 
 			defer func() {
 				os.RemoveAll(filepath.Join(persistence.HomeDir, "tls"))
+				os.RemoveAll(filepath.Join(persistence.HomeDir, "modules"))
 			}()
 			ctx := &deployContext{}
 			tc.mockFn(t)
@@ -563,7 +618,7 @@ etcd:
 					PrivateKey:    "1",
 					CACertificate: "1",
 				}, nil)
-				mockClient.EXPECT().GetCloudLuaModule().Return(nil, errors.New("mock error"))
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(nil, errors.New("mock error"))
 				cloud.DefaultClient = mockClient
 			},
 		},
@@ -585,7 +640,7 @@ etcd:
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.HELM).Return("", errors.New("mock error"))
 
@@ -609,7 +664,7 @@ etcd:
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.HELM).Return(_helmStartupConfigTpl, nil)
 				cloud.DefaultClient = mockClient
@@ -649,7 +704,7 @@ etcd:
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.HELM).Return(_helmStartupConfigTpl, nil)
 				cloud.DefaultClient = mockClient
@@ -698,7 +753,7 @@ etcd:
 					CACertificate: "1",
 				}, nil)
 
-				mockClient.EXPECT().GetCloudLuaModule().Return(mockCloudModule(t), nil)
+				mockClient.EXPECT().GetCloudLuaModule(gomock.Any()).Return(&cloud.CloudLuaModule{Data: mockCloudModule(t)}, nil)
 
 				mockClient.EXPECT().GetStartupConfig(sdk.ID(3), cloud.HELM).Return(_helmStartupConfigTpl, nil)
 				cloud.DefaultClient = mockClient
@@ -739,6 +794,7 @@ etcd:
 
 			defer func() {
 				os.RemoveAll(filepath.Join(persistence.HomeDir, "tls"))
+				os.RemoveAll(filepath.Join(persistence.HomeDir, "modules"))
 			}()
 
 			ctx := &deployContext{}
@@ -768,7 +824,7 @@ func TestGetDockerContainerIDbyName(t *testing.T) {
 			mockFn: func(t *testing.T) commands.Cmd {
 				ctrl := gomock.NewController(t)
 				cmd := commands.NewMockCmd(ctrl)
-				cmd.EXPECT().AppendArgs("ps", "--filter", "name=apisix", "--format", "{{.ID}}")
+				cmd.EXPECT().AppendArgs("ps", "--filter", "name=^apisix$", "--format", "{{.ID}}")
 				cmd.EXPECT().Run(gomock.Any()).Return("", "", errors.New("mock error"))
 				return cmd
 			},
@@ -779,7 +835,7 @@ func TestGetDockerContainerIDbyName(t *testing.T) {
 			mockFn: func(t *testing.T) commands.Cmd {
 				ctrl := gomock.NewController(t)
 				cmd := commands.NewMockCmd(ctrl)
-				cmd.EXPECT().AppendArgs("ps", "--filter", "name=apisix", "--format", "{{.ID}}")
+				cmd.EXPECT().AppendArgs("ps", "--filter", "name=^apisix$", "--format", "{{.ID}}")
 				cmd.EXPECT().Run(gomock.Any()).Return("", "stderr", nil)
 				return cmd
 			},
@@ -790,7 +846,7 @@ func TestGetDockerContainerIDbyName(t *testing.T) {
 			mockFn: func(t *testing.T) commands.Cmd {
 				ctrl := gomock.NewController(t)
 				cmd := commands.NewMockCmd(ctrl)
-				cmd.EXPECT().AppendArgs("ps", "--filter", "name=apisix", "--format", "{{.ID}}")
+				cmd.EXPECT().AppendArgs("ps", "--filter", "name=^apisix$", "--format", "{{.ID}}")
 				cmd.EXPECT().Run(gomock.Any()).Return("2b68d1dcfe34", "", nil)
 				return cmd
 			},
@@ -812,3 +868,149 @@ func TestGetDockerContainerIDbyName(t *testing.T) {
 		})
 	}
 }
+
+func TestCloudModuleFiles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		files       map[string]int
+		keys        []string
+		errorReason string
+	}{
+		{
+			name: "discover all files",
+			files: map[string]int{
+				"cloud.ljbc":                   1,
+				"cloud/agent.ljbc":             1,
+				"cloud/new_feature.ljbc":       1,
+				"apisix/core/config_etcd.ljbc": 1,
+			},
+			keys: []string{"apisix-core-config-etcd.ljbc", "cloud-agent.ljbc", "cloud-new-feature.ljbc", "cloud.ljbc"},
+		},
+		{
+			name: "conflict keys",
+			files: map[string]int{
+				"cloud/new_feature.ljbc": 1,
+				"cloud/new/feature.ljbc": 1,
+			},
+			errorReason: "files cloud/new/feature.ljbc and cloud/new_feature.ljbc have the same configmap key cloud-new-feature.ljbc",
+		},
+		{
+			name: "invalid key",
+			files: map[string]int{
+				"cloud/agent@v2.ljbc": 1,
+			},
+			errorReason: "invalid configmap key cloud-agent@v2.ljbc for file cloud/agent@v2.ljbc",
+		},
+		{
+			name: "exceed size limit",
+			files: map[string]int{
+				"cloud.ljbc":       512 * 1024,
+				"cloud/agent.ljbc": 512 * 1024,
+			},
+			errorReason: "the cloud lua module (1398130 bytes) exceeds the configmap size limit (1048576 bytes)",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, size := range tc.files {
+				filename := filepath.Join(dir, file)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755), "create dir")
+				assert.NoError(t, os.WriteFile(filename, bytes.Repeat([]byte("a"), size), 0644), "write file")
+			}
+
+			files, err := cloudModuleFiles(dir)
+			if tc.errorReason != "" {
+				assert.Contains(t, err.Error(), tc.errorReason, "check the error")
+				return
+			}
+			assert.NoError(t, err, "walk cloud lua module")
+			var keys []string
+			for _, file := range files {
+				keys = append(keys, file.Key)
+			}
+			assert.Equal(t, tc.keys, keys, "check configmap keys")
+		})
+	}
+}
+
+func TestInjectCloudModuleMounts(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"cloud.ljbc", "cloud/agent.ljbc", "cloud/new_feature.ljbc"} {
+		filename := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755), "create dir")
+		assert.NoError(t, os.WriteFile(filename, []byte(file), 0644), "write file")
+	}
+	ctx := &deployContext{
+		cloudLuaModuleDir: dir,
+	}
+
+	testCases := []struct {
+		name   string
+		values map[string]interface{}
+		mounts interface{}
+	}{
+		{
+			name: "lua module hook disabled",
+			values: map[string]interface{}{
+				"apisix": map[string]interface{}{
+					"luaModuleHook": map[string]interface{}{
+						"enabled": false,
+					},
+				},
+			},
+		},
+		{
+			name: "keep existing mounts",
+			values: map[string]interface{}{
+				"apisix": map[string]interface{}{
+					"luaModuleHook": map[string]interface{}{
+						"enabled": true,
+						"luaPath": "/hook/?.ljbc",
+						"configMapRef": map[string]interface{}{
+							"name": "cloud-module",
+							"mounts": []interface{}{
+								map[string]interface{}{"key": "cloud.ljbc", "path": "/hook/cloud.ljbc"},
+							},
+						},
+					},
+				},
+			},
+			mounts: []interface{}{
+				map[string]interface{}{"key": "cloud.ljbc", "path": "/hook/cloud.ljbc"},
+				map[string]interface{}{"key": "cloud-agent.ljbc", "path": "/hook/cloud/agent.ljbc"},
+				map[string]interface{}{"key": "cloud-new-feature.ljbc", "path": "/hook/cloud/new_feature.ljbc"},
+			},
+		},
+		{
+			name: "default lua path",
+			values: map[string]interface{}{
+				"apisix": map[string]interface{}{
+					"luaModuleHook": map[string]interface{}{
+						"enabled": true,
+					},
+				},
+			},
+			mounts: []interface{}{
+				map[string]interface{}{"key": "cloud-agent.ljbc", "path": "/lua-module-hook/cloud/agent.ljbc"},
+				map[string]interface{}{"key": "cloud-new-feature.ljbc", "path": "/lua-module-hook/cloud/new_feature.ljbc"},
+				map[string]interface{}{"key": "cloud.ljbc", "path": "/lua-module-hook/cloud.ljbc"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, injectCloudModuleMounts(ctx, tc.values), "inject mounts")
+			hook := tc.values["apisix"].(map[string]interface{})["luaModuleHook"].(map[string]interface{})
+			if tc.mounts == nil {
+				assert.NotContains(t, hook, "configMapRef", "check config map ref")
+				return
+			}
+			assert.Equal(t, tc.mounts, hook["configMapRef"].(map[string]interface{})["mounts"], "check mounts")
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/manifest"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+const (
+	// _exitCodeDifferent is the exit code when differences are found,
+	// errors exit with a different code so that they can be told apart.
+	_exitCodeDifferent = 1
+)
+
+// NewCommand creates the diff sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff -f FILENAME",
+		Short: "Show differences between the local manifests and the resources on API7 Cloud",
+		Long: `Show differences between the local manifests and the resources on API7 Cloud.
+Server-managed fields (IDs, timestamps and status) are ignored, and fields
+which are not specified in the manifests keep the live values. The exit code
+is 0 if there are no differences, 1 if differences are found, and others if
+errors occur.`,
+		Example: `
+cloud-cli diff -f resources.yaml`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.CheckConfigurationAndInitCloudClient(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Diff.Validate(); err != nil {
+				output.Errorf(err.Error())
+			}
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			objects, err := manifest.Load(options.Global.Diff.Filenames...)
+			if err != nil {
+				output.Errorf(err.Error())
+			}
+			manifest.SortByDependency(objects)
+
+			cluster, err := cloud.Client().GetDefaultCluster()
+			if err != nil {
+				output.Errorf("Failed to get default cluster: %s", err)
+			}
+
+			different := false
+			for _, obj := range objects {
+				text, err := diff(cloud.Client(), cluster.ID, obj)
+				if err != nil {
+					output.Errorf("%s (%s): %s", obj, obj.Source, err)
+				}
+				if text != "" {
+					different = true
+					printDiff(text)
+				}
+			}
+			if different {
+				os.Exit(_exitCodeDifferent)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Profile, "profile", "", "The profile of the configuration to use")
+	cmd.Flags().StringSliceVarP(&options.Global.Diff.Filenames, "filename", "f", nil, "The manifest files or directories which contain the resources, \"-\" means the standard input")
+
+	return cmd
+}
+
+func diff(api cloud.API, clusterID sdk.ID, obj *manifest.Object) (string, error) {
+	var live interface{}
+	if err := manifest.ResolveServiceID(api, clusterID, obj); err != nil {
+		// The service doesn't exist yet, so does the route.
+		if obj.Route.ID != 0 || !errors.Is(err, manifest.ErrServiceNotFound) {
+			return "", err
+		}
+	} else if live, err = manifest.FindLive(api, clusterID, obj); err != nil {
+		return "", err
+	}
+	return manifest.Diff(obj, live)
+}
+
+func printDiff(text string) {
+	for _, line := range strings.SplitAfter(text, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			fmt.Print(color.New(color.Bold).Sprint(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Print(color.CyanString(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Print(color.RedString(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Print(color.GreenString(line))
+		default:
+			fmt.Print(line)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
+)
+
+const _manifest = `
+kind: route
+name: list-users
+service: users
+methods: [GET]
+---
+kind: service
+name: users
+path_prefix: /v2
+`
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mockCloud func(api *cloud.MockAPI)
+		exitCode  int
+		outputs   []string
+	}{
+		{
+			name: "differences found",
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{
+						ID:              2,
+						ApplicationSpec: sdk.ApplicationSpec{Name: "users", PathPrefix: "/v1"},
+					},
+				)).Times(2)
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).Return(nil)
+			},
+			exitCode: 1,
+			outputs: []string{
+				"--- live/service/users\n+++ local/service/users\n",
+				"-path_prefix: /v1\n+path_prefix: /v2\n",
+				"--- live/route/list-users\n+++ local/route/list-users\n@@ -0,0 +1,3 @@\n+methods:\n+    - GET\n+name: list-users\n",
+			},
+		},
+		{
+			name: "no differences",
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{
+						ID:              2,
+						ApplicationSpec: sdk.ApplicationSpec{Name: "users", PathPrefix: "/v2"},
+					},
+				)).Times(2)
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{
+						ID:      3,
+						AppID:   2,
+						APISpec: sdk.APISpec{Name: "list-users", Methods: []string{"GET"}},
+					},
+				))
+			},
+			exitCode: 0,
+		},
+		{
+			name: "failed to list services",
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(fmt.Errorf("mock error"))
+			},
+			exitCode: 255,
+			outputs: []string{
+				"ERROR: service/users",
+				"failed to list services: mock error",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := persistence.SaveConfiguration(&persistence.CloudConfiguration{
+				DefaultProfile: "default",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "default",
+						Address: "https://console.api7.cloud",
+						User: persistence.User{
+							AccessToken: "test-token",
+						},
+					},
+				},
+			})
+			assert.NoError(t, err, "prepare fake cloud configuration")
+
+			filename := filepath.Join(os.TempDir(), "diff-manifest.yaml")
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				tc.mockCloud(api)
+				cloud.NewClient = func(_ string, _ string, _ bool) (cloud.API, error) {
+					return api, nil
+				}
+				cloud.DefaultClient = api
+
+				cmd := NewCommand()
+				cmd.SetArgs([]string{"-f", filename})
+				_ = cmd.Execute()
+				return
+			}
+
+			err = os.WriteFile(filename, []byte(_manifest), 0600)
+			assert.NoError(t, err, "write manifest")
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			assert.Equal(t, tc.exitCode, cmd.ProcessState.ExitCode(), "check exit code")
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/manifest"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+// NewCommand creates the export sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the services, routes, consumers and SSL objects of the cluster",
+		Long: `Export the services, routes, consumers and SSL objects of the cluster.
+Objects are written in the manifest format, one file per object, so they
+can be applied or imported again. API7 Cloud never returns the SSL
+certificates and private keys, so they are redacted in the exported files.`,
+		Example: `
+cloud-cli export --directory ./backup
+cloud-cli export --archive backup.tar.gz`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.CheckConfigurationAndInitCloudClient(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Export.Validate(); err != nil {
+				output.Errorf(err.Error())
+			}
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := options.Global.Export
+
+			cluster, err := cloud.Client().GetDefaultCluster()
+			if err != nil {
+				output.Errorf("Failed to get default cluster: %s", err)
+			}
+			docs, err := manifest.Export(cloud.Client(), cluster.ID)
+			if err != nil {
+				output.Errorf("Failed to export cluster %s: %s", cluster.Name, err)
+			}
+
+			target := opts.Directory
+			if opts.Archive != "" {
+				target = opts.Archive
+				err = manifest.WriteArchive(opts.Archive, docs)
+			} else {
+				err = manifest.WriteDirectory(opts.Directory, docs)
+			}
+			if err != nil {
+				output.Errorf(err.Error())
+			}
+
+			counts := make(map[string]int)
+			for _, doc := range docs {
+				counts[doc.Kind]++
+			}
+			fmt.Printf("Exported %d services, %d routes, %d consumers and %d ssl objects to %s\n",
+				counts[manifest.KindService], counts[manifest.KindRoute], counts[manifest.KindConsumer], counts[manifest.KindSSL], target)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Profile, "profile", "", "The profile of the configuration to use")
+	cmd.Flags().StringVarP(&options.Global.Export.Directory, "directory", "d", "", "The directory to write the objects, one file per object")
+	cmd.Flags().StringVar(&options.Global.Export.Archive, "archive", "", "The tar.gz file to write the objects")
+
+	return cmd
+}
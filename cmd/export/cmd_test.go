@@ -0,0 +1,129 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
+)
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-export-test")
+
+	testCases := []struct {
+		name      string
+		args      []string
+		mockCloud func(api *cloud.MockAPI)
+		outputs   []string
+		files     []string
+	}{
+		{
+			name: "export to directory",
+			args: []string{"--directory", dir},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 2, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+				))
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 3, AppID: 2, APISpec: sdk.APISpec{Name: "list-users"}},
+				))
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).Return(nil)
+				api.EXPECT().IterateSSL(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.CertificateDetails{ID: 5, SNIs: []string{"a.com"}},
+				))
+			},
+			outputs: []string{"Exported 1 services, 1 routes, 0 consumers and 1 ssl objects to " + dir},
+			files:   []string{"services/2.yaml", "routes/3.yaml", "ssl/5.yaml"},
+		},
+		{
+			name: "failed to list services",
+			args: []string{"--archive", filepath.Join(dir, "backup.tar.gz")},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1, Name: "default"}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(fmt.Errorf("mock error"))
+			},
+			outputs: []string{"ERROR: Failed to export cluster default: failed to list services: mock error"},
+		},
+		{
+			name:    "missing target",
+			outputs: []string{"ERROR: either --directory or --archive is required"},
+		},
+		{
+			name:    "both targets",
+			args:    []string{"--directory", dir, "--archive", "backup.tar.gz"},
+			outputs: []string{"ERROR: --directory and --archive are mutually exclusive"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := persistence.SaveConfiguration(&persistence.CloudConfiguration{
+				DefaultProfile: "default",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "default",
+						Address: "https://console.api7.cloud",
+						User: persistence.User{
+							AccessToken: "test-token",
+						},
+					},
+				},
+			})
+			assert.NoError(t, err, "prepare fake cloud configuration")
+
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				if tc.mockCloud != nil {
+					tc.mockCloud(api)
+				}
+				cloud.NewClient = func(_ string, _ string, _ bool) (cloud.API, error) {
+					return api, nil
+				}
+				cloud.DefaultClient = api
+
+				cmd := NewCommand()
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			defer os.RemoveAll(dir)
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+			for _, file := range tc.files {
+				assert.FileExists(t, filepath.Join(dir, file), "check exported file")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/manifest"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+// NewCommand creates the import sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import -f FILENAME",
+		Short: "Import the objects exported by the export command to the cluster",
+		Long: `Import the objects exported by the export command to the cluster.
+Services are imported first, then routes, consumers and SSL objects. Objects
+are matched with the existing ones by name (by SNIs for SSL), and routes are
+moved to the services with the new IDs.`,
+		Example: `
+cloud-cli import -f ./backup
+cloud-cli import -f backup.tar.gz --on-conflict overwrite --dry-run`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.CheckConfigurationAndInitCloudClient(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Import.Validate(); err != nil {
+				output.Errorf(err.Error())
+			}
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			objects, err := manifest.Load(options.Global.Import.Filenames...)
+			if err != nil {
+				output.Errorf(err.Error())
+			}
+			if len(objects) == 0 {
+				output.Errorf("no objects found in the snapshot")
+			}
+			manifest.SortByDependency(objects)
+
+			cluster, err := cloud.Client().GetDefaultCluster()
+			if err != nil {
+				output.Errorf("Failed to get default cluster: %s", err)
+			}
+
+			imp := newImporter(cloud.Client(), cluster.ID, options.Global.Import.OnConflict)
+			steps := imp.plan(objects)
+
+			if options.Global.Import.OnConflict == options.OnConflictFail {
+				var conflicts []string
+				for _, s := range steps {
+					if s.conflict {
+						conflicts = append(conflicts, s.obj.String())
+					}
+				}
+				if len(conflicts) > 0 {
+					output.Errorf("Objects already exist: %s", strings.Join(conflicts, ", "))
+				}
+			}
+
+			if !options.Global.DryRun {
+				imp.execute(steps)
+			}
+
+			counts := make(map[string]int)
+			for _, s := range steps {
+				if s.err != nil {
+					counts["failed"]++
+					output.Warnf("%s (%s): %s", s.obj, s.obj.Source, s.err)
+					continue
+				}
+				counts[s.action]++
+				if options.Global.DryRun {
+					fmt.Printf("%s %s (dry run)\n", s.obj, s.action)
+				} else {
+					fmt.Printf("%s %s\n", s.obj, s.action)
+				}
+			}
+			fmt.Printf("%d created, %d overwritten, %d skipped, %d failed\n",
+				counts[_actionCreated], counts[_actionOverwritten], counts[_actionSkipped], counts["failed"])
+			if counts["failed"] > 0 {
+				output.Errorf("%d of %d objects failed to import", counts["failed"], len(steps))
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Profile, "profile", "", "The profile of the configuration to use")
+	cmd.Flags().StringSliceVarP(&options.Global.Import.Filenames, "filename", "f", nil, "The exported directories, tar.gz archives or manifest files")
+	cmd.Flags().StringVar(&options.Global.Import.OnConflict, "on-conflict", options.OnConflictFail, "How to handle the objects which already exist, optional values are skip, overwrite and fail")
+
+	return cmd
+}
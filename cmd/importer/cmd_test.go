@@ -0,0 +1,185 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
+)
+
+var (
+	_snapshot = map[string]string{
+		"services/10.yaml":  "kind: service\nid: \"10\"\nname: users\npath_prefix: /v1\n",
+		"routes/11.yaml":    "kind: route\nid: \"11\"\napp_id: \"10\"\nname: list-users\nmethods: [GET]\n",
+		"consumers/12.yaml": "kind: consumer\nid: \"12\"\nname: jack\n",
+		"ssl/13.yaml":       "kind: ssl\nid: \"13\"\ncertificate: <redacted>\nprivate_key: <redacted>\nsnis: [a.com]\n",
+	}
+)
+
+func TestImport(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-import-test")
+
+	// mockLookup mocks the lookups of the snapshot objects, the service
+	// doesn't exist while the consumer and the SSL object exist.
+	mockLookup := func(api *cloud.MockAPI) {
+		api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+		api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).Return(nil)
+		api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+			&sdk.Consumer{ID: 22, Name: "jack"},
+		))
+		api.EXPECT().IterateSSL(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+			&sdk.CertificateDetails{ID: 23, SNIs: []string{"a.com"}},
+		))
+	}
+
+	testCases := []struct {
+		name      string
+		args      []string
+		mockCloud func(api *cloud.MockAPI)
+		outputs   []string
+	}{
+		{
+			name: "skip conflicts",
+			args: []string{"-f", dir, "--on-conflict", "skip"},
+			mockCloud: func(api *cloud.MockAPI) {
+				mockLookup(api)
+				api.EXPECT().CreateService(sdk.ID(1), gomock.Any()).Return(&sdk.Application{ID: 20}, nil)
+				api.EXPECT().CreateRoute(sdk.ID(1), gomock.Any()).DoAndReturn(func(_ sdk.ID, route *sdk.API) (*sdk.API, error) {
+					if route.AppID != 20 {
+						return nil, fmt.Errorf("unexpected app id %s", route.AppID)
+					}
+					return &sdk.API{ID: 21, AppID: 20}, nil
+				})
+			},
+			outputs: []string{
+				"service/users created\n",
+				"route/list-users created\n",
+				"consumer/jack skipped\n",
+				"ssl/23 skipped\n",
+				"2 created, 0 overwritten, 2 skipped, 0 failed\n",
+			},
+		},
+		{
+			name: "overwrite conflicts",
+			args: []string{"-f", dir, "--on-conflict", "overwrite"},
+			mockCloud: func(api *cloud.MockAPI) {
+				mockLookup(api)
+				api.EXPECT().CreateService(sdk.ID(1), gomock.Any()).Return(&sdk.Application{ID: 20}, nil)
+				api.EXPECT().CreateRoute(sdk.ID(1), gomock.Any()).Return(&sdk.API{ID: 21, AppID: 20}, nil)
+				api.EXPECT().UpdateConsumer(sdk.ID(1), gomock.Any()).DoAndReturn(func(_ sdk.ID, consumer *sdk.Consumer) (*sdk.Consumer, error) {
+					if consumer.ID != 22 {
+						return nil, fmt.Errorf("unexpected consumer id %s", consumer.ID)
+					}
+					return consumer, nil
+				})
+			},
+			outputs: []string{
+				"consumer/jack overwritten\n",
+				"WARNING: ssl/23",
+				"certificate and private key are redacted",
+				"2 created, 1 overwritten, 0 skipped, 1 failed\n",
+				"ERROR: 1 of 4 objects failed to import",
+			},
+		},
+		{
+			name:      "fail on conflicts",
+			args:      []string{"-f", dir},
+			mockCloud: mockLookup,
+			outputs: []string{
+				"ERROR: Objects already exist: consumer/jack, ssl/23",
+			},
+		},
+		{
+			name: "dry run",
+			args: []string{"-f", dir, "--on-conflict", "skip", "--dry-run"},
+			mockCloud: func(api *cloud.MockAPI) {
+				mockLookup(api)
+			},
+			outputs: []string{
+				"service/users created (dry run)\n",
+				"route/list-users created (dry run)\n",
+				"consumer/jack skipped (dry run)\n",
+			},
+		},
+		{
+			name:    "invalid on-conflict",
+			args:    []string{"-f", dir, "--on-conflict", "ignore"},
+			outputs: []string{`ERROR: invalid --on-conflict value "ignore"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := persistence.SaveConfiguration(&persistence.CloudConfiguration{
+				DefaultProfile: "default",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "default",
+						Address: "https://console.api7.cloud",
+						User: persistence.User{
+							AccessToken: "test-token",
+						},
+					},
+				},
+			})
+			assert.NoError(t, err, "prepare fake cloud configuration")
+
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				if tc.mockCloud != nil {
+					tc.mockCloud(api)
+				}
+				cloud.NewClient = func(_ string, _ string, _ bool) (cloud.API, error) {
+					return api, nil
+				}
+				cloud.DefaultClient = api
+
+				cmd := NewCommand()
+				cmd.PersistentFlags().BoolVar(&options.Global.DryRun, "dry-run", false, "")
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			for name, content := range _snapshot {
+				filename := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755), "create snapshot directory")
+				assert.NoError(t, os.WriteFile(filename, []byte(content), 0600), "write snapshot")
+			}
+			defer os.RemoveAll(dir)
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/manifest"
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+const (
+	_actionCreated     = "created"
+	_actionOverwritten = "overwritten"
+	_actionSkipped     = "skipped"
+)
+
+// step is the import plan of an object.
+type step struct {
+	obj *manifest.Object
+	// oldID is the object ID in the snapshot.
+	oldID sdk.ID
+	// oldAppID is the service ID of the route in the snapshot.
+	oldAppID sdk.ID
+	// pendingService indicates the route belongs to a service which
+	// will be created, so its service ID is known after the creation.
+	pendingService bool
+	// conflict indicates the object already exists in the cluster.
+	conflict bool
+	action   string
+	err      error
+}
+
+type importer struct {
+	api        cloud.API
+	clusterID  sdk.ID
+	onConflict string
+	// serviceIDs maps the service IDs in the snapshot to the ones in the
+	// cluster, zero means the service will be created.
+	serviceIDs map[sdk.ID]sdk.ID
+	// pendingServices are names of the services which will be created.
+	pendingServices map[string]struct{}
+	// failedServices are IDs of the services which cannot be imported.
+	failedServices map[sdk.ID]struct{}
+}
+
+func newImporter(api cloud.API, clusterID sdk.ID, onConflict string) *importer {
+	return &importer{
+		api:             api,
+		clusterID:       clusterID,
+		onConflict:      onConflict,
+		serviceIDs:      make(map[sdk.ID]sdk.ID),
+		pendingServices: make(map[string]struct{}),
+		failedServices:  make(map[sdk.ID]struct{}),
+	}
+}
+
+// plan decides the action of each object, nothing is changed in the cluster.
+func (imp *importer) plan(objects []*manifest.Object) []*step {
+	steps := make([]*step, 0, len(objects))
+	for _, obj := range objects {
+		s := &step{
+			obj:   obj,
+			oldID: obj.ID(),
+		}
+		steps = append(steps, s)
+		// IDs in the snapshot may not be valid in the cluster,
+		// objects are matched by name instead.
+		obj.SetID(0)
+
+		s.err = imp.planStep(s)
+		if s.err != nil && obj.Kind == manifest.KindService {
+			imp.failedServices[s.oldID] = struct{}{}
+		}
+	}
+	return steps
+}
+
+func (imp *importer) planStep(s *step) error {
+	obj := s.obj
+	if obj.Kind == manifest.KindRoute {
+		if err := imp.remapService(s); err != nil {
+			return err
+		}
+		if s.pendingService {
+			s.action = _actionCreated
+			return nil
+		}
+	}
+
+	live, err := manifest.FindLive(imp.api, imp.clusterID, obj)
+	if err != nil {
+		return err
+	}
+	if live == nil {
+		s.action = _actionCreated
+	} else {
+		s.conflict = true
+		if imp.onConflict == options.OnConflictOverwrite {
+			s.action = _actionOverwritten
+		} else {
+			s.action = _actionSkipped
+		}
+	}
+	if s.action != _actionSkipped && obj.Redacted() {
+		return errors.Wrap(manifest.ErrRedacted, "please fill them before importing")
+	}
+
+	if obj.Kind == manifest.KindService {
+		imp.serviceIDs[s.oldID] = obj.ID()
+		if s.action == _actionCreated {
+			imp.pendingServices[obj.Service.Name] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// execute applies the plan to the cluster, the routes are moved to
+// the services created during the execution.
+func (imp *importer) execute(steps []*step) {
+	for _, s := range steps {
+		if s.err != nil || s.action == _actionSkipped {
+			continue
+		}
+		obj := s.obj
+		if s.pendingService {
+			if s.err = imp.resolvePendingService(s); s.err != nil {
+				continue
+			}
+		}
+
+		switch s.action {
+		case _actionCreated:
+			_, s.err = manifest.Create(imp.api, imp.clusterID, obj)
+		case _actionOverwritten:
+			_, s.err = manifest.Update(imp.api, imp.clusterID, obj)
+		}
+		if s.err == nil && obj.Kind == manifest.KindService {
+			imp.serviceIDs[s.oldID] = obj.ID()
+		}
+	}
+}
+
+// remapService finds the service of the route in the cluster, the route
+// refers to the service either by the ID in the snapshot or by name.
+func (imp *importer) remapService(s *step) error {
+	route := s.obj.Route
+	if s.obj.ServiceName != "" {
+		if _, ok := imp.pendingServices[s.obj.ServiceName]; ok {
+			s.pendingService = true
+			return nil
+		}
+		route.AppID = 0
+		return manifest.ResolveServiceID(imp.api, imp.clusterID, s.obj)
+	}
+
+	s.oldAppID = route.AppID
+	if _, failed := imp.failedServices[s.oldAppID]; failed {
+		return errors.Errorf("service %s cannot be imported", s.oldAppID)
+	}
+	id, ok := imp.serviceIDs[s.oldAppID]
+	if !ok {
+		return errors.Errorf("service %s is not in the snapshot", s.oldAppID)
+	}
+	if id == 0 {
+		s.pendingService = true
+		return nil
+	}
+	route.AppID = id
+	return nil
+}
+
+func (imp *importer) resolvePendingService(s *step) error {
+	if s.obj.ServiceName != "" {
+		s.obj.Route.AppID = 0
+		return manifest.ResolveServiceID(imp.api, imp.clusterID, s.obj)
+	}
+	id := imp.serviceIDs[s.oldAppID]
+	if id == 0 {
+		return errors.Errorf("service %s was not imported", s.oldAppID)
+	}
+	s.obj.Route.AppID = id
+	return nil
+}
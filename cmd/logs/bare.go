@@ -0,0 +1,147 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+const (
+	// _errorLogTimeLayout is the time layout at the beginning of the error
+	// log lines, e.g. 2023/04/26 08:25:34.
+	_errorLogTimeLayout = "2006/01/02 15:04:05"
+	// _accessLogTimeLayout is the time layout of $time_local in the access
+	// log lines, e.g. [26/Apr/2023:08:25:34 +0000].
+	_accessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+var (
+	// _apisixLogDir is the logs directory of APISIX deployed on bare metal.
+	_apisixLogDir = "/usr/local/apisix/logs"
+)
+
+func newBareCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bare",
+		Short: "Show the logs of Apache APISIX on bare metal (only CentOS 7)",
+		Long: `Show the logs of Apache APISIX on bare metal.
+Both the access.log and the error.log are shown, each line is prefixed with
+the log type.`,
+		Example: `
+cloud-cli logs bare \
+		--since 1h \
+		--grep "upstream timed out"`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			var sources []logSource
+			for _, log := range []struct {
+				name      string
+				timestamp func(line []byte) (time.Time, bool)
+			}{
+				{name: "access", timestamp: accessLogTime},
+				{name: "error", timestamp: errorLogTime},
+			} {
+				sources = append(sources, logSource{
+					prefix: "[" + log.name + "] ",
+					cmd:    tailCommand(filepath.Join(_apisixLogDir, log.name+".log")),
+					match:  bareFilter(log.timestamp),
+				})
+			}
+
+			if err := streamLogs(ctx, sources); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+	}
+
+	return cmd
+}
+
+func tailCommand(filename string) commands.Cmd {
+	opts := options.Global.Logs
+	tail := commands.New("tail", options.Global.DryRun)
+	if opts.Tail >= 0 {
+		tail.AppendArgs("-n", strconv.Itoa(opts.Tail))
+	} else {
+		tail.AppendArgs("-n", "+1")
+	}
+	if opts.Follow {
+		// Follow the file name so that the rotated logs are also followed.
+		tail.AppendArgs("-F")
+	}
+	tail.AppendArgs(filename)
+	return tail
+}
+
+// bareFilter returns the filter for the --grep and the --since options, the
+// log files don't support the --since option, so the time of the lines are
+// compared.
+func bareFilter(timestamp func(line []byte) (time.Time, bool)) func(line []byte) bool {
+	grep := grepFilter()
+	if options.Global.Logs.Since <= 0 {
+		return grep
+	}
+	since := time.Now().Add(-options.Global.Logs.Since)
+	// The lines without time, e.g. the stack traceback of the errors, are
+	// decided by the preceding line.
+	var newer bool
+	return func(line []byte) bool {
+		if t, ok := timestamp(line); ok {
+			newer = !t.Before(since)
+		}
+		if !newer {
+			return false
+		}
+		return grep == nil || grep(line)
+	}
+}
+
+func errorLogTime(line []byte) (time.Time, bool) {
+	if len(line) < len(_errorLogTimeLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(_errorLogTimeLayout, string(line[:len(_errorLogTimeLayout)]), time.Local)
+	return t, err == nil
+}
+
+func accessLogTime(line []byte) (time.Time, bool) {
+	start := bytes.IndexByte(line, '[')
+	if start < 0 {
+		return time.Time{}, false
+	}
+	end := bytes.IndexByte(line[start:], ']')
+	if end < 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(_accessLogTimeLayout, string(line[start+1:start+end]))
+	return t, err == nil
+}
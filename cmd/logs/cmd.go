@@ -0,0 +1,47 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+// NewCommand creates the logs sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [COMMAND] [ARG...]",
+		Short: "Show the access and error logs of Apache APISIX.",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Logs.Validate(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Name, "name", "apisix", "The identifier of the deployment, it would be the container name (on Docker), the helm release (on Kubernetes) and it's useless if APISIX is deployed on bare metal")
+	cmd.PersistentFlags().BoolVarP(&options.Global.Logs.Follow, "follow", "f", false, "Stream the new logs until it's interrupted")
+	cmd.PersistentFlags().DurationVar(&options.Global.Logs.Since, "since", 0, "Only show the logs newer than the relative duration, e.g. 10m, 1h")
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Grep, "grep", "", "Only show the log lines which match the regular expression")
+	cmd.PersistentFlags().IntVar(&options.Global.Logs.Tail, "tail", -1, "The number of lines to show from the end of the logs, all the lines are shown if it's negative")
+
+	cmd.AddCommand(newDockerCommand())
+	cmd.AddCommand(newKubernetesCommand())
+	cmd.AddCommand(newBareCommand())
+
+	return cmd
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+const (
+	_fakeDocker = `#!/bin/sh
+echo "$0 $@" >> "$(dirname "$0")/commands.log"
+echo '127.0.0.1 - - [26/Apr/2023:08:25:34 +0000] 127.0.0.1:9080 "GET /anything HTTP/1.1" 200'
+echo '2023/04/26 08:25:35 [error] 44#44: *1 connect() failed' >&2
+`
+	_fakeKubectl = `#!/bin/sh
+echo "$0 $@" >> "$(dirname "$0")/commands.log"
+case "$1" in
+get) echo '"apisix-0 apisix-1"' ;;
+logs) printf "GET /anything from $2\n2023/04/26 08:25:35 [warn] $2 is warming up" ;;
+esac
+`
+)
+
+func TestLogs(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-logs-test")
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		args        []string
+		deployments []*persistence.Deployment
+		commands    []string
+		outputs     []string
+		notOutputs  []string
+		failed      bool
+	}{
+		{
+			name:     "docker",
+			args:     []string{"docker", "--docker-cli-path", filepath.Join(dir, "docker"), "--follow", "--since", "10m", "--tail", "20"},
+			commands: []string{filepath.Join(dir, "docker") + " logs --follow --since 10m0s --tail 20 apisix"},
+			outputs: []string{
+				`127.0.0.1 - - [26/Apr/2023:08:25:34 +0000] 127.0.0.1:9080 "GET /anything HTTP/1.1" 200`,
+				"2023/04/26 08:25:35 [error] 44#44: *1 connect() failed",
+			},
+		},
+		{
+			name:     "docker with grep",
+			args:     []string{"docker", "--docker-cli-path", filepath.Join(dir, "docker"), "--name", "apisix-1", "--grep", `\[error\]`},
+			commands: []string{filepath.Join(dir, "docker") + " logs apisix-1"},
+			outputs:  []string{"[error] 44#44: *1 connect() failed"},
+			notOutputs: []string{
+				"GET /anything",
+			},
+		},
+		{
+			name: "docker with the recorded runtime",
+			args: []string{"docker", "--name", "apisix-1"},
+			deployments: []*persistence.Deployment{
+				{Name: "apisix-1", Target: persistence.DeploymentDocker, Runtime: "podman"},
+			},
+			commands: []string{"podman logs apisix-1"},
+		},
+		{
+			name: "kubernetes",
+			args: []string{"kubernetes", "--kubectl-cli-path", filepath.Join(dir, "kubectl"), "--name", "my-apisix", "--namespace", "gateway", "--since", "1h"},
+			commands: []string{
+				"get pods -n gateway -l app.kubernetes.io/instance=my-apisix",
+				"logs apisix-0 -n gateway --since 1h0m0s",
+				"logs apisix-1 -n gateway --since 1h0m0s",
+			},
+			outputs: []string{
+				"[apisix-0] GET /anything from apisix-0\n",
+				"[apisix-1] GET /anything from apisix-1\n",
+				"[apisix-1] 2023/04/26 08:25:35 [warn] apisix-1 is warming up\n",
+			},
+		},
+		{
+			name: "kubernetes with grep",
+			args: []string{"kubernetes", "--kubectl-cli-path", filepath.Join(dir, "kubectl"), "--grep", "warn"},
+			outputs: []string{
+				"[apisix-0] 2023/04/26 08:25:35 [warn] apisix-0 is warming up",
+			},
+			notOutputs: []string{"GET /anything"},
+		},
+		{
+			name: "bare metal",
+			args: []string{"bare", "--since", "1h"},
+			outputs: []string{
+				"[access] 127.0.0.1 - - [" + now.Format(_accessLogTimeLayout) + "] 127.0.0.1:9080 \"GET /new HTTP/1.1\" 200",
+				"[error] " + now.Format(_errorLogTimeLayout) + " [error] 44#44: *2 lua entry thread aborted",
+				"[error] stack traceback:",
+			},
+			notOutputs: []string{"GET /old", "*1 connect() failed"},
+		},
+		{
+			name: "bare metal with tail",
+			args: []string{"bare", "--tail", "1", "--grep", "GET"},
+			outputs: []string{
+				"[access] 127.0.0.1 - - [" + now.Format(_accessLogTimeLayout) + "] 127.0.0.1:9080 \"GET /new HTTP/1.1\" 200",
+			},
+			notOutputs: []string{"GET /old", "[error]"},
+		},
+		{
+			name:    "invalid grep",
+			args:    []string{"docker", "--grep", "("},
+			outputs: []string{"invalid --grep option"},
+			failed:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			persistence.HomeDir = dir
+			_apisixLogDir = dir
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				// Use the fake commands of the other runtimes.
+				os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+				cmd := NewCommand()
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			defer os.RemoveAll(dir)
+			assert.NoError(t, persistence.Init(), "init persistence")
+			for _, deployment := range tc.deployments {
+				assert.NoError(t, persistence.SaveDeployment(deployment), "save deployment")
+			}
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(_fakeDocker), 0755), "write fake docker")
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "podman"), []byte(_fakeDocker), 0755), "write fake podman")
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "kubectl"), []byte(_fakeKubectl), 0755), "write fake kubectl")
+
+			old := now.Add(-2 * time.Hour)
+			accessLog := fmt.Sprintf("127.0.0.1 - - [%s] 127.0.0.1:9080 \"GET /old HTTP/1.1\" 200\n127.0.0.1 - - [%s] 127.0.0.1:9080 \"GET /new HTTP/1.1\" 200\n",
+				old.Format(_accessLogTimeLayout), now.Format(_accessLogTimeLayout))
+			errorLog := fmt.Sprintf("%s [error] 44#44: *1 connect() failed\n%s [error] 44#44: *2 lua entry thread aborted\nstack traceback:\n",
+				old.Format(_errorLogTimeLayout), now.Format(_errorLogTimeLayout))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "access.log"), []byte(accessLog), 0644), "write access log")
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "error.log"), []byte(errorLog), 0644), "write error log")
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, err := cmd.CombinedOutput()
+			if tc.failed {
+				assert.Error(t, err, "check if the command failed")
+			} else {
+				assert.NoError(t, err, "check if the command executed successfully")
+			}
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+			for _, out := range tc.notOutputs {
+				assert.NotContains(t, string(output), out, "check output")
+			}
+
+			if len(tc.commands) > 0 {
+				data, err := os.ReadFile(filepath.Join(dir, "commands.log"))
+				assert.NoError(t, err, "read commands log")
+				for _, command := range tc.commands {
+					assert.Contains(t, string(data), command, "check the executed commands")
+				}
+			}
+		})
+	}
+}
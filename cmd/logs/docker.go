@@ -0,0 +1,92 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/container"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+func newDockerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docker [ARG...]",
+		Short: "Show the logs of Apache APISIX on Docker",
+		Long: `Show the logs of Apache APISIX on Docker.
+The access logs are written to the stdout, and the error logs are written to
+the stderr, as what the APISIX container does.`,
+		Example: `
+cloud-cli logs docker \
+		--name apisix \
+		--follow \
+		--since 10m \
+		--grep "\[error\]"`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			opts := options.Global.Logs
+			runtime, err := persistence.DeploymentRuntime(opts.Docker.Runtime, opts.Name)
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			docker := runtime.Command(opts.Docker.DockerCLIPath, options.Global.DryRun)
+			docker.AppendArgs("logs")
+			if opts.Follow {
+				docker.AppendArgs("--follow")
+			}
+			if opts.Since > 0 {
+				docker.AppendArgs("--since", opts.Since.String())
+			}
+			if opts.Tail >= 0 {
+				docker.AppendArgs("--tail", strconv.Itoa(opts.Tail))
+			}
+			docker.AppendArgs(opts.Name)
+
+			err = streamLogs(ctx, []logSource{
+				{
+					cmd:   docker,
+					match: grepFilter(),
+				},
+			})
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+	}
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command (or the podman and nerdctl command for the corresponding runtime)")
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Docker.Runtime, "runtime", "", "Specify the container runtime, one of: "+strings.Join(container.Runtimes(), ", ")+", the one used to deploy is used by default")
+
+	return cmd
+}
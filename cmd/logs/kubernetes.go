@@ -0,0 +1,103 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+func newKubernetesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubernetes [ARG...]",
+		Short: "Show the logs of Apache APISIX on Kubernetes",
+		Long: `Show the logs of Apache APISIX on Kubernetes.
+The logs of all the pods of the release are shown, each line is prefixed
+with the pod name.`,
+		Example: `
+cloud-cli logs kubernetes \
+		--name apisix \
+		--namespace apisix \
+		--follow \
+		--tail 100`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts := &options.Global.Logs.Kubernetes
+			if opts.KubectlCLIPath == "" {
+				opts.KubectlCLIPath = "kubectl"
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			opts := options.Global.Logs
+			kubectl := commands.New(opts.Kubernetes.KubectlCLIPath, options.Global.DryRun)
+			podNames, err := utils.GetPodsNames(kubectl, opts.Name, opts.Kubernetes.Namespace)
+			if err != nil {
+				output.Errorf("Failed to get pods: %s", err)
+				return
+			}
+
+			var sources []logSource
+			for _, podName := range podNames {
+				podName = strings.TrimSpace(podName)
+				if podName == "" {
+					continue
+				}
+				kubectl := commands.New(opts.Kubernetes.KubectlCLIPath, options.Global.DryRun)
+				kubectl.AppendArgs("logs", podName, "-n", opts.Kubernetes.Namespace)
+				if opts.Follow {
+					kubectl.AppendArgs("--follow")
+				}
+				if opts.Since > 0 {
+					kubectl.AppendArgs("--since", opts.Since.String())
+				}
+				if opts.Tail >= 0 {
+					kubectl.AppendArgs("--tail", strconv.Itoa(opts.Tail))
+				}
+				sources = append(sources, logSource{
+					prefix: "[" + podName + "] ",
+					cmd:    kubectl,
+					match:  grepFilter(),
+				})
+			}
+			if len(sources) == 0 && !options.Global.DryRun {
+				output.Errorf("No pods of release %s are found in namespace %s", opts.Name, opts.Kubernetes.Namespace)
+				return
+			}
+
+			if err = streamLogs(ctx, sources); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Kubernetes.Namespace, "namespace", "apisix", "Specify the Kubernetes name space")
+	cmd.PersistentFlags().StringVar(&options.Global.Logs.Kubernetes.KubectlCLIPath, "kubectl-cli-path", "", "Specify the filepath of the kubectl command")
+
+	return cmd
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+// logSource is a command which prints the logs, e.g. "docker logs".
+type logSource struct {
+	// prefix is prepended to each log line, it identifies the source when
+	// there are multiple sources.
+	prefix string
+	cmd    commands.Cmd
+	// match filters the log lines, it's created for each source since it
+	// might be stateful.
+	match func(line []byte) bool
+}
+
+// streamLogs runs the commands of the sources concurrently, and writes the
+// log lines to the console as soon as they're produced.
+func streamLogs(ctx context.Context, sources []logSource) error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make([]error, len(sources))
+	)
+	for i, source := range sources {
+		if options.Global.DryRun {
+			output.Infof("Running:\n%s\n", source.cmd.String())
+			continue
+		}
+		output.Verbosef("Running:\n%s\n", source.cmd.String())
+
+		wg.Add(1)
+		go func(i int, source logSource) {
+			defer wg.Done()
+			stdout := &lineWriter{mu: &mu, w: os.Stdout, prefix: source.prefix, match: source.match}
+			stderr := &lineWriter{mu: &mu, w: os.Stderr, prefix: source.prefix, match: source.match}
+			errs[i] = source.cmd.Stream(ctx, stdout, stderr)
+			_ = stdout.Flush()
+			_ = stderr.Flush()
+		}(i, source)
+	}
+	wg.Wait()
+
+	// The commands are killed once the user interrupts following the logs.
+	if ctx.Err() != nil {
+		return nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineWriter writes the complete lines which match the filter to the
+// underlying writer. The writers of all the sources share the lock, so
+// that the lines from different sources are not interleaved.
+type lineWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	match  func(line []byte) bool
+	buf    []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.buf = append(lw.buf, p...)
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := lw.writeLine(lw.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		lw.buf = lw.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the last line even if it's not terminated by a newline.
+func (lw *lineWriter) Flush() error {
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	line := append(lw.buf, '\n')
+	lw.buf = nil
+	return lw.writeLine(line)
+}
+
+func (lw *lineWriter) writeLine(line []byte) error {
+	if lw.match != nil && !lw.match(line) {
+		return nil
+	}
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.prefix != "" {
+		if _, err := io.WriteString(lw.w, lw.prefix); err != nil {
+			return err
+		}
+	}
+	_, err := lw.w.Write(line)
+	return err
+}
+
+// grepFilter returns the filter for the --grep option, it's nil if the
+// option is not specified.
+func grepFilter() func(line []byte) bool {
+	if options.Global.Logs.Grep == "" {
+		return nil
+	}
+	// The expression is validated before.
+	re := regexp.MustCompile(options.Global.Logs.Grep)
+	return re.Match
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	lw := &lineWriter{
+		mu:     &sync.Mutex{},
+		w:      buf,
+		prefix: "[apisix-0] ",
+		match:  regexp.MustCompile("error").Match,
+	}
+
+	for _, p := range []string{"2023/04/26 [err", "or] first\n2023/04/26 [warn] second\n", "third error"} {
+		n, err := lw.Write([]byte(p))
+		assert.NoError(t, err, "write logs")
+		assert.Equal(t, len(p), n, "check the written bytes")
+	}
+	assert.Equal(t, "[apisix-0] 2023/04/26 [error] first\n", buf.String(), "check the complete lines")
+
+	assert.NoError(t, lw.Flush(), "flush logs")
+	assert.Equal(t, "[apisix-0] 2023/04/26 [error] first\n[apisix-0] third error\n", buf.String(), "check the flushed line")
+}
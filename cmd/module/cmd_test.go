@@ -0,0 +1,122 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+func TestModuleCommand(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-module-test")
+
+	testCases := []struct {
+		name       string
+		args       []string
+		format     string
+		bundles    []string
+		corrupted  int
+		wantErr    bool
+		outputs    []string
+		notOutputs []string
+	}{
+		{
+			name:    "list nothing",
+			args:    []string{"list"},
+			outputs: []string{"No cloud lua modules were pulled"},
+		},
+		{
+			name:    "list modules",
+			args:    []string{"list"},
+			format:  output.FormatWide,
+			bundles: []string{"bundle-1", "bundle-2"},
+			outputs: []string{"CHECKSUM", "SIZE", "VERIFIED", "SOURCE", "bundle-1.tar.gz", "bundle-2.tar.gz"},
+		},
+		{
+			name:    "verify modules",
+			args:    []string{"verify"},
+			bundles: []string{"bundle-1", "bundle-2"},
+			outputs: []string{": OK"},
+			notOutputs: []string{
+				"CORRUPTED",
+			},
+		},
+		{
+			name:      "verify corrupted modules",
+			args:      []string{"verify"},
+			bundles:   []string{"bundle-1", "bundle-2"},
+			corrupted: 1,
+			wantErr:   true,
+			outputs:   []string{": OK", ": CORRUPTED (cloud lua module", "1 cloud lua module(s) are corrupted"},
+		},
+		{
+			name:    "verify unknown module",
+			args:    []string{"verify", "unknown"},
+			bundles: []string{"bundle-1"},
+			wantErr: true,
+			outputs: []string{"No cloud lua modules were found"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			persistence.HomeDir = dir
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				options.Global.Output = tc.format
+				cmd := NewCommand()
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			defer os.RemoveAll(dir)
+			assert.NoError(t, persistence.Init(), "init persistence")
+			for i, name := range tc.bundles {
+				bundle := filepath.Join(dir, name+".tar.gz")
+				assert.NoError(t, os.WriteFile(bundle, []byte(name), 0644), "write bundle")
+				module, err := persistence.ImportCloudLuaModule(bundle, "")
+				assert.NoError(t, err, "import bundle")
+				if i < tc.corrupted {
+					assert.NoError(t, os.WriteFile(module.Path(), []byte("corrupted"), 0644), "corrupt module")
+				}
+			}
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			out, err := cmd.CombinedOutput()
+			if tc.wantErr {
+				assert.Error(t, err, "check if the command failed")
+			} else {
+				assert.NoError(t, err, "check if the command executed successfully")
+			}
+			for _, expected := range tc.outputs {
+				assert.Contains(t, string(out), expected, "check output")
+			}
+			for _, unexpected := range tc.notOutputs {
+				assert.NotContains(t, string(out), unexpected, "check output")
+			}
+		})
+	}
+}
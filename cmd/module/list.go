@@ -0,0 +1,63 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+func init() {
+	output.RegisterTableDefinition("module", output.TableDefinition{
+		Columns: []output.Column{
+			output.NewColumn("Checksum", false, func(m *persistence.CachedCloudLuaModule) string { return m.ShortChecksum() }),
+			output.NewColumn("Size", false, func(m *persistence.CachedCloudLuaModule) string { return strconv.FormatInt(m.Size, 10) }),
+			output.NewColumn("Verified", false, func(m *persistence.CachedCloudLuaModule) string { return strconv.FormatBool(m.Verified) }),
+			output.NewColumn("Pulled At", false, func(m *persistence.CachedCloudLuaModule) string { return m.PulledAt.Format(time.RFC3339) }),
+			output.NewColumn("Source", true, func(m *persistence.CachedCloudLuaModule) string { return m.Source }),
+			output.NewColumn("ETag", true, func(m *persistence.CachedCloudLuaModule) string { return m.ETag }),
+		},
+		Name: func(obj interface{}) string {
+			return obj.(*persistence.CachedCloudLuaModule).Checksum
+		},
+	})
+}
+
+func newListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cloud lua modules in the local cache, the latest pulled one is used by default",
+		Example: `cloud-cli module list
+cloud-cli module list --output wide`,
+		Run: func(cmd *cobra.Command, args []string) {
+			modules, err := persistence.ListCloudLuaModules()
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			if len(modules) == 0 {
+				output.Infof("No cloud lua modules were pulled")
+				return
+			}
+			output.PrintResource("module", modules, output.FormatTable)
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+func newPullCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Pull the cloud lua module from API7 Cloud to the local cache",
+		Example: `cloud-cli module pull
+cloud-cli module pull --checksum 0a1b2c...`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.CheckConfigurationAndInitCloudClient(); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			module, pulled, err := persistence.PullCloudLuaModule(options.Global.Module.Checksum)
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			if pulled {
+				fmt.Printf("Pulled cloud lua module %s\n", module.Checksum)
+			} else {
+				fmt.Printf("Cloud lua module %s is up to date\n", module.Checksum)
+			}
+			if !module.Verified {
+				output.Warnf("The checksum of the cloud lua module was not published, it was not verified")
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Module.Checksum, "checksum", "", "Specify the expected SHA-256 checksum of the cloud lua module")
+
+	return cmd
+}
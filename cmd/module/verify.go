@@ -0,0 +1,80 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package module
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+func newVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [CHECKSUM...]",
+		Short: "Verify the integrity of the cloud lua modules in the local cache",
+		Example: `cloud-cli module verify
+cloud-cli module verify 0a1b2c3d4e5f`,
+		Run: func(cmd *cobra.Command, args []string) {
+			modules, err := persistence.ListCloudLuaModules()
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+
+			var (
+				verified  int
+				corrupted int
+			)
+			for _, module := range modules {
+				if !matchChecksum(module.Checksum, args) {
+					continue
+				}
+				verified++
+				if err = persistence.VerifyCloudLuaModule(module); err != nil {
+					corrupted++
+					fmt.Printf("%s: CORRUPTED (%s)\n", module.ShortChecksum(), err)
+					continue
+				}
+				fmt.Printf("%s: OK\n", module.ShortChecksum())
+			}
+			if verified == 0 {
+				output.Errorf("No cloud lua modules were found")
+				return
+			}
+			if corrupted > 0 {
+				output.Errorf("%d cloud lua module(s) are corrupted, please remove them and pull again", corrupted)
+			}
+		},
+	}
+	return cmd
+}
+
+// matchChecksum checks if the checksum has one of the prefixes, it matches
+// everything if there is no prefixes.
+func matchChecksum(checksum string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(checksum, prefix) {
+			return true
+		}
+	}
+	return false
+}
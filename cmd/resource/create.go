@@ -15,8 +15,6 @@
 package resource
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
 
 	sdk "github.com/api7/cloud-go-sdk"
@@ -144,8 +142,7 @@ func newCreateCommand() *cobra.Command {
 				output.Errorf("This kind of resource is not supported")
 			} else {
 				resource := handler()
-				text, _ := json.MarshalIndent(resource, "", "\t")
-				fmt.Println(string(text))
+				output.PrintResource(kind, resource, output.FormatJSON)
 			}
 		},
 	}
@@ -15,107 +15,324 @@
 package resource
 
 import (
+	"bufio"
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/selector"
 	sdk "github.com/api7/cloud-go-sdk"
 )
 
+// deleteTarget is an object to be deleted.
+type deleteTarget struct {
+	kind      string
+	id        sdk.ID
+	serviceID sdk.ID
+	name      string
+	labels    []string
+	// routes are the routes of the service, they're deleted before the
+	// service when deleting in cascade.
+	routes []*deleteTarget
+}
+
+func (t *deleteTarget) String() string {
+	if t.name == "" {
+		return fmt.Sprintf("%s/%s", t.kind, t.id)
+	}
+	return fmt.Sprintf("%s/%s (id: %s)", t.kind, t.name, t.id)
+}
+
 var (
-	_resourceDeleteHandler = map[string]func(id sdk.ID, args ...any){
-		"ssl": func(id sdk.ID, args ...any) {
-			cluster, err := cloud.DefaultClient.GetDefaultCluster()
-			if err != nil {
-				output.Errorf("Failed to get the default cluster: %s", err.Error())
-			}
-			if err := cloud.DefaultClient.DeleteSSL(cluster.ID, id); err != nil {
-				output.Errorf("Failed to delete ssl: %s", err.Error())
-			}
+	_resourceDeleteHandler = map[string]func(clusterID sdk.ID, target *deleteTarget) error{
+		"ssl": func(clusterID sdk.ID, target *deleteTarget) error {
+			return cloud.DefaultClient.DeleteSSL(clusterID, target.id)
 		},
-		"service": func(id sdk.ID, args ...any) {
-			cluster, err := cloud.DefaultClient.GetDefaultCluster()
-			if err != nil {
-				output.Errorf("Failed to get the default cluster: %s", err.Error())
-			}
-			if err := cloud.DefaultClient.DeleteService(cluster.ID, id); err != nil {
-				output.Errorf("Failed to delete service: %s", err.Error())
-			}
+		"service": func(clusterID sdk.ID, target *deleteTarget) error {
+			return cloud.DefaultClient.DeleteService(clusterID, target.id)
 		},
-		"consumer": func(id sdk.ID, args ...any) {
-			cluster, err := cloud.DefaultClient.GetDefaultCluster()
-			if err != nil {
-				output.Errorf("Failed to get the default cluster: %s", err.Error())
-			}
-			if err := cloud.DefaultClient.DeleteConsumer(cluster.ID, id); err != nil {
-				output.Errorf("Failed to delete consumer: %s", err.Error())
-			}
+		"consumer": func(clusterID sdk.ID, target *deleteTarget) error {
+			return cloud.DefaultClient.DeleteConsumer(clusterID, target.id)
 		},
-		"route": func(id sdk.ID, args ...any) {
-			cluster, err := cloud.DefaultClient.GetDefaultCluster()
-			if err != nil {
-				output.Errorf("Failed to get the default cluster: %s", err.Error())
-			}
+		"route": func(clusterID sdk.ID, target *deleteTarget) error {
+			return cloud.DefaultClient.DeleteRoute(clusterID, target.serviceID, target.id)
+		},
+	}
 
-			if len(args) == 0 {
-				output.Errorf("Please specify the service id")
-			}
-			serviceID, ok := args[0].(sdk.ID)
-			if !ok {
-				output.Errorf("Please specify the correct service id")
+	// _resourceDeleteLister lists all the objects of the kind, it's used
+	// when deleting objects by --selector or --all.
+	_resourceDeleteLister = map[string]func(clusterID, serviceID sdk.ID) ([]*deleteTarget, error){
+		"ssl": func(clusterID, _ sdk.ID) ([]*deleteTarget, error) {
+			var targets []*deleteTarget
+			err := cloud.DefaultClient.IterateSSL(clusterID, nil, func(ssl *sdk.CertificateDetails) error {
+				targets = append(targets, &deleteTarget{
+					kind:   "ssl",
+					id:     ssl.ID,
+					name:   strings.Join(ssl.SNIs, ","),
+					labels: ssl.Labels,
+				})
+				return nil
+			})
+			return targets, err
+		},
+		"service": func(clusterID, _ sdk.ID) ([]*deleteTarget, error) {
+			var targets []*deleteTarget
+			err := cloud.DefaultClient.IterateServices(clusterID, nil, func(svc *sdk.Application) error {
+				targets = append(targets, &deleteTarget{
+					kind:   "service",
+					id:     svc.ID,
+					name:   svc.Name,
+					labels: svc.Labels,
+				})
+				return nil
+			})
+			return targets, err
+		},
+		"consumer": func(clusterID, _ sdk.ID) ([]*deleteTarget, error) {
+			var targets []*deleteTarget
+			err := cloud.DefaultClient.IterateConsumers(clusterID, nil, func(consumer *sdk.Consumer) error {
+				targets = append(targets, &deleteTarget{
+					kind:   "consumer",
+					id:     consumer.ID,
+					name:   consumer.Name,
+					labels: consumer.Labels,
+				})
+				return nil
+			})
+			return targets, err
+		},
+		"route": listRouteDeleteTargets,
+	}
+)
+
+func listRouteDeleteTargets(clusterID, serviceID sdk.ID) ([]*deleteTarget, error) {
+	var targets []*deleteTarget
+	err := cloud.DefaultClient.IterateRoutes(clusterID, serviceID, nil, func(route *sdk.API) error {
+		targets = append(targets, &deleteTarget{
+			kind:      "route",
+			id:        route.ID,
+			serviceID: serviceID,
+			name:      route.Name,
+			labels:    route.Labels,
+		})
+		return nil
+	})
+	return targets, err
+}
+
+// resolveDeleteTargets finds out the objects to delete according to the
+// --id, --selector or --all options.
+func resolveDeleteTargets(clusterID, serviceID sdk.ID, ids []sdk.ID) ([]*deleteTarget, error) {
+	opts := options.Global.Resource.Delete
+
+	var targets []*deleteTarget
+	if len(ids) > 0 || len(opts.Names) > 0 {
+		for _, id := range ids {
+			targets = append(targets, &deleteTarget{
+				kind:      opts.Kind,
+				id:        id,
+				serviceID: serviceID,
+			})
+		}
+		for _, name := range opts.Names {
+			id, err := cloud.DefaultResolver().Resolve(clusterID, opts.Kind, name, serviceID)
+			if err != nil {
+				return nil, err
 			}
-			if serviceID == 0 {
-				output.Errorf("Please specify the correct service id")
+			targets = append(targets, &deleteTarget{
+				kind:      opts.Kind,
+				id:        id,
+				serviceID: serviceID,
+				name:      name,
+			})
+		}
+	} else {
+		labelSelector, err := selector.Parse(opts.Selector)
+		if err != nil {
+			return nil, err
+		}
+		all, err := _resourceDeleteLister[opts.Kind](clusterID, serviceID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s", opts.Kind)
+		}
+		for _, target := range all {
+			if labelSelector.Matches(target.labels) {
+				targets = append(targets, target)
 			}
+		}
+	}
 
-			if err := cloud.DefaultClient.DeleteRoute(cluster.ID, serviceID, id); err != nil {
-				output.Errorf("Failed to delete route: %s", err.Error())
+	if opts.Kind == "service" && opts.Cascade {
+		for _, target := range targets {
+			routes, err := listRouteDeleteTargets(clusterID, target.id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list routes of %s", target)
 			}
-		},
+			target.routes = routes
+		}
 	}
-)
+	return targets, nil
+}
+
+// confirm asks the user to confirm the operation, only "y" and "yes" are
+// treated as the confirmation.
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		fmt.Println()
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
 
 func newDeleteCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "delete a resource",
+		Use:   "delete [KIND/NAME...]",
+		Short: "delete resources",
+		Example: `cloud-cli resource delete --kind service --id 123
+cloud-cli resource delete service/users service/orders
+cloud-cli resource delete --kind route --service-id 123 --selector team=payments
+cloud-cli resource delete --kind consumer --all --yes`,
 		PreRun: func(cmd *cobra.Command, args []string) {
+			opts := &options.Global.Resource.Delete
+			for i, arg := range args {
+				var name string
+				kind := opts.Kind
+				applyRef([]string{arg}, &opts.Kind, &name)
+				if i > 0 && opts.Kind != kind {
+					output.Errorf("All the resources should be of the same kind")
+				}
+				opts.Names = append(opts.Names, name)
+			}
+			if err := opts.Validate(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+
 			if err := persistence.Init(); err != nil {
 				output.Errorf(err.Error())
 				return
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			kind := options.Global.Resource.Delete.Kind
-			id := options.Global.Resource.Delete.ID
-			serviceID := options.Global.Resource.Delete.ServiceID
-			handler, ok := _resourceDeleteHandler[kind]
+			opts := options.Global.Resource.Delete
+			handler, ok := _resourceDeleteHandler[opts.Kind]
 			if !ok {
 				output.Errorf("This kind of resource is not supported")
-			} else {
+				return
+			}
+
+			var ids []sdk.ID
+			for _, id := range opts.IDs {
 				uint64ID, err := strconv.ParseUint(id, 10, 64)
 				if err != nil {
 					output.Errorf("Failed to parse id: %s", id)
 					return
 				}
+				ids = append(ids, sdk.ID(uint64ID))
+			}
 
-				uint64ServiceID, err := strconv.ParseUint(serviceID, 10, 64)
+			var serviceID sdk.ID
+			if opts.Service != "" {
+				serviceID = resolveServiceID("", opts.Service)
+			} else {
+				uint64ServiceID, err := strconv.ParseUint(opts.ServiceID, 10, 64)
 				if err != nil {
-					output.Errorf("Failed to parse service id: %s", serviceID)
+					output.Errorf("Failed to parse service id: %s", opts.ServiceID)
 					return
 				}
-				handler(sdk.ID(uint64ID), sdk.ID(uint64ServiceID))
+				serviceID = sdk.ID(uint64ServiceID)
+			}
+			if opts.Kind == "route" && serviceID == 0 {
+				output.Errorf("Please specify the correct service id")
+				return
+			}
+
+			cluster, err := cloud.DefaultClient.GetDefaultCluster()
+			if err != nil {
+				output.Errorf("Failed to get the default cluster: %s", err.Error())
+				return
+			}
+
+			targets, err := resolveDeleteTargets(cluster.ID, serviceID, ids)
+			if err != nil {
+				output.Errorf("Failed to find objects to delete: %s", err.Error())
+				return
+			}
+			if len(targets) == 0 {
+				fmt.Println("No objects to delete")
+				return
+			}
+
+			total := 0
+			fmt.Println("The following objects will be deleted:")
+			for _, target := range targets {
+				fmt.Printf("  %s\n", target)
+				for _, route := range target.routes {
+					fmt.Printf("    %s\n", route)
+				}
+				total += 1 + len(target.routes)
+			}
+			if options.Global.DryRun {
+				return
+			}
+			if !opts.Yes && !confirm(fmt.Sprintf("Delete %d objects? [y/N]: ", total)) {
+				output.Errorf("Deletion aborted")
+				return
+			}
+
+			deleted, failed := 0, 0
+			remove := func(target *deleteTarget, handler func(sdk.ID, *deleteTarget) error) bool {
+				if err := handler(cluster.ID, target); err != nil {
+					output.Warnf("Failed to delete %s: %s", target, err)
+					failed++
+					return false
+				}
+				fmt.Printf("%s deleted\n", target)
+				deleted++
+				return true
+			}
+			for _, target := range targets {
+				routesDeleted := true
+				for _, route := range target.routes {
+					if !remove(route, _resourceDeleteHandler["route"]) {
+						routesDeleted = false
+					}
+				}
+				if !routesDeleted {
+					output.Warnf("Skipped %s as some of its routes were not deleted", target)
+					failed++
+					continue
+				}
+				remove(target, handler)
+			}
+
+			fmt.Printf("%d deleted, %d failed\n", deleted, failed)
+			if failed > 0 {
+				output.Errorf("%d of %d objects failed to delete", failed, total)
 			}
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Delete.Kind, "kind", "cluster", "Specify the resource kind")
-	cmd.PersistentFlags().StringVar(&options.Global.Resource.Delete.ID, "id", "", "Specify the id of resource")
+	cmd.PersistentFlags().StringSliceVar(&options.Global.Resource.Delete.IDs, "id", nil, "Specify the id of resource, can be specified multiple times")
+	cmd.PersistentFlags().StringSliceVar(&options.Global.Resource.Delete.Names, "name", nil, "Specify the name of resource, can be specified multiple times")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Delete.ServiceID, "service-id", "0", "Specify the id of service resource, when delete route this value should be set")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.Delete.Service, "service", "", "Specify the name of service resource, it can be used instead of --service-id")
+	cmd.PersistentFlags().StringVarP(&options.Global.Resource.Delete.Selector, "selector", "l", "", "Delete resources matching the label selector, supports key=value, key!=value and key in (v1,v2)")
+	cmd.PersistentFlags().BoolVar(&options.Global.Resource.Delete.All, "all", false, "Delete all the resources of the kind")
+	cmd.PersistentFlags().BoolVar(&options.Global.Resource.Delete.Cascade, "cascade", true, "Delete the routes of the services as well")
+	cmd.PersistentFlags().BoolVarP(&options.Global.Resource.Delete.Yes, "yes", "y", false, "Skip the confirmation prompt")
 
 	return cmd
 }
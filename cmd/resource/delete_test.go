@@ -27,6 +27,7 @@ import (
 
 	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
 	sdk "github.com/api7/cloud-go-sdk"
 )
 
@@ -52,14 +53,15 @@ func TestServiceDelete(t *testing.T) {
 					},
 				},
 			},
-			args: []string{"delete", "--kind", "service", "--id", "123"},
+			args: []string{"delete", "--kind", "service", "--id", "123", "--yes"},
 			mockCloud: func(api *cloud.MockAPI) {
 				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
 					ID: 123,
 				}, nil)
+				api.EXPECT().IterateRoutes(sdk.ID(123), sdk.ID(123), nil, gomock.Any()).Return(nil)
 				api.EXPECT().DeleteService(sdk.ID(123), sdk.ID(123)).Return(nil)
 			},
-			outputs: []string{""},
+			outputs: []string{"service/123 deleted\n", "1 deleted, 0 failed\n"},
 		},
 	}
 	for _, tc := range testCases {
@@ -119,7 +121,7 @@ func TestDeleteRoute(t *testing.T) {
 					},
 				},
 			},
-			args: []string{"delete", "--kind", "route", "--id", "123", "--service-id", "456"},
+			args: []string{"delete", "--kind", "route", "--id", "123", "--service-id", "456", "--yes"},
 			mockCloud: func(api *cloud.MockAPI) {
 				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
 					ID: 100,
@@ -142,7 +144,7 @@ func TestDeleteRoute(t *testing.T) {
 					},
 				},
 			},
-			args: []string{"delete", "--kind", "route", "--id", "a", "--service-id", "456"},
+			args: []string{"delete", "--kind", "route", "--id", "a", "--service-id", "456", "--yes"},
 			mockCloud: func(api *cloud.MockAPI) {
 				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
 					ID: 100,
@@ -165,7 +167,7 @@ func TestDeleteRoute(t *testing.T) {
 					},
 				},
 			},
-			args: []string{"delete", "--kind", "route", "--id", "123", "--service-id", "456"},
+			args: []string{"delete", "--kind", "route", "--id", "123", "--service-id", "456", "--yes"},
 			mockCloud: func(api *cloud.MockAPI) {
 				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
 					ID: 100,
@@ -214,3 +216,167 @@ func TestDeleteRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkDelete(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		stdin     string
+		mockCloud func(api *cloud.MockAPI)
+		outputs   []string
+	}{
+		{
+			name: "delete services by selector in cascade",
+			args: []string{"delete", "--kind", "service", "--selector", "team=payments", "--yes"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 2, ApplicationSpec: sdk.ApplicationSpec{Name: "payments", Labels: []string{"team=payments"}}},
+					&sdk.Application{ID: 3, ApplicationSpec: sdk.ApplicationSpec{Name: "orders", Labels: []string{"team=orders"}}},
+				))
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 4, AppID: 2, APISpec: sdk.APISpec{Name: "charge"}},
+				))
+				gomock.InOrder(
+					api.EXPECT().DeleteRoute(sdk.ID(1), sdk.ID(2), sdk.ID(4)).Return(nil),
+					api.EXPECT().DeleteService(sdk.ID(1), sdk.ID(2)).Return(nil),
+				)
+			},
+			outputs: []string{
+				"The following objects will be deleted:\n  service/payments (id: 2)\n    route/charge (id: 4)\n",
+				"route/charge (id: 4) deleted\n",
+				"service/payments (id: 2) deleted\n",
+				"2 deleted, 0 failed\n",
+			},
+		},
+		{
+			name: "skip service if its routes are not deleted",
+			args: []string{"delete", "--kind", "service", "--id", "2", "--yes"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 4, AppID: 2, APISpec: sdk.APISpec{Name: "charge"}},
+				))
+				api.EXPECT().DeleteRoute(sdk.ID(1), sdk.ID(2), sdk.ID(4)).Return(errors.New("mock error"))
+			},
+			outputs: []string{
+				"WARNING: Failed to delete route/charge (id: 4): mock error",
+				"WARNING: Skipped service/2 as some of its routes were not deleted",
+				"0 deleted, 2 failed\n",
+				"ERROR: 2 of 2 objects failed to delete",
+			},
+		},
+		{
+			name: "delete multiple consumers",
+			args: []string{"delete", "--kind", "consumer", "--id", "1", "--id", "2", "--yes"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().DeleteConsumer(sdk.ID(1), sdk.ID(1)).Return(nil)
+				api.EXPECT().DeleteConsumer(sdk.ID(1), sdk.ID(2)).Return(errors.New("mock error"))
+			},
+			outputs: []string{
+				"consumer/1 deleted\n",
+				"WARNING: Failed to delete consumer/2: mock error",
+				"1 deleted, 1 failed\n",
+				"ERROR: 1 of 2 objects failed to delete",
+			},
+		},
+		{
+			name: "delete consumers by name",
+			args: []string{"delete", "consumer/jack", "consumer/rose", "--yes"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateConsumers(sdk.ID(1), &sdk.Filter{Search: "jack"}, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Consumer{ID: 5, Name: "jack"},
+				))
+				api.EXPECT().IterateConsumers(sdk.ID(1), &sdk.Filter{Search: "rose"}, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Consumer{ID: 6, Name: "rose"},
+				))
+				api.EXPECT().DeleteConsumer(sdk.ID(1), sdk.ID(5)).Return(nil)
+				api.EXPECT().DeleteConsumer(sdk.ID(1), sdk.ID(6)).Return(nil)
+			},
+			outputs: []string{
+				"consumer/jack (id: 5) deleted\n",
+				"consumer/rose (id: 6) deleted\n",
+			},
+		},
+		{
+			name: "delete resources of different kinds",
+			args: []string{"delete", "consumer/jack", "service/users", "--yes"},
+			outputs: []string{
+				"ERROR: All the resources should be of the same kind",
+			},
+		},
+		{
+			name:  "confirm deletion",
+			args:  []string{"delete", "--kind", "consumer", "--all"},
+			stdin: "y\n",
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Consumer{ID: 5, Name: "jack"},
+				))
+				api.EXPECT().DeleteConsumer(sdk.ID(1), sdk.ID(5)).Return(nil)
+			},
+			outputs: []string{
+				"Delete 1 objects? [y/N]: ",
+				"consumer/jack (id: 5) deleted\n",
+			},
+		},
+		{
+			name:  "abort deletion",
+			args:  []string{"delete", "--kind", "consumer", "--all"},
+			stdin: "n\n",
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{ID: 1}, nil)
+				api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Consumer{ID: 5, Name: "jack"},
+				))
+			},
+			outputs: []string{
+				"  consumer/jack (id: 5)\n",
+				"ERROR: Deletion aborted",
+			},
+		},
+		{
+			name:    "mutually exclusive targets",
+			args:    []string{"delete", "--kind", "consumer", "--id", "1", "--all"},
+			outputs: []string{"ERROR: --id/--name, --selector and --all are mutually exclusive"},
+		},
+		{
+			name:    "missing targets",
+			args:    []string{"delete", "--kind", "consumer"},
+			outputs: []string{"ERROR: one of --id/--name, --selector and --all is required"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.PrepareFakeConfiguration(t)
+
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				ctrl := gomock.NewController(t)
+				api := cloud.NewMockAPI(ctrl)
+				if tc.mockCloud != nil {
+					tc.mockCloud(api)
+				}
+				cloud.NewClient = func(_ string, _ string, _ bool) (cloud.API, error) {
+					return api, nil
+				}
+				cloud.DefaultClient = api
+				cmd := NewCommand()
+				cmd.SetArgs(tc.args)
+				_ = cmd.Execute()
+				return
+			}
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			cmd.Stdin = strings.NewReader(tc.stdin)
+			output, _ := cmd.CombinedOutput()
+			for _, o := range tc.outputs {
+				assert.Contains(t, string(output), o, "check output")
+			}
+		})
+	}
+}
@@ -15,10 +15,6 @@
 package resource
 
 import (
-	"encoding/json"
-	"fmt"
-	"strconv"
-
 	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/spf13/cobra"
 
@@ -75,16 +71,12 @@ var (
 			if err != nil {
 				output.Errorf("Failed to get the default cluster: %s", err.Error())
 			}
-			serviceID := options.Global.Resource.Get.ServiceID
-			uint64ServiceID, err := strconv.ParseUint(serviceID, 10, 64)
-			if err != nil {
-				output.Errorf("Failed to parse service-id: %s", err.Error())
-			}
-			if uint64ServiceID == 0 {
+			serviceID := resolveServiceID(options.Global.Resource.Get.ServiceID, options.Global.Resource.Get.Service)
+			if serviceID == 0 {
 				output.Errorf("--service-id option is required")
 			}
 
-			service, err := cloud.DefaultClient.GetRoute(cluster.ID, sdk.ID(uint64ServiceID), id)
+			service, err := cloud.DefaultClient.GetRoute(cluster.ID, serviceID, id)
 			if err != nil {
 				output.Errorf("Failed to get route: %s", err.Error())
 			}
@@ -95,9 +87,11 @@ var (
 
 func newGetCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "get",
-		Short:   "get the resource detail by the Cloud CLI.",
-		Example: `cloud-cli resource get --kind ssl --id 12345`,
+		Use:   "get [KIND/NAME]",
+		Short: "get the resource detail by the Cloud CLI.",
+		Example: `cloud-cli resource get --kind ssl --id 12345
+cloud-cli resource get service/users
+cloud-cli resource get --kind route --name list-users --service users`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if err := options.Global.Resource.List.Validate(); err != nil {
 				output.Errorf(err.Error())
@@ -109,21 +103,26 @@ func newGetCommand() *cobra.Command {
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			kind := options.Global.Resource.Get.Kind
-			id := options.Global.Resource.Get.ID
+			opts := &options.Global.Resource.Get
+			applyRef(args, &opts.Kind, &opts.Name)
+			kind := opts.Kind
 			handler, ok := _resourceFetchHandler[kind]
 			if !ok {
 				output.Errorf("This kind of resource is not supported")
 			} else {
-				uint64ID, _ := strconv.ParseUint(id, 10, 64)
-				resource := handler(sdk.ID(uint64ID))
-				text, _ := json.MarshalIndent(resource, "", "\t")
-				fmt.Println(string(text))
+				var serviceID sdk.ID
+				if kind == "route" && opts.Name != "" {
+					serviceID = resolveServiceID(opts.ServiceID, opts.Service)
+				}
+				resource := handler(resolveID(kind, opts.ID, opts.Name, serviceID))
+				output.PrintResource(kind, resource, output.FormatJSON)
 			}
 		},
 	}
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Get.Kind, "kind", "cluster", "Specify the resource kind")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Get.ID, "id", "", "Specify the id of resource")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.Get.Name, "name", "", "Specify the name of resource, it's resolved to the id")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Get.ServiceID, "service-id", "0", "Specify the id of service resource, when get route this value should be set")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.Get.Service, "service", "", "Specify the name of service resource, it can be used instead of --service-id")
 	return cmd
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
 )
 
 func TestResourceGet(t *testing.T) {
@@ -133,6 +134,62 @@ func TestServiceGet(t *testing.T) {
 			},
 			outputs: []string{"{\n\t\"name\": \"\",\n\t\"description\": \"\",\n\t\"path_prefix\": \"\",\n\t\"hosts\": null,\n\t\"upstreams\": null,\n\t\"active\": 0,\n\t\"id\": \"123\",\n\t\"cluster_id\": \"123\",\n\t\"status\": 0,\n\t\"created_at\": \"0001-01-01T00:00:00Z\",\n\t\"updated_at\": \"0001-01-01T00:00:00Z\",\n\t\"available_cert_ids\": null,\n\t\"canary_release_id\": null,\n\t\"canary_upstream_version_list\": null\n}"},
 		},
+		{
+			name: "get service by name",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"get", "service/users"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 123,
+				}, nil).Times(2)
+				api.EXPECT().IterateServices(sdk.ID(123), &sdk.Filter{Search: "users"}, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 7, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+					&sdk.Application{ID: 8, ApplicationSpec: sdk.ApplicationSpec{Name: "users-v2"}},
+				))
+				api.EXPECT().GetService(sdk.ID(123), sdk.ID(7)).Return(&sdk.Application{
+					ID:              7,
+					ApplicationSpec: sdk.ApplicationSpec{Name: "users"},
+				}, nil)
+			},
+			outputs: []string{"\"name\": \"users\"", "\"id\": \"7\""},
+		},
+		{
+			name: "get service by ambiguous name",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"get", "--kind", "service", "--name", "users"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 123,
+				}, nil)
+				api.EXPECT().IterateServices(sdk.ID(123), &sdk.Filter{Search: "users"}, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 7, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+					&sdk.Application{ID: 8, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+				))
+			},
+			outputs: []string{"ERROR: Failed to resolve service: service \"users\" matches 2 objects (7, 8): ambiguous name, please use the ID instead"},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -15,8 +15,6 @@
 package resource
 
 import (
-	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/spf13/cobra"
@@ -25,19 +23,32 @@ import (
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/selector"
 	sdk "github.com/api7/cloud-go-sdk"
 )
 
 var (
 	_resourceListHandler = map[string]func() interface{}{
 		"cluster": func() interface{} {
-			user, err := cloud.Client().Me()
+			org, err := cloud.Client().GetDefaultOrganization()
 			if err != nil {
-				output.Errorf(err.Error())
+				output.Errorf("Failed to get default organization: %s", err.Error())
 			}
-			limit := options.Global.Resource.List.Limit
-			skip := options.Global.Resource.List.Skip
-			clusters, err := cloud.DefaultClient.ListClusters(user.OrgIDs[0], limit, skip)
+			clusters, err := listResources(
+				func(limit, skip int) ([]*sdk.Cluster, error) {
+					return cloud.DefaultClient.ListClusters(org.ID, limit, skip)
+				},
+				func(filter *sdk.Filter, fn func(*sdk.Cluster) error) error {
+					return cloud.DefaultClient.IterateClusters(org.ID, filter, fn)
+				},
+				func(c *sdk.Cluster) ([]string, map[string][]string) {
+					return nil, map[string][]string{
+						"id":     {c.ID.String()},
+						"name":   {c.Name},
+						"status": {strconv.Itoa(int(c.Status)), c.Status.String()},
+					}
+				},
+			)
 			if err != nil {
 				output.Errorf("Failed to list clusters: %s", err.Error())
 			}
@@ -46,14 +57,71 @@ var (
 			}
 			return clusters
 		},
+		"organization": func() interface{} {
+			orgs, err := cloud.Client().ListOrganizations()
+			if err != nil {
+				output.Errorf("Failed to list organizations: %s", err.Error())
+			}
+			// A user only belongs to a few organizations, they're fetched
+			// at once, so just paginate them locally.
+			orgs, err = listResources(
+				func(limit, skip int) ([]*sdk.Organization, error) {
+					if skip >= len(orgs) {
+						return nil, nil
+					}
+					end := skip + limit
+					if end > len(orgs) {
+						end = len(orgs)
+					}
+					return orgs[skip:end], nil
+				},
+				func(_ *sdk.Filter, fn func(*sdk.Organization) error) error {
+					for _, org := range orgs {
+						if err := fn(org); err != nil {
+							if err == cloud.ErrStopIteration {
+								return nil
+							}
+							return err
+						}
+					}
+					return nil
+				},
+				func(o *sdk.Organization) ([]string, map[string][]string) {
+					return nil, map[string][]string{
+						"id":   {o.ID.String()},
+						"name": {o.Name},
+					}
+				},
+			)
+			if err != nil {
+				output.Errorf("Failed to list organizations: %s", err.Error())
+			}
+			if orgs == nil {
+				return nil
+			}
+			return orgs
+		},
 		"service": func() interface{} {
 			cluster, err := cloud.Client().GetDefaultCluster()
 			if err != nil {
 				output.Errorf("Failed to get default cluster: %s", err.Error())
 			}
-			limit := options.Global.Resource.List.Limit
-			skip := options.Global.Resource.List.Skip
-			services, err := cloud.DefaultClient.ListServices(cluster.ID, limit, skip)
+			services, err := listResources(
+				func(limit, skip int) ([]*sdk.Application, error) {
+					return cloud.DefaultClient.ListServices(cluster.ID, limit, skip)
+				},
+				func(filter *sdk.Filter, fn func(*sdk.Application) error) error {
+					return cloud.DefaultClient.IterateServices(cluster.ID, filter, fn)
+				},
+				func(s *sdk.Application) ([]string, map[string][]string) {
+					return s.Labels, map[string][]string{
+						"id":          {s.ID.String()},
+						"name":        {s.Name},
+						"path_prefix": {s.PathPrefix},
+						"status":      entityStatus(s.Status),
+					}
+				},
+			)
 			if err != nil {
 				output.Errorf("Failed to list services: %s", err.Error())
 			}
@@ -67,10 +135,21 @@ var (
 			if err != nil {
 				output.Errorf("Failed to get default cluster: %s", err.Error())
 			}
-			limit := options.Global.Resource.List.Limit
-			skip := options.Global.Resource.List.Skip
-
-			ssl, err := cloud.DefaultClient.ListSSL(cluster.ID, limit, skip)
+			ssl, err := listResources(
+				func(limit, skip int) ([]*sdk.CertificateDetails, error) {
+					return cloud.DefaultClient.ListSSL(cluster.ID, limit, skip)
+				},
+				func(filter *sdk.Filter, fn func(*sdk.CertificateDetails) error) error {
+					return cloud.DefaultClient.IterateSSL(cluster.ID, filter, fn)
+				},
+				func(c *sdk.CertificateDetails) ([]string, map[string][]string) {
+					return c.Labels, map[string][]string{
+						"id":     {c.ID.String()},
+						"sni":    c.SNIs,
+						"status": entityStatus(c.Status),
+					}
+				},
+			)
 			if err != nil {
 				output.Errorf("Failed to list ssl: %s", err.Error())
 			}
@@ -84,10 +163,20 @@ var (
 			if err != nil {
 				output.Errorf("Failed to get default cluster: %s", err.Error())
 			}
-			limit := options.Global.Resource.List.Limit
-			skip := options.Global.Resource.List.Skip
-
-			ssl, err := cloud.DefaultClient.ListConsumers(cluster.ID, limit, skip)
+			ssl, err := listResources(
+				func(limit, skip int) ([]*sdk.Consumer, error) {
+					return cloud.DefaultClient.ListConsumers(cluster.ID, limit, skip)
+				},
+				func(filter *sdk.Filter, fn func(*sdk.Consumer) error) error {
+					return cloud.DefaultClient.IterateConsumers(cluster.ID, filter, fn)
+				},
+				func(c *sdk.Consumer) ([]string, map[string][]string) {
+					return c.Labels, map[string][]string{
+						"id":   {c.ID.String()},
+						"name": {c.Name},
+					}
+				},
+			)
 			if err != nil {
 				output.Errorf("Failed to list ssl: %s", err.Error())
 			}
@@ -101,9 +190,6 @@ var (
 			if err != nil {
 				output.Errorf("Failed to get default cluster: %s", err.Error())
 			}
-			limit := options.Global.Resource.List.Limit
-			skip := options.Global.Resource.List.Skip
-
 			serviceID := options.Global.Resource.List.ServiceID
 			uint64ServiceID, err := strconv.ParseUint(serviceID, 10, 64)
 			if err != nil {
@@ -113,7 +199,21 @@ var (
 				output.Errorf("--service-id is required")
 			}
 
-			routes, err := cloud.DefaultClient.ListRoutes(cluster.ID, sdk.ID(uint64ServiceID), limit, skip)
+			routes, err := listResources(
+				func(limit, skip int) ([]*sdk.API, error) {
+					return cloud.DefaultClient.ListRoutes(cluster.ID, sdk.ID(uint64ServiceID), limit, skip)
+				},
+				func(filter *sdk.Filter, fn func(*sdk.API) error) error {
+					return cloud.DefaultClient.IterateRoutes(cluster.ID, sdk.ID(uint64ServiceID), filter, fn)
+				},
+				func(r *sdk.API) ([]string, map[string][]string) {
+					return r.Labels, map[string][]string{
+						"id":     {r.ID.String()},
+						"name":   {r.Name},
+						"status": entityStatus(r.Status),
+					}
+				},
+			)
 			if err != nil {
 				output.Errorf("Failed to list routes: %s", err.Error())
 			}
@@ -125,6 +225,76 @@ var (
 	}
 )
 
+// listResources lists a page of resources according to the --limit and
+// --skip options. If --all or any selector is specified, it walks through
+// all the resources instead, and the selectors are applied on the client
+// side, while the name field is also sent to the server as the search
+// condition to reduce the pages.
+func listResources[T any](
+	list func(limit, skip int) ([]*T, error),
+	iterate func(filter *sdk.Filter, fn func(*T) error) error,
+	attributes func(*T) (labels []string, fields map[string][]string),
+) ([]*T, error) {
+	opts := options.Global.Resource.List
+	labelSelector, err := selector.Parse(opts.Selector)
+	if err != nil {
+		return nil, err
+	}
+	fieldSelector, err := selector.ParseFields(opts.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.All && len(labelSelector) == 0 && len(fieldSelector) == 0 {
+		return list(opts.Limit, opts.Skip)
+	}
+
+	var filter *sdk.Filter
+	if name, ok := fieldSelector.Value("name"); ok {
+		filter = &sdk.Filter{Search: name}
+	}
+
+	var (
+		objs    []*T
+		skipped int
+	)
+	err = iterate(filter, func(obj *T) error {
+		labels, fields := attributes(obj)
+		if !labelSelector.Matches(labels) || !fieldSelector.Matches(fields) {
+			return nil
+		}
+		if opts.All {
+			objs = append(objs, obj)
+			return nil
+		}
+		if skipped < opts.Skip {
+			skipped++
+			return nil
+		}
+		objs = append(objs, obj)
+		if len(objs) >= opts.Limit {
+			return cloud.ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+func entityStatus(status sdk.EntityStatus) []string {
+	values := []string{strconv.Itoa(int(status))}
+	switch status {
+	case sdk.Uninitialized:
+		values = append(values, "uninitialized")
+	case sdk.Normal:
+		values = append(values, "normal")
+	case sdk.Deleted:
+		values = append(values, "deleted")
+	}
+	return values
+}
+
 func newListCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list",
@@ -149,8 +319,7 @@ func newListCommand() *cobra.Command {
 			} else {
 				resource := handler()
 				if resource != nil {
-					text, _ := json.MarshalIndent(resource, "", "\t")
-					fmt.Println(string(text))
+					output.PrintResource(kind, resource, output.FormatJSON)
 				}
 			}
 		},
@@ -159,5 +328,8 @@ func newListCommand() *cobra.Command {
 	cmd.PersistentFlags().IntVar(&options.Global.Resource.List.Limit, "limit", 10, "Specify the amount of data to be listed")
 	cmd.PersistentFlags().IntVar(&options.Global.Resource.List.Skip, "skip", 0, "Specifies how much data to skip ahead")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.List.ServiceID, "service-id", "", "Specify the service id")
+	cmd.PersistentFlags().BoolVar(&options.Global.Resource.List.All, "all", false, "List all the resources page by page, --limit and --skip are ignored")
+	cmd.PersistentFlags().StringVarP(&options.Global.Resource.List.Selector, "selector", "l", "", "Filter resources by labels, supports key=value, key!=value and key in (v1,v2), separated by comma")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.List.FieldSelector, "field-selector", "", "Filter resources by fields, supports field=value and field!=value, separated by comma, e.g. name=users,status=normal")
 	return cmd
 }
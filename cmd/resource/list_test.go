@@ -28,6 +28,7 @@ import (
 
 	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/testutils"
 )
 
 func TestResourceList(t *testing.T) {
@@ -54,9 +55,9 @@ func TestResourceList(t *testing.T) {
 			},
 			args: []string{"list", "--kind", "cluster"},
 			mockCloud: func(api *cloud.MockAPI) {
-				api.EXPECT().Me().Return(&sdk.User{
-					Email:  "demo@api7.cloud",
-					OrgIDs: []sdk.ID{123},
+				api.EXPECT().GetDefaultOrganization().Return(&sdk.Organization{
+					ID:   123,
+					Name: "API7.AI",
 				}, nil)
 				api.EXPECT().ListClusters(sdk.ID(123), gomock.Any(), gomock.Any()).Return([]*sdk.Cluster{
 					{
@@ -67,6 +68,52 @@ func TestResourceList(t *testing.T) {
 			},
 			outputs: []string{"[\n\t{\n\t\t\"org_id\": \"0\",\n\t\t\"region_id\": \"0\",\n\t\t\"status\": 0,\n\t\t\"domain\": \"\",\n\t\t\"settings\": {\n\t\t\t\"client_settings\": {\n\t\t\t\t\"client_real_ip\": {\n\t\t\t\t\t\"replace_from\": {},\n\t\t\t\t\t\"recursive_search\": false,\n\t\t\t\t\t\"enabled\": false\n\t\t\t\t},\n\t\t\t\t\"maximum_request_body_size\": 0\n\t\t\t},\n\t\t\t\"observability_settings\": {\n\t\t\t\t\"metrics\": {\n\t\t\t\t\t\"enabled\": false\n\t\t\t\t},\n\t\t\t\t\"show_upstream_status_in_response_header\": false,\n\t\t\t\t\"access_log_rotate\": {\n\t\t\t\t\t\"enabled\": false,\n\t\t\t\t\t\"enable_compression\": false\n\t\t\t\t}\n\t\t\t},\n\t\t\t\"api_proxy_settings\": {\n\t\t\t\t\"enable_request_buffering\": false,\n\t\t\t\t\"url_handling_options\": null\n\t\t\t}\n\t\t},\n\t\t\"config_version\": 0,\n\t\t\"id\": \"123\",\n\t\t\"name\": \"API7.AI\",\n\t\t\"created_at\": \"0001-01-01T00:00:00Z\",\n\t\t\"updated_at\": \"0001-01-01T00:00:00Z\"\n\t}\n]"},
 		},
+		{
+			name: "list organizations",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"list", "--kind", "organization", "--skip", "1"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().ListOrganizations().Return([]*sdk.Organization{
+					{ID: 123, Name: "API7.AI"},
+					{ID: 456, Name: "Staging"},
+				}, nil)
+			},
+			outputs: []string{"\"id\": \"456\",\n\t\t\"name\": \"Staging\""},
+		},
+		{
+			name: "list organizations by name",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"list", "--kind", "organization", "--field-selector", "name=API7.AI"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().ListOrganizations().Return([]*sdk.Organization{
+					{ID: 123, Name: "API7.AI"},
+					{ID: 456, Name: "Staging"},
+				}, nil)
+			},
+			outputs: []string{"\"id\": \"123\",\n\t\t\"name\": \"API7.AI\""},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -142,6 +189,33 @@ func TestServiceList(t *testing.T) {
 			},
 			outputs: []string{"[\n\t{\n\t\t\"name\": \"\",\n\t\t\"description\": \"\",\n\t\t\"path_prefix\": \"\",\n\t\t\"hosts\": null,\n\t\t\"upstreams\": null,\n\t\t\"active\": 0,\n\t\t\"id\": \"123\",\n\t\t\"cluster_id\": \"123\",\n\t\t\"status\": 0,\n\t\t\"created_at\": \"0001-01-01T00:00:00Z\",\n\t\t\"updated_at\": \"0001-01-01T00:00:00Z\",\n\t\t\"available_cert_ids\": null,\n\t\t\"canary_release_id\": null,\n\t\t\"canary_upstream_version_list\": null\n\t}\n]"},
 		},
+		{
+			name: "list all services",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"list", "--kind", "service", "--all"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID:   123,
+					Name: "API7.AI",
+				}, nil)
+				api.EXPECT().IterateServices(sdk.ID(123), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+					&sdk.Application{ID: 1, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+					&sdk.Application{ID: 2, ApplicationSpec: sdk.ApplicationSpec{Name: "orders"}},
+				))
+			},
+			outputs: []string{"\"name\": \"users\"", "\"name\": \"orders\""},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -255,6 +329,78 @@ func TestRouteList(t *testing.T) {
 			},
 			outputs: []string{"Failed to list routes: error"},
 		},
+		{
+			name: "list api with selectors",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"list", "--kind", "route", "--service-id", "456", "--limit", "1",
+				"--selector", "team in (payments)", "--field-selector", "status=normal"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 100,
+				}, nil)
+				api.EXPECT().IterateRoutes(sdk.ID(100), sdk.ID(456), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 1, Status: sdk.Normal, APISpec: sdk.APISpec{Name: "list-orders", Labels: []string{"team=orders"}}},
+					&sdk.API{ID: 2, Status: sdk.Deleted, APISpec: sdk.APISpec{Name: "pay", Labels: []string{"team=payments"}}},
+					&sdk.API{ID: 3, Status: sdk.Normal, APISpec: sdk.APISpec{Name: "charge", Labels: []string{"team=payments"}}},
+					&sdk.API{ID: 4, Status: sdk.Normal, APISpec: sdk.APISpec{Name: "refund", Labels: []string{"team=payments"}}},
+				))
+			},
+			outputs: []string{"[\n\t{\n\t\t\"name\": \"charge\""},
+		},
+		{
+			name: "list api with name field selector",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args: []string{"list", "--kind", "route", "--service-id", "456", "--field-selector", "name=refund"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().GetDefaultCluster().Return(&sdk.Cluster{
+					ID: 100,
+				}, nil)
+				api.EXPECT().IterateRoutes(sdk.ID(100), sdk.ID(456), &sdk.Filter{Search: "refund"}, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+					&sdk.API{ID: 4, APISpec: sdk.APISpec{Name: "refund"}},
+					&sdk.API{ID: 5, APISpec: sdk.APISpec{Name: "refund-all"}},
+				))
+			},
+			outputs: []string{"[\n\t{\n\t\t\"name\": \"refund\""},
+		},
+		{
+			name: "list api with invalid selector",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+					},
+				},
+			},
+			args:    []string{"list", "--kind", "route", "--service-id", "456", "--selector", "team in payments"},
+			outputs: []string{"ERROR: invalid --selector: invalid requirement \"team in payments\""},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
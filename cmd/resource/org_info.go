@@ -21,15 +21,19 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/persistence"
 )
 
 func newOrgInfoCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "org-info",
-		Short:   "Show the resource details by the Cloud CLI",
-		Example: `cloud-cli resource org-info`,
+		Use:   "org-info [ORG]",
+		Short: "Show the organization details by the Cloud CLI",
+		Long:  "Show the details of the organization with the given name or ID, the organization selected by --org or the default profile is used if it's not specified.",
+		Example: `cloud-cli resource org-info
+cloud-cli resource org-info my-org`,
+		Args: cobra.MaximumNArgs(1),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if err := persistence.Init(); err != nil {
 				output.Errorf(err.Error())
@@ -53,9 +57,24 @@ func newOrgInfoCommand() *cobra.Command {
 					return
 				}
 
+				target := options.Global.Org
+				if len(args) > 0 {
+					target = args[0]
+				}
+				if target == "" {
+					target = profile.Org
+				}
+				if target != "" {
+					api.SelectOrganization(target)
+				}
+
 				org, err := api.GetDefaultOrganization()
 				if err != nil {
-					output.Warnf("Failed to get default organization for profile %s: %s", profile.Name, err.Error())
+					if target != "" {
+						output.Warnf("Failed to get organization %s for profile %s: %s", target, profile.Name, err.Error())
+					} else {
+						output.Warnf("Failed to get default organization for profile %s: %s", profile.Name, err.Error())
+					}
 					return
 				}
 
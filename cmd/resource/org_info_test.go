@@ -23,6 +23,7 @@ import (
 
 	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/api7/cloud-cli/internal/cloud"
@@ -60,6 +61,53 @@ func TestResourceOrgInfo(t *testing.T) {
 			},
 			outputs: []string{`{"id":"123","name":"API7.AI","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","plan_id":"0","plan_expire_time":"0001-01-01T00:00:00Z","subscription_started_at":null,"owner_id":""}`},
 		},
+		{
+			name: "get org info by name",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+						Org: "API7.AI",
+					},
+				},
+			},
+			args: []string{"org-info", "Staging"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().SelectOrganization("Staging")
+				api.EXPECT().GetDefaultOrganization().Return(&sdk.Organization{
+					ID:   456,
+					Name: "Staging",
+				}, nil)
+			},
+			outputs: []string{`{"id":"456","name":"Staging"`},
+		},
+		{
+			name: "organization not found",
+			config: &persistence.CloudConfiguration{
+				DefaultProfile: "prod",
+				Profiles: []persistence.Profile{
+					{
+						Name:    "prod",
+						Address: "https://prod.api7.ai",
+						User: persistence.User{
+							AccessToken: "prod-token",
+						},
+						Org: "unknown",
+					},
+				},
+			},
+			args: []string{"org-info"},
+			mockCloud: func(api *cloud.MockAPI) {
+				api.EXPECT().SelectOrganization("unknown")
+				api.EXPECT().GetDefaultOrganization().Return(nil, errors.Wrap(cloud.ErrNotFound, "organization \"unknown\""))
+			},
+			outputs: []string{`WARNING: Failed to get organization unknown for profile prod: organization "unknown": not found`},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
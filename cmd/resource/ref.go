@@ -0,0 +1,78 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	sdk "github.com/api7/cloud-go-sdk"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/output"
+)
+
+// applyRef applies the positional reference to the kind and name options,
+// the reference can be KIND/NAME (e.g. "service/users") or just NAME.
+func applyRef(args []string, kind *string, name *string) {
+	if len(args) == 0 {
+		return
+	}
+	if len(args) > 1 {
+		output.Errorf("Only one resource can be specified")
+	}
+	if *name != "" {
+		output.Errorf("--name and the resource reference are mutually exclusive")
+	}
+	if k, n, ok := strings.Cut(args[0], "/"); ok {
+		*kind = k
+		*name = n
+	} else {
+		*name = args[0]
+	}
+	if *name == "" {
+		output.Errorf("Invalid resource reference: %s", args[0])
+	}
+}
+
+// resolveID returns the object ID specified by id, or resolves it from the
+// name if the name is not empty. The serviceID is only required for routes.
+func resolveID(kind, id, name string, serviceID sdk.ID) sdk.ID {
+	if name == "" {
+		uint64ID, _ := strconv.ParseUint(id, 10, 64)
+		return sdk.ID(uint64ID)
+	}
+	if id != "" {
+		output.Errorf("--id and --name are mutually exclusive")
+	}
+	resolved, err := cloud.DefaultResolver().ResolveDefault(kind, name, serviceID)
+	if err != nil {
+		output.Errorf("Failed to resolve %s: %s", kind, err.Error())
+	}
+	return resolved
+}
+
+// resolveServiceID returns the service ID specified by --service-id, or
+// resolves it from the service name specified by --service.
+func resolveServiceID(serviceID, service string) sdk.ID {
+	if service != "" {
+		return resolveID("service", "", service, 0)
+	}
+	uint64ServiceID, err := strconv.ParseUint(serviceID, 10, 64)
+	if err != nil {
+		output.Errorf("Failed to parse service-id: %s", err.Error())
+	}
+	return sdk.ID(uint64ServiceID)
+}
@@ -15,10 +15,7 @@
 package resource
 
 import (
-	"encoding/json"
-	"fmt"
 	"os"
-	"strconv"
 
 	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/spf13/cobra"
@@ -130,9 +127,13 @@ var (
 
 func newUpdateCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [KIND/NAME]",
 		Short: "update a resource",
+		Example: `cloud-cli resource update --kind service --id 123 --from-file service.json
+cloud-cli resource update service/users --from-file service.json`,
 		PreRun: func(cmd *cobra.Command, args []string) {
+			opts := &options.Global.Resource.Update
+			applyRef(args, &opts.Kind, &opts.Name)
 			if err := options.Global.Resource.Update.Validate(); err != nil {
 				output.Errorf(err.Error())
 				return
@@ -143,23 +144,30 @@ func newUpdateCommand() *cobra.Command {
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			id := options.Global.Resource.Update.ID
-			kind := options.Global.Resource.Update.Kind
+			opts := options.Global.Resource.Update
+			kind := opts.Kind
 
 			handler, ok := _resourceUpdateHandler[kind]
 			if !ok {
 				output.Errorf("This kind of resource is not supported")
 			} else {
-				uint64ID, _ := strconv.ParseUint(id, 10, 64)
-				resource := handler(sdk.ID(uint64ID))
-				text, _ := json.MarshalIndent(resource, "", "\t")
-				fmt.Println(string(text))
+				var serviceID sdk.ID
+				if kind == "route" && opts.Name != "" {
+					if opts.Service == "" {
+						output.Errorf("--service is required to update route by name")
+					}
+					serviceID = resolveServiceID("", opts.Service)
+				}
+				resource := handler(resolveID(kind, opts.ID, opts.Name, serviceID))
+				output.PrintResource(kind, resource, output.FormatJSON)
 			}
 		},
 	}
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.FromFile, "from-file", "", "Specify the resource definition file")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.Kind, "kind", "", "Specify the resource kind")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.ID, "id", "", "Specify the resource ID")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.Name, "name", "", "Specify the resource name, it's resolved to the resource ID")
+	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.Service, "service", "", "Specify the service name of the route, it's required when updating route by name")
 	cmd.PersistentFlags().StringSliceVar(&options.Global.Resource.Update.Labels, "label", nil, "Add label for this resource")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.SSL.CertFile, "cert", "", "Specify the certificate file (this option is only useful when kind is ssl)")
 	cmd.PersistentFlags().StringVar(&options.Global.Resource.Update.SSL.PKeyFile, "pkey", "", "Specify the private key file (this option is only useful when kind is ssl)")
@@ -0,0 +1,104 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/consts"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+func newKubernetesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubernetes [ARG...]",
+		Short: "Roll back the Helm release of Apache APISIX on Kubernetes",
+		Example: `
+cloud-cli rollback kubernetes \
+		--name apisix \
+		--namespace apisix \
+		--revision 2`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Rollback.Kubernetes.Validate(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithTimeout(context.TODO(), consts.DefaultHelmTimeout)
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			opts := options.Global.Rollback.Kubernetes
+			if opts.HelmCLIPath == "" {
+				opts.HelmCLIPath = "helm"
+			}
+			name := options.Global.Rollback.Name
+
+			helm := commands.New(opts.HelmCLIPath, options.Global.DryRun)
+			helm.AppendArgs("rollback", name)
+			// helm rolls back to the previous revision if the revision is omitted.
+			if opts.Revision > 0 {
+				helm.AppendArgs(strconv.Itoa(opts.Revision))
+			}
+			helm.AppendArgs("--namespace", opts.Namespace)
+			helmRun(ctx, helm)
+
+			if opts.Revision > 0 {
+				fmt.Printf("Helm release %s was rolled back to revision %d\n", name, opts.Revision)
+			} else {
+				fmt.Printf("Helm release %s was rolled back to the previous revision\n", name)
+			}
+
+			helm.AppendArgs("history", name, "--namespace", opts.Namespace)
+			if history := helmRun(ctx, helm); history != "" {
+				fmt.Printf("\nRelease history:\n%s", history)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Rollback.Kubernetes.Namespace, "namespace", "apisix", "Specify the Kubernetes name space")
+	cmd.PersistentFlags().IntVar(&options.Global.Rollback.Kubernetes.Revision, "revision", 0, "Specify the revision to roll back to, the previous revision will be used if it's not specified")
+	cmd.PersistentFlags().StringVar(&options.Global.Rollback.Kubernetes.HelmCLIPath, "helm-cli-path", "", "Specify the filepath of the helm command")
+	return cmd
+}
+
+// helmRun runs the helm command and returns its stdout, it exits once
+// the command fails.
+func helmRun(ctx context.Context, helm commands.Cmd) string {
+	if options.Global.DryRun {
+		output.Infof("Running:\n%s\n", helm.String())
+	} else {
+		output.Verbosef("Running:\n%s\n", helm.String())
+	}
+
+	stdout, stderr, err := helm.Run(ctx)
+	if stderr != "" {
+		output.Warnf(stderr)
+	}
+	if err != nil {
+		output.Errorf(err.Error())
+	}
+	return stdout
+}
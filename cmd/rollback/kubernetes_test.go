@@ -0,0 +1,114 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollback
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// _fakeHelm logs the arguments and prints the release history.
+const _fakeHelm = `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/helm.log"
+case "$1" in
+rollback) echo "Rollback was a success! Happy Helming!" ;;
+history)
+  echo "REVISION	STATUS    	CHART       	DESCRIPTION"
+  echo "1       	superseded	apisix-0.11.3	Install complete"
+  echo "2       	superseded	apisix-0.11.3	Upgrade complete"
+  echo "3       	deployed  	apisix-0.11.3	Rollback to 1"
+  ;;
+esac
+`
+
+func TestRollbackKubernetes(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-rollback-test")
+
+	testCases := []struct {
+		name    string
+		args    []string
+		outputs []string
+		logs    []string
+	}{
+		{
+			name: "roll back to the previous revision",
+			args: []string{"kubernetes"},
+			outputs: []string{
+				"Helm release apisix was rolled back to the previous revision",
+				"Release history:",
+				"3       	deployed  	apisix-0.11.3	Rollback to 1",
+			},
+			logs: []string{
+				"rollback apisix --namespace apisix\n",
+				"history apisix --namespace apisix\n",
+			},
+		},
+		{
+			name: "roll back to the specified revision",
+			args: []string{"kubernetes", "--name", "apisix-test", "--namespace", "my-apisix", "--revision", "1"},
+			outputs: []string{
+				"Helm release apisix-test was rolled back to revision 1",
+				"Release history:",
+			},
+			logs: []string{
+				"rollback apisix-test 1 --namespace my-apisix\n",
+				"history apisix-test --namespace my-apisix\n",
+			},
+		},
+		{
+			name:    "invalid revision",
+			args:    []string{"kubernetes", "--revision", "-1"},
+			outputs: []string{"ERROR: --revision should not be negative"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			//Because `os.Exit(-1)` will be triggered in the failure case, so here the test is executed using a subprocess
+			//The method come from: https://talks.golang.org/2014/testing.slide#23
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				cmd := NewCommand()
+				cmd.SetArgs(append(tc.args, "--helm-cli-path", filepath.Join(dir, "helm")))
+				_ = cmd.Execute()
+				return
+			}
+
+			assert.NoError(t, os.MkdirAll(dir, 0755), "create test directory")
+			defer os.RemoveAll(dir)
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "helm"), []byte(_fakeHelm), 0755), "write fake helm")
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+
+			logs, _ := os.ReadFile(filepath.Join(dir, "helm.log"))
+			for _, log := range tc.logs {
+				assert.Contains(t, string(logs), log, "check helm commands")
+			}
+			if len(tc.logs) == 0 {
+				assert.Empty(t, logs, "helm should not be called")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/consts"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+// deploymentStatus is a recorded deployment joined with its live state.
+type deploymentStatus struct {
+	*persistence.Deployment
+	// State is the live state, e.g. "running" or "2/3 pods ready".
+	State string `json:"state"`
+	// Health is one of Healthy, Degraded, Starting, Unhealthy and Unknown.
+	Health string `json:"health"`
+}
+
+func init() {
+	output.RegisterTableDefinition("deployment", output.TableDefinition{
+		Columns: []output.Column{
+			output.NewColumn("Name", false, func(s *deploymentStatus) string { return s.Name }),
+			output.NewColumn("Target", false, func(s *deploymentStatus) string { return string(s.Target) }),
+			output.NewColumn("Image", false, func(s *deploymentStatus) string { return s.Image }),
+			output.NewColumn("Cluster ID", false, func(s *deploymentStatus) string { return s.ClusterID.String() }),
+			output.NewColumn("State", false, func(s *deploymentStatus) string { return s.State }),
+			output.NewColumn("Health", false, func(s *deploymentStatus) string { return s.Health }),
+			output.NewColumn("Namespace", true, func(s *deploymentStatus) string { return valueOrDash(s.Namespace) }),
+			output.NewColumn("Runtime", true, func(s *deploymentStatus) string { return valueOrDash(s.Runtime) }),
+			output.NewColumn("APISIX IDs", true, func(s *deploymentStatus) string { return valueOrDash(strings.Join(s.APISIXIDs, ",")) }),
+			output.NewColumn("Config Hash", true, func(s *deploymentStatus) string { return shortHash(s.ConfigHash) }),
+			output.NewColumn("Deployed At", true, func(s *deploymentStatus) string { return s.DeployedAt.Format(time.RFC3339) }),
+		},
+		Name: func(obj interface{}) string {
+			s := obj.(*deploymentStatus)
+			return string(s.Target) + "/" + s.Name
+		},
+	})
+}
+
+// NewCommand creates the status command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of APISIX instances deployed by the Cloud CLI",
+		Long:  "Show the APISIX instances deployed by the Cloud CLI on this machine, along with their live state on Docker, Kubernetes or bare metal.",
+		Example: `cloud-cli status
+cloud-cli status --output wide`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			deployments, err := persistence.LoadDeployments()
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			if len(deployments) == 0 {
+				output.Infof("No APISIX instances were deployed by the Cloud CLI")
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.TODO(), consts.DefaultKubectlTimeout)
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			statuses := make([]*deploymentStatus, 0, len(deployments))
+			for _, deployment := range deployments {
+				statuses = append(statuses, inspect(ctx, deployment))
+			}
+			output.PrintResource("deployment", statuses, output.FormatTable)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Status.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command")
+	cmd.PersistentFlags().StringVar(&options.Global.Status.KubectlCLIPath, "kubectl-cli-path", "", "Specify the filepath of the kubectl command")
+
+	return cmd
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return valueOrDash(hash)
+}
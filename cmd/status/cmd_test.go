@@ -0,0 +1,116 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+const (
+	_fakeDocker = `#!/bin/sh
+case "$4" in
+apisix) echo "running healthy" ;;
+apisix-1) echo "exited " ;;
+*) echo "Error: No such object: $4" >&2; exit 1 ;;
+esac
+`
+	_fakeKubectl = `#!/bin/sh
+printf 'apisix-0 Running true\napisix-1 Pending false\n'
+`
+)
+
+func TestStatus(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-status-test")
+
+	testCases := []struct {
+		name        string
+		deployments []*persistence.Deployment
+		outputs     []string
+	}{
+		{
+			name:    "nothing deployed",
+			outputs: []string{"No APISIX instances were deployed by the Cloud CLI"},
+		},
+		{
+			name: "docker deployments",
+			deployments: []*persistence.Deployment{
+				{Name: "apisix", Target: persistence.DeploymentDocker, Image: "apache/apisix:2.15.0-centos", ClusterID: 1},
+				{Name: "apisix-1", Target: persistence.DeploymentDocker, Image: "apache/apisix:2.15.0-centos", ClusterID: 1},
+				{Name: "apisix-2", Target: persistence.DeploymentDocker, Image: "apache/apisix:2.15.0-centos", ClusterID: 1},
+			},
+			outputs: []string{
+				"NAME", "HEALTH",
+				"running", "Healthy",
+				"exited", "not found", "Unhealthy",
+			},
+		},
+		{
+			name: "kubernetes and bare metal deployments",
+			deployments: []*persistence.Deployment{
+				{Name: "apisix", Target: persistence.DeploymentKubernetes, Namespace: "apisix", Image: "apache/apisix:2.15.0-centos", ClusterID: 1},
+				{Name: "apisix", Target: persistence.DeploymentBare, Image: "2.15.0", ClusterID: 1},
+			},
+			outputs: []string{
+				"1/2 pods ready", "Degraded",
+				fmt.Sprintf("running (pid %d)", os.Getpid()),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			persistence.HomeDir = dir
+			apisix.PIDFile = filepath.Join(dir, "nginx.pid")
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				cmd := NewCommand()
+				cmd.SetArgs([]string{
+					"--docker-cli-path", filepath.Join(dir, "docker"),
+					"--kubectl-cli-path", filepath.Join(dir, "kubectl"),
+				})
+				_ = cmd.Execute()
+				return
+			}
+
+			defer os.RemoveAll(dir)
+			assert.NoError(t, persistence.Init(), "init persistence")
+			for _, deployment := range tc.deployments {
+				assert.NoError(t, persistence.SaveDeployment(deployment), "save deployment")
+			}
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(_fakeDocker), 0755), "write fake docker")
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "kubectl"), []byte(_fakeKubectl), 0755), "write fake kubectl")
+			// The subprocess checks if its parent, i.e. the test process, is alive.
+			assert.NoError(t, os.WriteFile(apisix.PIDFile, []byte(strconv.Itoa(os.Getpid())), 0644), "write pid file")
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, err := cmd.CombinedOutput()
+			assert.NoError(t, err, "check if the command executed successfully")
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/container"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+const (
+	healthHealthy   = "Healthy"
+	healthDegraded  = "Degraded"
+	healthStarting  = "Starting"
+	healthUnhealthy = "Unhealthy"
+	healthUnknown   = "Unknown"
+)
+
+// inspect joins the deployment with its live state, the status command is
+// read-only so the commands are executed even in the dry run mode.
+func inspect(ctx context.Context, deployment *persistence.Deployment) *deploymentStatus {
+	status := &deploymentStatus{
+		Deployment: deployment,
+	}
+	switch deployment.Target {
+	case persistence.DeploymentDocker:
+		status.State, status.Health = inspectDocker(ctx, deployment.Runtime, deployment.Name)
+	case persistence.DeploymentKubernetes:
+		status.State, status.Health = inspectKubernetes(ctx, deployment.Name, deployment.Namespace)
+	case persistence.DeploymentBare:
+		status.State, status.Health = inspectBare()
+	default:
+		status.State, status.Health = "unknown target", healthUnknown
+	}
+	return status
+}
+
+func inspectDocker(ctx context.Context, runtimeName, name string) (string, string) {
+	runtime, err := container.ParseRuntime(runtimeName)
+	if err != nil {
+		output.Warnf("Failed to inspect container %s: %s", name, err)
+		return "unknown", healthUnknown
+	}
+	// The --docker-cli-path option only applies to the containers run by
+	// Docker, since the deployments might use different runtimes.
+	var path string
+	if runtime == container.Docker {
+		path = options.Global.Status.DockerCLIPath
+	}
+	docker := runtime.Command(path, false)
+	state, err := runtime.InspectState(ctx, docker, name)
+	if err != nil {
+		if errors.Is(err, container.ErrNotFound) {
+			return "not found", healthUnhealthy
+		}
+		output.Warnf("Failed to inspect container %s: %s", name, err)
+		return "unknown", healthUnknown
+	}
+
+	switch {
+	case state.Healthy():
+		return state.String(), healthHealthy
+	case state.Starting():
+		return state.Status, healthStarting
+	case state.Status == "":
+		return state.String(), healthUnknown
+	default:
+		return state.String(), healthUnhealthy
+	}
+}
+
+func inspectKubernetes(ctx context.Context, release, namespace string) (string, string) {
+	path := options.Global.Status.KubectlCLIPath
+	if path == "" {
+		path = "kubectl"
+	}
+	kubectl := commands.New(path, false)
+	kubectl.AppendArgs("get", "pods", "-n", namespace)
+	kubectl.AppendArgs("-l", fmt.Sprintf("app.kubernetes.io/instance=%s", release))
+	kubectl.AppendArgs("-o", `jsonpath={range .items[*]}{.metadata.name}{" "}{.status.phase}{" "}{.status.containerStatuses[*].ready}{"\n"}{end}`)
+	output.Verbosef("Running:\n%s\n", kubectl.String())
+
+	stdout, stderr, err := kubectl.Run(ctx)
+	if err != nil {
+		output.Warnf("Failed to get pods of release %s: %s", release, commandError(stderr, err))
+		return "unknown", healthUnknown
+	}
+
+	var total, ready int
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		total++
+		if podReady(fields[1:]) {
+			ready++
+		}
+	}
+
+	state := fmt.Sprintf("%d/%d pods ready", ready, total)
+	switch {
+	case total == 0:
+		return "no pods", healthUnhealthy
+	case ready == total:
+		return state, healthHealthy
+	case ready > 0:
+		return state, healthDegraded
+	default:
+		return state, healthUnhealthy
+	}
+}
+
+// podReady checks the pod phase and the readiness of its containers.
+func podReady(fields []string) bool {
+	if len(fields) < 2 || fields[0] != "Running" {
+		return false
+	}
+	for _, ready := range fields[1:] {
+		if ready != "true" {
+			return false
+		}
+	}
+	return true
+}
+
+func inspectBare() (string, string) {
+	pid, running, err := apisix.MasterProcess()
+	if err != nil {
+		output.Warnf(err.Error())
+		return "unknown", healthUnknown
+	}
+	if !running {
+		return "stopped", healthUnhealthy
+	}
+	return fmt.Sprintf("running (pid %d)", pid), healthHealthy
+}
+
+func commandError(stderr string, err error) string {
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		return stderr
+	}
+	return err.Error()
+}
@@ -16,12 +16,14 @@ package stop
 
 import (
 	"context"
+	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/api7/cloud-cli/internal/commands"
+	"github.com/api7/cloud-cli/internal/container"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
 	"github.com/api7/cloud-cli/internal/utils"
 )
 
@@ -29,22 +31,38 @@ func newStopDockerCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "docker [ARG...]",
 		Short: "Stop Apache APISIX on Docker",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx, cancel := context.WithCancel(context.TODO())
 			go utils.WaitForSignal(func() {
 				cancel()
 			})
 
-			var (
-				docker commands.Cmd
-				err    error
-			)
 			opts := options.Global.Stop.Docker
-			if opts.DockerCLIPath != "" {
-				docker = commands.New(opts.DockerCLIPath, options.Global.DryRun)
-			} else {
-				docker = commands.New("docker", options.Global.DryRun)
+			names := []string{options.Global.Stop.Name}
+			if opts.AllReplicas {
+				replicas, err := dockerReplicas(options.Global.Stop.Name)
+				if err != nil {
+					output.Errorf(err.Error())
+					return
+				}
+				if len(replicas) == 0 {
+					output.Errorf("No replicas of %s are found", options.Global.Stop.Name)
+					return
+				}
+				names = replicas
 			}
+			runtime, err := persistence.DeploymentRuntime(opts.Runtime, names[0])
+			if err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			docker := runtime.Command(opts.DockerCLIPath, options.Global.DryRun)
 			if options.Global.Stop.Remove {
 				docker.AppendArgs("rm")
 				docker.AppendArgs("-f")
@@ -52,8 +70,10 @@ func newStopDockerCommand() *cobra.Command {
 				docker.AppendArgs("stop")
 			}
 
-			if options.Global.Stop.Name != "" {
-				docker.AppendArgs(options.Global.Stop.Name)
+			for _, name := range names {
+				if name != "" {
+					docker.AppendArgs(name)
+				}
 			}
 			if options.Global.DryRun {
 				output.Infof(docker.String())
@@ -70,9 +90,32 @@ func newStopDockerCommand() *cobra.Command {
 				output.Errorf(err.Error())
 				return
 			}
+			if options.Global.Stop.Remove {
+				for _, name := range names {
+					forgetDeployment(persistence.DeploymentDocker, name, "")
+				}
+			}
 		},
 	}
-	cmd.PersistentFlags().StringVar(&options.Global.Stop.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command")
+	cmd.PersistentFlags().StringVar(&options.Global.Stop.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command (or the podman and nerdctl command for the corresponding runtime)")
+	cmd.PersistentFlags().BoolVar(&options.Global.Stop.Docker.AllReplicas, "all-replicas", false, "Stop all the containers deployed by deploy docker --replicas, the --name option should be the one used to deploy")
+	cmd.PersistentFlags().StringVar(&options.Global.Stop.Docker.Runtime, "runtime", "", "Specify the container runtime, one of: "+strings.Join(container.Runtimes(), ", ")+", the one used to deploy is used by default")
 
 	return cmd
 }
+
+// dockerReplicas returns the container names of the replicas that are
+// deployed with the name by deploy docker --replicas.
+func dockerReplicas(name string) ([]string, error) {
+	deployments, err := persistence.LoadDeployments()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, deployment := range deployments {
+		if deployment.Target == persistence.DeploymentDocker && deployment.ReplicaOf == name {
+			names = append(names, deployment.Name)
+		}
+	}
+	return names, nil
+}
@@ -18,21 +18,26 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/persistence"
 	"github.com/api7/cloud-cli/internal/testutils"
 )
 
 func TestNewStopDockerCommand(t *testing.T) {
 	t.Parallel()
 
+	dir := filepath.Join(os.TempDir(), "cloud-cli-stop-docker-test")
+
 	testcases := []struct {
-		name       string
-		args       []string
-		cmdPattern string
+		name        string
+		args        []string
+		deployments string
+		cmdPattern  string
 	}{
 		{
 			name:       "default deployment name",
@@ -54,11 +59,45 @@ func TestNewStopDockerCommand(t *testing.T) {
 			args:       []string{"docker", "--name", "apisix-0", "--rm"},
 			cmdPattern: "docker rm -f apisix-0",
 		},
+		{
+			name:       "test stop docker command with podman runtime",
+			args:       []string{"docker", "--runtime", "podman"},
+			cmdPattern: "podman stop apisix",
+		},
+		{
+			name: "test stop docker command with the recorded runtime",
+			args: []string{"docker", "--name", "apisix-0"},
+			deployments: `deployments:
+- name: apisix-0
+  target: docker
+  runtime: nerdctl
+`,
+			cmdPattern: "nerdctl stop apisix-0",
+		},
+		{
+			name: "test stop docker command with all replicas",
+			args: []string{"docker", "--all-replicas", "--rm"},
+			deployments: `deployments:
+- name: apisix-0
+  target: docker
+  runtime: podman
+  replica_of: apisix
+- name: apisix-1
+  target: docker
+  runtime: podman
+  replica_of: apisix
+- name: gateway-0
+  target: docker
+  replica_of: gateway
+`,
+			cmdPattern: "podman rm -f apisix-0 apisix-1\n",
+		},
 	}
 	for _, tc := range testcases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				persistence.HomeDir = dir
 				options.Global.DryRun = true
 				options.Global.Verbose = true
 				cmd := NewStopCommand()
@@ -68,6 +107,12 @@ func TestNewStopDockerCommand(t *testing.T) {
 				return
 			}
 
+			defer os.RemoveAll(dir)
+			if tc.deployments != "" {
+				assert.NoError(t, os.MkdirAll(dir, 0755), "create home dir")
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, "deployments.yaml"), []byte(tc.deployments), 0644), "record deployments")
+			}
+
 			testutils.PrepareFakeConfiguration(t)
 			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
 			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
@@ -23,6 +23,7 @@ import (
 	"github.com/api7/cloud-cli/internal/commands"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
 	"github.com/api7/cloud-cli/internal/utils"
 )
 
@@ -83,6 +84,7 @@ cloud-cli stop kubernetes \
 			if err != nil {
 				output.Errorf(err.Error())
 			}
+			forgetDeployment(persistence.DeploymentKubernetes, options.Global.Stop.Name, opts.NameSpace)
 		},
 	}
 
@@ -23,6 +23,7 @@ import (
 	"github.com/api7/cloud-cli/internal/consts"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
 	"github.com/api7/cloud-cli/internal/types"
 	"github.com/api7/cloud-cli/internal/utils"
 )
@@ -83,3 +84,14 @@ func deleteOnKubernetes(kubectl commands.Cmd, k types.K8sResourceKind) error {
 
 	return nil
 }
+
+// forgetDeployment removes the deployment from the local registry once the
+// APISIX instances are removed.
+func forgetDeployment(target persistence.DeploymentTarget, name, namespace string) {
+	if options.Global.DryRun {
+		return
+	}
+	if err := persistence.RemoveDeployment(target, name, namespace); err != nil {
+		output.Warnf("Failed to remove the deployment from the registry: %s", err)
+	}
+}
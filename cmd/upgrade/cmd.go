@@ -0,0 +1,34 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+// NewCommand creates the upgrade sub-command object.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade [COMMAND] [ARG...]",
+		Short: "Upgrade Apache APISIX instances deployed by the Cloud CLI.",
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Upgrade.Name, "name", "apisix", "The identifier of the deployment, it would be the container name (on Docker)")
+
+	cmd.AddCommand(newDockerCommand())
+	return cmd
+}
@@ -0,0 +1,307 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/apisix"
+	"github.com/api7/cloud-cli/internal/container"
+	"github.com/api7/cloud-cli/internal/options"
+	"github.com/api7/cloud-cli/internal/output"
+	"github.com/api7/cloud-cli/internal/persistence"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+const (
+	_configTarget   = "/usr/local/apisix/conf/config.yaml"
+	_apisixIDTarget = "/usr/local/apisix/conf/apisix.uid"
+	// _defaultHTTPContainerPort is the HTTP port of APISIX if node_listen
+	// is not configured.
+	_defaultHTTPContainerPort = "9080"
+)
+
+var (
+	// _readinessInterval is the interval to probe the new container.
+	_readinessInterval = time.Second
+)
+
+func newDockerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docker [ARGS...]",
+		Short: "Upgrade Apache APISIX deployed on Docker",
+		Long: `Upgrade Apache APISIX deployed by "cloud-cli deploy docker" to a new image.
+The new container reuses the APISIX instance ID, the configuration and the mounts
+of the current one. If it doesn't become ready in time, the current container is
+restored.`,
+		Example: `
+cloud-cli upgrade docker \
+		--name apisix \
+		--apisix-image apache/apisix:3.2.0-centos`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if err := options.Global.Upgrade.Docker.Validate(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+			if err := persistence.Init(); err != nil {
+				output.Errorf(err.Error())
+				return
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			go utils.WaitForSignal(func() {
+				cancel()
+			})
+
+			if err := upgradeDocker(ctx, options.Global.Upgrade.Name); err != nil {
+				output.Errorf(err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.Global.Upgrade.Docker.APISIXImage, "apisix-image", "", "Specify the new Apache APISIX image")
+	cmd.PersistentFlags().StringVar(&options.Global.Upgrade.Docker.DockerCLIPath, "docker-cli-path", "", "Specify the filepath of the docker command (or the podman and nerdctl command if it was deployed by them)")
+	cmd.PersistentFlags().DurationVar(&options.Global.Upgrade.Docker.Timeout, "timeout", time.Minute, "Specify how long to wait for the new container to be ready before rolling back")
+
+	return cmd
+}
+
+func upgradeDocker(ctx context.Context, name string) error {
+	opts := options.Global.Upgrade.Docker
+	deployment, err := findDockerDeployment(name)
+	if err != nil {
+		return err
+	}
+	runtime, err := container.ParseRuntime(deployment.Runtime)
+	if err != nil {
+		return err
+	}
+	image := runtime.QualifyImage(opts.APISIXImage)
+
+	runArgs, httpPort, err := prepareRunArgs(ctx, runtime, deployment)
+	if err != nil {
+		return err
+	}
+
+	if _, err = runDocker(ctx, runtime, "pull", image); err != nil {
+		return errors.Wrap(err, "failed to pull the new image")
+	}
+
+	// Keep the current container until the new one is ready, so that it
+	// can be restored.
+	previous := name + "-previous"
+	_, _ = runDocker(ctx, runtime, "rm", "-f", previous)
+	if _, err = runDocker(ctx, runtime, "stop", name); err != nil {
+		return errors.Wrap(err, "failed to stop the current container")
+	}
+	if _, err = runDocker(ctx, runtime, "rename", name, previous); err != nil {
+		_, _ = runDocker(ctx, runtime, "start", name)
+		return errors.Wrap(err, "failed to rename the current container")
+	}
+
+	runArgs = append(append([]string{"run"}, runArgs...), image)
+	if _, err = runDocker(ctx, runtime, runArgs...); err == nil && !options.Global.DryRun {
+		err = waitForReady(ctx, runtime, name, httpHostPort(runArgs, httpPort), opts.Timeout)
+	}
+	if err != nil {
+		output.Warnf("The new container is not ready: %s, rolling back", err)
+		if rollbackErr := rollback(ctx, runtime, name, previous); rollbackErr != nil {
+			return errors.Wrapf(rollbackErr, "failed to roll back to the previous container %s", previous)
+		}
+		return errors.Errorf("failed to upgrade %s, rolled back to %s: %s", name, deployment.Image, err)
+	}
+
+	if _, err = runDocker(ctx, runtime, "rm", "-f", previous); err != nil {
+		output.Warnf("Failed to remove the previous container %s: %s", previous, err)
+	}
+
+	if !options.Global.DryRun {
+		deployment.Image = image
+		deployment.RunArgs = runArgs[1 : len(runArgs)-1]
+		deployment.DeployedAt = time.Now()
+		if err = persistence.SaveDeployment(deployment); err != nil {
+			output.Warnf("Failed to record the deployment: %s", err)
+		}
+	}
+	fmt.Printf("Congratulations! Your APISIX instance %s was upgraded to %s\n", name, image)
+	return nil
+}
+
+func findDockerDeployment(name string) (*persistence.Deployment, error) {
+	deployments, err := persistence.LoadDeployments()
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		if deployment.Target == persistence.DeploymentDocker && deployment.Name == name {
+			if deployment.ComposeFile != "" {
+				return nil, errors.Errorf("%s is managed by Docker Compose, please update the image in %s and run docker compose up instead", name, deployment.ComposeFile)
+			}
+			if len(deployment.RunArgs) == 0 {
+				return nil, errors.Errorf("the docker run arguments of %s are not recorded, please redeploy it by cloud-cli deploy docker", name)
+			}
+			return deployment, nil
+		}
+	}
+	return nil, errors.Errorf("deployment docker/%s is not found, please deploy it by cloud-cli deploy docker first", name)
+}
+
+// prepareRunArgs returns the recorded docker run arguments, while the
+// configuration and the APISIX ID are saved to dedicated files, since the
+// configuration was saved in a temporary file and the APISIX ID file is
+// shared by all the deployments. The HTTP port that APISIX listens on in the
+// container is also returned.
+func prepareRunArgs(ctx context.Context, runtime container.Runtime, deployment *persistence.Deployment) ([]string, string, error) {
+	dir := filepath.Join(persistence.APISIXConfigDir, deployment.ClusterID.String())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, "", errors.Wrap(err, "failed to create apisix config directory")
+	}
+
+	runArgs := append([]string{}, deployment.RunArgs...)
+	configFile := filepath.Join(dir, deployment.Name+"-config.yaml")
+	if _, err := runDocker(ctx, runtime, "cp", deployment.Name+":"+_configTarget, configFile); err != nil {
+		return nil, "", errors.Wrap(err, "failed to copy the configuration from the current container")
+	}
+	if !options.Global.DryRun {
+		// Processes inside the container should be able to read it.
+		if err := os.Chmod(configFile, 0644); err != nil {
+			return nil, "", errors.Wrap(err, "change configuration file permission")
+		}
+	}
+	runArgs = container.ReplaceBindMountSource(runArgs, _configTarget, configFile)
+	httpPort := httpContainerPort(configFile)
+
+	if len(deployment.APISIXIDs) > 0 {
+		idFile := filepath.Join(persistence.HomeDir, "uid", deployment.Name+".uid")
+		if !options.Global.DryRun {
+			if err := os.MkdirAll(filepath.Dir(idFile), 0755); err != nil {
+				return nil, "", errors.Wrap(err, "failed to create APISIX instance ID directory")
+			}
+			if err := os.WriteFile(idFile, []byte(deployment.APISIXIDs[0]), 0644); err != nil {
+				return nil, "", errors.Wrap(err, "failed to save APISIX instance ID")
+			}
+		}
+		runArgs = container.ReplaceBindMountSource(runArgs, _apisixIDTarget, idFile)
+	}
+	return runArgs, httpPort, nil
+}
+
+// httpContainerPort returns the first port in the node_listen of the APISIX
+// configuration.
+func httpContainerPort(configFile string) string {
+	var config struct {
+		APISIX struct {
+			NodeListen []yaml.Node `yaml:"node_listen"`
+		} `yaml:"apisix"`
+	}
+	data, err := os.ReadFile(configFile)
+	if err != nil || yaml.Unmarshal(data, &config) != nil || len(config.APISIX.NodeListen) == 0 {
+		return _defaultHTTPContainerPort
+	}
+	// The listener is either a port or an object with the port field.
+	listener := config.APISIX.NodeListen[0]
+	if listener.Kind == yaml.MappingNode {
+		var entry struct {
+			Port string `yaml:"port"`
+		}
+		if listener.Decode(&entry) != nil || entry.Port == "" {
+			return _defaultHTTPContainerPort
+		}
+		return entry.Port
+	}
+	return listener.Value
+}
+
+// httpHostPort returns the host port which is mapped to the HTTP port of
+// APISIX, it's empty if the port is not published.
+func httpHostPort(args []string, httpPort string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] != "-p" && args[i] != "--publish" {
+			continue
+		}
+		mapping := strings.Split(args[i+1], ":")
+		if len(mapping) >= 2 && mapping[len(mapping)-1] == httpPort {
+			return mapping[len(mapping)-2]
+		}
+	}
+	return ""
+}
+
+// waitForReady waits until the container is running and the HTTP port
+// responds, any HTTP response means APISIX is serving.
+func waitForReady(ctx context.Context, runtime container.Runtime, name, port string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		docker := runtime.Command(options.Global.Upgrade.Docker.DockerCLIPath, false)
+		state, err := runtime.InspectState(ctx, docker, name)
+		if err != nil {
+			lastErr = err
+		} else if !state.Running() {
+			return errors.Errorf("the container is %s", state)
+		} else if port == "" {
+			return nil
+		} else if lastErr = apisix.Probe(ctx, "127.0.0.1:"+port, _readinessInterval); lastErr == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("timed out after %s: %s", timeout, lastErr)
+		case <-time.After(_readinessInterval):
+		}
+	}
+}
+
+func rollback(ctx context.Context, runtime container.Runtime, name, previous string) error {
+	_, _ = runDocker(ctx, runtime, "rm", "-f", name)
+	if _, err := runDocker(ctx, runtime, "rename", previous, name); err != nil {
+		return err
+	}
+	_, err := runDocker(ctx, runtime, "start", name)
+	return err
+}
+
+func runDocker(ctx context.Context, runtime container.Runtime, args ...string) (string, error) {
+	docker := runtime.Command(options.Global.Upgrade.Docker.DockerCLIPath, options.Global.DryRun)
+	docker.AppendArgs(args...)
+	if options.Global.DryRun {
+		output.Infof("Running:\n%s\n", docker.String())
+	} else {
+		output.Verbosef("Running:\n%s\n", docker.String())
+	}
+
+	stdout, stderr, err := docker.Run(ctx)
+	if err != nil {
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			return "", errors.Wrap(err, stderr)
+		}
+		return "", err
+	}
+	return stdout, nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/persistence"
+)
+
+// _fakeDocker logs the arguments, and fails to run the "broken" image.
+const _fakeDocker = `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/docker.log"
+case "$1" in
+cp) echo "apisix: {}" > "$3" ;;
+inspect) echo "running " ;;
+run)
+  for arg in "$@"; do
+    case "$arg" in
+    *broken*) echo "Error: failed to start container" >&2; exit 1 ;;
+    esac
+  done
+  ;;
+esac
+`
+
+func TestUpgradeDocker(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "cloud-cli-upgrade-test")
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err, "parse server url")
+	readyPort := u.Port()
+	// Nothing is listening on the port of a closed server.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	u, err = url.Parse(closed.URL)
+	assert.NoError(t, err, "parse server url")
+	unavailablePort := u.Port()
+
+	testCases := []struct {
+		name        string
+		args        []string
+		port        string
+		recorded    bool
+		composeFile string
+		runtime     string
+		image       string
+		outputs     []string
+		logs        []string
+	}{
+		{
+			name:     "upgrade successfully",
+			args:     []string{"docker", "--apisix-image", "apache/apisix:3.2.0-centos"},
+			port:     readyPort,
+			recorded: true,
+			image:    "apache/apisix:3.2.0-centos",
+			outputs:  []string{"Your APISIX instance apisix was upgraded to apache/apisix:3.2.0-centos"},
+			logs: []string{
+				"cp apisix:/usr/local/apisix/conf/config.yaml " + filepath.Join(dir, "apisix", "1", "apisix-config.yaml"),
+				"pull apache/apisix:3.2.0-centos",
+				"stop apisix",
+				"rename apisix apisix-previous",
+				"run --detach --mount type=bind,source=" + filepath.Join(dir, "apisix", "1", "apisix-config.yaml") + ",target=/usr/local/apisix/conf/config.yaml,readonly" +
+					" --mount type=bind,source=" + filepath.Join(dir, "uid", "apisix.uid") + ",target=/usr/local/apisix/conf/apisix.uid,readonly" +
+					" -p " + readyPort + ":9080 --name apisix --hostname apisix apache/apisix:3.2.0-centos",
+				"rm -f apisix-previous",
+			},
+		},
+		{
+			name:     "upgrade successfully with podman runtime",
+			args:     []string{"docker", "--apisix-image", "apache/apisix:3.2.0-centos"},
+			port:     readyPort,
+			recorded: true,
+			runtime:  "podman",
+			image:    "docker.io/apache/apisix:3.2.0-centos",
+			outputs:  []string{"Your APISIX instance apisix was upgraded to docker.io/apache/apisix:3.2.0-centos"},
+			logs: []string{
+				"pull docker.io/apache/apisix:3.2.0-centos",
+				"run --detach --volume " + filepath.Join(dir, "apisix", "1", "apisix-config.yaml") + ":/usr/local/apisix/conf/config.yaml:ro,Z" +
+					" --volume " + filepath.Join(dir, "uid", "apisix.uid") + ":/usr/local/apisix/conf/apisix.uid:ro,z" +
+					" -p " + readyPort + ":9080 --name apisix --hostname apisix docker.io/apache/apisix:3.2.0-centos",
+			},
+		},
+		{
+			name:     "roll back if the container fails to start",
+			args:     []string{"docker", "--apisix-image", "apache/apisix:broken"},
+			port:     readyPort,
+			recorded: true,
+			image:    "apache/apisix:2.15.0-centos",
+			outputs: []string{
+				"WARNING: The new container is not ready",
+				"ERROR: failed to upgrade apisix, rolled back to apache/apisix:2.15.0-centos",
+			},
+			logs: []string{"rename apisix apisix-previous", "rm -f apisix\n", "rename apisix-previous apisix", "start apisix"},
+		},
+		{
+			name:     "roll back if the container is not ready",
+			args:     []string{"docker", "--apisix-image", "apache/apisix:3.2.0-centos", "--timeout", "1s"},
+			port:     unavailablePort,
+			recorded: true,
+			outputs:  []string{"ERROR: failed to upgrade apisix, rolled back to apache/apisix:2.15.0-centos: timed out after 1s"},
+			logs:     []string{"rename apisix-previous apisix", "start apisix"},
+		},
+		{
+			name:        "refuse to upgrade the container managed by docker compose",
+			args:        []string{"docker", "--apisix-image", "apache/apisix:3.2.0-centos"},
+			port:        readyPort,
+			recorded:    true,
+			composeFile: "/srv/apisix/docker-compose.yaml",
+			image:       "apache/apisix:2.15.0-centos",
+			outputs:     []string{"ERROR: apisix is managed by Docker Compose, please update the image in /srv/apisix/docker-compose.yaml and run docker compose up instead"},
+		},
+		{
+			name:    "deployment not found",
+			args:    []string{"docker", "--apisix-image", "apache/apisix:3.2.0-centos"},
+			outputs: []string{"ERROR: deployment docker/apisix is not found"},
+		},
+		{
+			name:    "missing image",
+			args:    []string{"docker"},
+			outputs: []string{"ERROR: --apisix-image is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			persistence.HomeDir = dir
+			if os.Getenv("GO_TEST_SUBPROCESS") == "1" {
+				_readinessInterval = 100 * time.Millisecond
+				cmd := NewCommand()
+				cmd.SetArgs(append(tc.args, "--docker-cli-path", filepath.Join(dir, "docker")))
+				_ = cmd.Execute()
+				return
+			}
+
+			defer os.RemoveAll(dir)
+			assert.NoError(t, persistence.Init(), "init persistence")
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(_fakeDocker), 0755), "write fake docker")
+			if tc.recorded {
+				mounts := []string{
+					"--mount", "type=bind,source=/tmp/apisix-config-1.yaml,target=/usr/local/apisix/conf/config.yaml,readonly",
+					"--mount", "type=bind,source=/root/.api7cloud/apisix.uid,target=/usr/local/apisix/conf/apisix.uid,readonly",
+				}
+				if tc.runtime == "podman" {
+					mounts = []string{
+						"--volume", "/tmp/apisix-config-1.yaml:/usr/local/apisix/conf/config.yaml:ro,Z",
+						"--volume", "/root/.api7cloud/apisix.uid:/usr/local/apisix/conf/apisix.uid:ro,z",
+					}
+				}
+				deployment := &persistence.Deployment{
+					Name:      "apisix",
+					Target:    persistence.DeploymentDocker,
+					Image:     "apache/apisix:2.15.0-centos",
+					APISIXIDs: []string{"apisix-id"},
+					ClusterID: 1,
+					Runtime:   tc.runtime,
+					RunArgs: append(append([]string{"--detach"}, mounts...),
+						"-p", tc.port+":9080",
+						"--name", "apisix",
+						"--hostname", "apisix",
+					),
+				}
+				if tc.composeFile != "" {
+					deployment.RunArgs = nil
+					deployment.ComposeFile = tc.composeFile
+				}
+				assert.NoError(t, persistence.SaveDeployment(deployment), "save deployment")
+			}
+
+			cmd := exec.Command(os.Args[0], fmt.Sprintf("-test.run=^%s$", t.Name()))
+			cmd.Env = append(os.Environ(), "GO_TEST_SUBPROCESS=1")
+			output, _ := cmd.CombinedOutput()
+			for _, out := range tc.outputs {
+				assert.Contains(t, string(output), out, "check output")
+			}
+
+			logs, _ := os.ReadFile(filepath.Join(dir, "docker.log"))
+			for _, log := range tc.logs {
+				assert.Contains(t, string(logs), log, "check docker commands")
+			}
+			if tc.image != "" {
+				deployments, err := persistence.LoadDeployments()
+				assert.NoError(t, err, "load deployments")
+				assert.Equal(t, tc.image, deployments[0].Image, "check the recorded image")
+			}
+		})
+	}
+}
+
+func TestHTTPContainerPort(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name: "missing config",
+			want: "9080",
+		},
+		{
+			name:   "no node_listen",
+			config: "apisix: {}",
+			want:   "9080",
+		},
+		{
+			name:   "port",
+			config: "apisix:\n  node_listen:\n    - 8080\n",
+			want:   "8080",
+		},
+		{
+			name:   "listener object",
+			config: "apisix:\n  node_listen:\n    - port: 8081\n      enable_http2: true\n",
+			want:   "8081",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if tc.config != "" {
+				assert.NoError(t, os.WriteFile(configFile, []byte(tc.config), 0644), "write config")
+			}
+			assert.Equal(t, tc.want, httpContainerPort(configFile), "checking http container port")
+			assert.Equal(t, "80", httpHostPort([]string{"-p", "80:" + tc.want, "-p", "443:9443"}, tc.want), "checking http host port")
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package apisix
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 
 	"github.com/imdario/mergo"
@@ -80,3 +82,14 @@ func SaveConfig(config map[string]interface{}, filepath string) error {
 	}
 	return nil
 }
+
+// ConfigHash returns the SHA-256 checksum of the config, keys are sorted
+// when marshaling so the same config always has the same checksum.
+func ConfigHash(config map[string]interface{}) (string, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal config")
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -223,3 +223,27 @@ func TestSaveConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigHash(t *testing.T) {
+	t.Parallel()
+
+	hash1, err := ConfigHash(map[string]interface{}{
+		"apisix":     map[string]interface{}{"enable_admin": false, "node_listen": 9080},
+		"deployment": map[string]interface{}{"role": "traditional"},
+	})
+	assert.Nil(t, err, "check if err is nil")
+	assert.Len(t, hash1, 64, "check hash length")
+
+	hash2, err := ConfigHash(map[string]interface{}{
+		"deployment": map[string]interface{}{"role": "traditional"},
+		"apisix":     map[string]interface{}{"node_listen": 9080, "enable_admin": false},
+	})
+	assert.Nil(t, err, "check if err is nil")
+	assert.Equal(t, hash1, hash2, "check if the hash is stable")
+
+	hash3, err := ConfigHash(map[string]interface{}{
+		"apisix": map[string]interface{}{"enable_admin": true, "node_listen": 9080},
+	})
+	assert.Nil(t, err, "check if err is nil")
+	assert.NotEqual(t, hash1, hash3, "check if the hash changes")
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apisix
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// PIDFile is the PID file of the APISIX master process deployed on
+	// bare metal.
+	PIDFile = "/usr/local/apisix/logs/nginx.pid"
+)
+
+// MasterProcess returns the PID of the APISIX master process on bare metal
+// and whether it's running. It's not running if the PID file doesn't exist.
+func MasterProcess() (int, bool, error) {
+	data, err := os.ReadFile(PIDFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "failed to read APISIX pid file")
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "invalid APISIX pid file %s", PIDFile)
+	}
+	process, err := os.FindProcess(pid)
+	if err == nil {
+		// Signal 0 only checks if the process exists, EPERM means it
+		// exists but is owned by another user (e.g. root).
+		if err = process.Signal(syscall.Signal(0)); errors.Is(err, syscall.EPERM) {
+			err = nil
+		}
+	}
+	return pid, err == nil, nil
+}
+
+// Probe sends a request to the APISIX data plane, any HTTP response means
+// APISIX is serving.
+func Probe(ctx context.Context, address string, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apisix
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasterProcess(t *testing.T) {
+	pidFile := PIDFile
+	defer func() {
+		PIDFile = pidFile
+	}()
+	PIDFile = filepath.Join(t.TempDir(), "nginx.pid")
+
+	_, running, err := MasterProcess()
+	assert.NoError(t, err, "check the error without pid file")
+	assert.False(t, running, "check if it's running without pid file")
+
+	assert.NoError(t, os.WriteFile(PIDFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644), "write pid file")
+	pid, running, err := MasterProcess()
+	assert.NoError(t, err, "check the error")
+	assert.True(t, running, "check if it's running")
+	assert.Equal(t, os.Getpid(), pid, "check the pid")
+
+	assert.NoError(t, os.WriteFile(PIDFile, []byte("abc"), 0644), "write invalid pid file")
+	_, _, err = MasterProcess()
+	assert.ErrorContains(t, err, "invalid APISIX pid file", "check the error of invalid pid file")
+}
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	address := strings.TrimPrefix(server.URL, "http://")
+	assert.NoError(t, Probe(context.Background(), address, time.Second), "any response means serving")
+
+	server.Close()
+	assert.Error(t, Probe(context.Background(), address, time.Second), "nothing is listening")
+}
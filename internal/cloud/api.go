@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -72,16 +73,32 @@ func (a *api) GetTLSBundle(clusterID cloud.ID) (*cloud.TLSBundle, error) {
 	return a.sdk.GenerateGatewaySideCertificate(context.TODO(), clusterID, nil)
 }
 
-func (a *api) GetCloudLuaModule() ([]byte, error) {
+func (a *api) GetCloudLuaModule(etag string) (*CloudLuaModule, error) {
 	req, err := a.newRequest(http.MethodGet, a.cloudLuaModuleURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+
+	module := &CloudLuaModule{
+		ETag: resp.Header.Get("ETag"),
+		URL:  a.cloudLuaModuleURL.String(),
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		module.NotModified = true
+		if module.ETag == "" {
+			module.ETag = etag
+		}
+		return module, nil
+	}
+
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read response body")
@@ -89,7 +106,46 @@ func (a *api) GetCloudLuaModule() ([]byte, error) {
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.Errorf("unexpected response code: %d, message: %s", resp.StatusCode, string(data))
 	}
-	return data, nil
+	module.Data = data
+
+	if module.Checksum, err = a.getCloudLuaModuleChecksum(); err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// getCloudLuaModuleChecksum downloads the SHA-256 checksum of the cloud lua
+// module, which is in the sha256sum format, an empty string is returned if
+// the checksum is not published.
+func (a *api) getCloudLuaModuleChecksum() (string, error) {
+	checksumURL := *a.cloudLuaModuleURL
+	checksumURL.Path += ".sha256"
+	checksumURL.RawPath = ""
+	req, err := a.newRequest(http.MethodGet, &checksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to download the checksum of the cloud lua module")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read response body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected response code: %d, message: %s", resp.StatusCode, string(data))
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", errors.New("empty checksum of the cloud lua module")
+	}
+	return strings.ToLower(fields[0]), nil
 }
 
 func (a *api) GetStartupConfig(clusterID cloud.ID, configType StartupConfigType) (string, error) {
@@ -101,6 +157,10 @@ func (a *api) GetStartupConfig(clusterID cloud.ID, configType StartupConfigType)
 }
 
 func (a *api) GetDefaultOrganization() (*cloud.Organization, error) {
+	if a.org != "" {
+		return a.getSelectedOrganization()
+	}
+
 	user, err := a.Me()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to access user information")
@@ -113,18 +173,105 @@ func (a *api) GetDefaultOrganization() (*cloud.Organization, error) {
 	return a.sdk.GetOrganization(context.TODO(), user.OrgIDs[0], nil)
 }
 
-func (a *api) GetDefaultCluster() (*cloud.Cluster, error) {
+func (a *api) ListOrganizations() ([]*cloud.Organization, error) {
 	user, err := a.Me()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to access user information")
 	}
 
+	orgs := make([]*cloud.Organization, 0, len(user.OrgIDs))
+	for _, id := range user.OrgIDs {
+		org, err := a.sdk.GetOrganization(context.TODO(), id, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get organization %s", id)
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+// getSelectedOrganization finds the selected organization by ID or name in
+// the user's organizations, it's cached since the selection won't change.
+func (a *api) getSelectedOrganization() (*cloud.Organization, error) {
+	if a.selectedOrg != nil {
+		return a.selectedOrg, nil
+	}
+
+	user, err := a.Me()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to access user information")
+	}
+	for _, id := range user.OrgIDs {
+		if id.String() == a.org {
+			org, err := a.sdk.GetOrganization(context.TODO(), id, nil)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get organization %s", id)
+			}
+			a.selectedOrg = org
+			return org, nil
+		}
+	}
+
+	orgs, err := a.ListOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	var matched []*cloud.Organization
+	for _, org := range orgs {
+		if org.Name == a.org {
+			matched = append(matched, org)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, errors.Wrapf(ErrNotFound, "organization \"%s\"", a.org)
+	case 1:
+		a.selectedOrg = matched[0]
+		return a.selectedOrg, nil
+	default:
+		return nil, errors.Wrapf(ErrAmbiguousName, "organization \"%s\" matches %d organizations", a.org, len(matched))
+	}
+}
+
+func (a *api) SelectOrganization(org string) {
+	a.org = org
+	a.selectedOrg = nil
+	// Clusters are looked up in the organization.
+	a.selectedCluster = nil
+}
+
+// defaultOrgID returns the ID of the selected organization, or the first
+// organization of the current user.
+func (a *api) defaultOrgID() (cloud.ID, error) {
+	if a.org != "" {
+		org, err := a.getSelectedOrganization()
+		if err != nil {
+			return 0, err
+		}
+		return org.ID, nil
+	}
+
+	user, err := a.Me()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to access user information")
+	}
 	if len(user.OrgIDs) == 0 {
-		return nil, errors.New("incomplete user information, no organization")
+		return 0, errors.New("incomplete user information, no organization")
+	}
+	return user.OrgIDs[0], nil
+}
+
+func (a *api) GetDefaultCluster() (*cloud.Cluster, error) {
+	orgID, err := a.defaultOrgID()
+	if err != nil {
+		return nil, err
+	}
+	if a.cluster != "" {
+		return a.getSelectedCluster(orgID)
 	}
 	iter, err := a.sdk.ListClusters(context.TODO(), &cloud.ResourceListOptions{
 		Organization: &cloud.Organization{
-			ID: user.OrgIDs[0],
+			ID: orgID,
 		},
 	})
 	if err != nil {
@@ -143,14 +290,51 @@ func (a *api) GetDefaultCluster() (*cloud.Cluster, error) {
 	return cluster, nil
 }
 
+// getSelectedCluster finds the selected cluster by ID or name in the
+// organization, it's cached since the selection won't change.
+func (a *api) getSelectedCluster(orgID cloud.ID) (*cloud.Cluster, error) {
+	if a.selectedCluster != nil {
+		return a.selectedCluster, nil
+	}
+
+	var matched []*cloud.Cluster
+	err := a.IterateClusters(orgID, nil, func(cluster *cloud.Cluster) error {
+		if cluster.ID.String() == a.cluster {
+			matched = []*cloud.Cluster{cluster}
+			return ErrStopIteration
+		}
+		if cluster.Name == a.cluster {
+			matched = append(matched, cluster)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch len(matched) {
+	case 0:
+		return nil, errors.Wrapf(ErrNotFound, "cluster \"%s\"", a.cluster)
+	case 1:
+		a.selectedCluster = matched[0]
+		return a.selectedCluster, nil
+	default:
+		return nil, errors.Wrapf(ErrAmbiguousName, "cluster \"%s\" matches %d clusters", a.cluster, len(matched))
+	}
+}
+
+func (a *api) SelectCluster(cluster string) {
+	a.cluster = cluster
+	a.selectedCluster = nil
+}
+
 func (a *api) GetClusterDetail(clusterID cloud.ID) (*cloud.Cluster, error) {
-	user, err := a.Me()
+	orgID, err := a.defaultOrgID()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to access user information")
+		return nil, err
 	}
 	cluster, err := a.sdk.GetCluster(context.TODO(), clusterID, &cloud.ResourceGetOptions{
 		Organization: &cloud.Organization{
-			ID: user.OrgIDs[0],
+			ID: orgID,
 		},
 	})
 	if err != nil {
@@ -160,6 +344,14 @@ func (a *api) GetClusterDetail(clusterID cloud.ID) (*cloud.Cluster, error) {
 	return cluster, nil
 }
 
+func (a *api) ListGatewayInstances(clusterID cloud.ID) ([]cloud.GatewayInstance, error) {
+	instances, err := a.sdk.ListAllGatewayInstances(context.TODO(), clusterID, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list gateway instances")
+	}
+	return instances, nil
+}
+
 func (a *api) GetSSL(clusterID, sslID cloud.ID) (*cloud.CertificateDetails, error) {
 	return a.sdk.GetCertificate(context.TODO(), sslID, &cloud.ResourceGetOptions{
 		Cluster: &cloud.Cluster{
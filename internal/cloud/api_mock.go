@@ -181,18 +181,18 @@ func (mr *MockAPIMockRecorder) DeleteService(clusterID, appID interface{}) *gomo
 }
 
 // GetCloudLuaModule mocks base method.
-func (m *MockAPI) GetCloudLuaModule() ([]byte, error) {
+func (m *MockAPI) GetCloudLuaModule(etag string) (*CloudLuaModule, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCloudLuaModule")
-	ret0, _ := ret[0].([]byte)
+	ret := m.ctrl.Call(m, "GetCloudLuaModule", etag)
+	ret0, _ := ret[0].(*CloudLuaModule)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCloudLuaModule indicates an expected call of GetCloudLuaModule.
-func (mr *MockAPIMockRecorder) GetCloudLuaModule() *gomock.Call {
+func (mr *MockAPIMockRecorder) GetCloudLuaModule(etag interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCloudLuaModule", reflect.TypeOf((*MockAPI)(nil).GetCloudLuaModule))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCloudLuaModule", reflect.TypeOf((*MockAPI)(nil).GetCloudLuaModule), etag)
 }
 
 // GetClusterDetail mocks base method.
@@ -330,6 +330,76 @@ func (mr *MockAPIMockRecorder) GetTLSBundle(clusterID interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTLSBundle", reflect.TypeOf((*MockAPI)(nil).GetTLSBundle), clusterID)
 }
 
+// IterateClusters mocks base method.
+func (m *MockAPI) IterateClusters(orgID cloud_go_sdk.ID, filter *cloud_go_sdk.Filter, fn func(*cloud_go_sdk.Cluster) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateClusters", orgID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateClusters indicates an expected call of IterateClusters.
+func (mr *MockAPIMockRecorder) IterateClusters(orgID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateClusters", reflect.TypeOf((*MockAPI)(nil).IterateClusters), orgID, filter, fn)
+}
+
+// IterateConsumers mocks base method.
+func (m *MockAPI) IterateConsumers(clusterID cloud_go_sdk.ID, filter *cloud_go_sdk.Filter, fn func(*cloud_go_sdk.Consumer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateConsumers", clusterID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateConsumers indicates an expected call of IterateConsumers.
+func (mr *MockAPIMockRecorder) IterateConsumers(clusterID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateConsumers", reflect.TypeOf((*MockAPI)(nil).IterateConsumers), clusterID, filter, fn)
+}
+
+// IterateRoutes mocks base method.
+func (m *MockAPI) IterateRoutes(clusterID, appID cloud_go_sdk.ID, filter *cloud_go_sdk.Filter, fn func(*cloud_go_sdk.API) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateRoutes", clusterID, appID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateRoutes indicates an expected call of IterateRoutes.
+func (mr *MockAPIMockRecorder) IterateRoutes(clusterID, appID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateRoutes", reflect.TypeOf((*MockAPI)(nil).IterateRoutes), clusterID, appID, filter, fn)
+}
+
+// IterateSSL mocks base method.
+func (m *MockAPI) IterateSSL(clusterID cloud_go_sdk.ID, filter *cloud_go_sdk.Filter, fn func(*cloud_go_sdk.CertificateDetails) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateSSL", clusterID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateSSL indicates an expected call of IterateSSL.
+func (mr *MockAPIMockRecorder) IterateSSL(clusterID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateSSL", reflect.TypeOf((*MockAPI)(nil).IterateSSL), clusterID, filter, fn)
+}
+
+// IterateServices mocks base method.
+func (m *MockAPI) IterateServices(clusterID cloud_go_sdk.ID, filter *cloud_go_sdk.Filter, fn func(*cloud_go_sdk.Application) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateServices", clusterID, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateServices indicates an expected call of IterateServices.
+func (mr *MockAPIMockRecorder) IterateServices(clusterID, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateServices", reflect.TypeOf((*MockAPI)(nil).IterateServices), clusterID, filter, fn)
+}
+
 // ListClusters mocks base method.
 func (m *MockAPI) ListClusters(orgID cloud_go_sdk.ID, limit, skip int) ([]*cloud_go_sdk.Cluster, error) {
 	m.ctrl.T.Helper()
@@ -360,6 +430,36 @@ func (mr *MockAPIMockRecorder) ListConsumers(clusterID, limit, skip interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsumers", reflect.TypeOf((*MockAPI)(nil).ListConsumers), clusterID, limit, skip)
 }
 
+// ListGatewayInstances mocks base method.
+func (m *MockAPI) ListGatewayInstances(clusterID cloud_go_sdk.ID) ([]cloud_go_sdk.GatewayInstance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGatewayInstances", clusterID)
+	ret0, _ := ret[0].([]cloud_go_sdk.GatewayInstance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGatewayInstances indicates an expected call of ListGatewayInstances.
+func (mr *MockAPIMockRecorder) ListGatewayInstances(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGatewayInstances", reflect.TypeOf((*MockAPI)(nil).ListGatewayInstances), clusterID)
+}
+
+// ListOrganizations mocks base method.
+func (m *MockAPI) ListOrganizations() ([]*cloud_go_sdk.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations")
+	ret0, _ := ret[0].([]*cloud_go_sdk.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockAPIMockRecorder) ListOrganizations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockAPI)(nil).ListOrganizations))
+}
+
 // ListRoutes mocks base method.
 func (m *MockAPI) ListRoutes(clusterID, appID cloud_go_sdk.ID, limit, skip int) ([]*cloud_go_sdk.API, error) {
 	m.ctrl.T.Helper()
@@ -420,6 +520,30 @@ func (mr *MockAPIMockRecorder) Me() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Me", reflect.TypeOf((*MockAPI)(nil).Me))
 }
 
+// SelectCluster mocks base method.
+func (m *MockAPI) SelectCluster(cluster string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SelectCluster", cluster)
+}
+
+// SelectCluster indicates an expected call of SelectCluster.
+func (mr *MockAPIMockRecorder) SelectCluster(cluster interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectCluster", reflect.TypeOf((*MockAPI)(nil).SelectCluster), cluster)
+}
+
+// SelectOrganization mocks base method.
+func (m *MockAPI) SelectOrganization(org string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SelectOrganization", org)
+}
+
+// SelectOrganization indicates an expected call of SelectOrganization.
+func (mr *MockAPIMockRecorder) SelectOrganization(org interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectOrganization", reflect.TypeOf((*MockAPI)(nil).SelectOrganization), org)
+}
+
 // UpdateConsumer mocks base method.
 func (m *MockAPI) UpdateConsumer(clusterID cloud_go_sdk.ID, consumer *cloud_go_sdk.Consumer) (*cloud_go_sdk.Consumer, error) {
 	m.ctrl.T.Helper()
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -246,6 +247,159 @@ func TestClusters(t *testing.T) {
 	}
 }
 
+func TestGetDefaultCluster(t *testing.T) {
+	tests := []struct {
+		name      string
+		cluster   string
+		wantID    sdk.ID
+		errReason string
+	}{
+		{
+			name:   "first cluster",
+			wantID: 1,
+		},
+		{
+			name:    "select cluster by name",
+			cluster: "production",
+			wantID:  2,
+		},
+		{
+			name:    "select cluster by id",
+			cluster: "1",
+			wantID:  1,
+		},
+		{
+			name:      "ambiguous cluster name",
+			cluster:   "dup",
+			errReason: `cluster "dup" matches 2 clusters`,
+		},
+		{
+			name:      "cluster not found",
+			cluster:   "unknown",
+			errReason: `cluster "unknown": not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var payload string
+				switch {
+				case req.URL.Path == "/api/v1/user/me":
+					payload = `{"id": "321", "org_ids": ["123"]}`
+				case req.URL.Path == "/api/v1/orgs/123/clusters" && req.URL.Query().Get("page") != "2":
+					payload = `{"list": [
+						{"id": "1", "name": "staging"},
+						{"id": "2", "name": "production"},
+						{"id": "3", "name": "dup"},
+						{"id": "4", "name": "dup"}
+					]}`
+				default:
+					payload = `{"list": []}`
+				}
+				_, err := rw.Write([]byte(fmt.Sprintf(`{"payload": %s, "status": {"code": 0, "message": "OK"}}`, payload)))
+				assert.NoError(t, err, "send mock response")
+			}))
+			defer server.Close()
+
+			api, err := newClient(server.URL, "test-token", false)
+			assert.NoError(t, err, "checking new cloud api client")
+			if tt.cluster != "" {
+				api.SelectCluster(tt.cluster)
+			}
+
+			cluster, err := api.GetDefaultCluster()
+			if tt.errReason != "" {
+				assert.Contains(t, err.Error(), tt.errReason, "checking error reason")
+			} else {
+				assert.NoError(t, err, "checking error")
+				assert.Equal(t, tt.wantID, cluster.ID, "checking cluster id")
+			}
+		})
+	}
+}
+
+func TestSelectOrganization(t *testing.T) {
+	tests := []struct {
+		name      string
+		org       string
+		cluster   string
+		wantOrgID sdk.ID
+		wantID    sdk.ID
+		errReason string
+	}{
+		{
+			name:      "first organization",
+			wantOrgID: 123,
+			wantID:    1,
+		},
+		{
+			name:      "select organization by name",
+			org:       "staging",
+			wantOrgID: 456,
+			wantID:    2,
+		},
+		{
+			name:      "select organization by id",
+			org:       "456",
+			cluster:   "default",
+			wantOrgID: 456,
+			wantID:    2,
+		},
+		{
+			name:      "organization not found",
+			org:       "unknown",
+			errReason: `organization "unknown": not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var payload string
+				switch {
+				case req.URL.Path == "/api/v1/user/me":
+					payload = `{"id": "321", "org_ids": ["123", "456"]}`
+				case req.URL.Path == "/api/v1/orgs/123":
+					payload = `{"id": "123", "name": "production"}`
+				case req.URL.Path == "/api/v1/orgs/456":
+					payload = `{"id": "456", "name": "staging"}`
+				case req.URL.Path == "/api/v1/orgs/123/clusters" && req.URL.Query().Get("page") != "2":
+					payload = `{"list": [{"id": "1", "name": "default"}]}`
+				case req.URL.Path == "/api/v1/orgs/456/clusters" && req.URL.Query().Get("page") != "2":
+					payload = `{"list": [{"id": "2", "name": "default"}]}`
+				default:
+					payload = `{"list": []}`
+				}
+				_, err := rw.Write([]byte(fmt.Sprintf(`{"payload": %s, "status": {"code": 0, "message": "OK"}}`, payload)))
+				assert.NoError(t, err, "send mock response")
+			}))
+			defer server.Close()
+
+			api, err := newClient(server.URL, "test-token", false)
+			assert.NoError(t, err, "checking new cloud api client")
+			if tt.org != "" {
+				api.SelectOrganization(tt.org)
+			}
+			if tt.cluster != "" {
+				api.SelectCluster(tt.cluster)
+			}
+
+			org, err := api.GetDefaultOrganization()
+			if tt.errReason != "" {
+				assert.Contains(t, err.Error(), tt.errReason, "checking error reason")
+				_, err = api.GetDefaultCluster()
+				assert.Contains(t, err.Error(), tt.errReason, "checking error reason")
+				return
+			}
+			assert.NoError(t, err, "checking error")
+			assert.Equal(t, tt.wantOrgID, org.ID, "checking organization id")
+
+			cluster, err := api.GetDefaultCluster()
+			assert.NoError(t, err, "checking error")
+			assert.Equal(t, tt.wantID, cluster.ID, "checking cluster id")
+		})
+	}
+}
+
 func TestGetTLSBundle(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -316,12 +470,95 @@ func TestGetTLSBundle(t *testing.T) {
 	}
 }
 
+func TestListGatewayInstances(t *testing.T) {
+	tests := []struct {
+		name      string
+		clusterID sdk.ID
+		code      int
+		body      string
+		want      []sdk.GatewayInstance
+		wantErr   bool
+		errReason string
+	}{
+		{
+			name:      "success",
+			code:      http.StatusOK,
+			clusterID: 1,
+			want: []sdk.GatewayInstance{
+				{
+					GatewayInstancePayload: sdk.GatewayInstancePayload{
+						ID:       "apisix-1",
+						Hostname: "apisix",
+					},
+					Status: sdk.GatewayInstanceHealthy,
+				},
+			},
+			body: `
+				{
+					"code": 0,
+					"payload": {
+						"list": [
+							{
+								"id": "apisix-1",
+								"hostname": "apisix",
+								"status": "Healthy"
+							}
+						],
+						"count": 1
+					}
+				}
+			`,
+		},
+		{
+			name:      "internal server error",
+			code:      http.StatusInternalServerError,
+			clusterID: 1,
+			body:      "internal server error",
+			errReason: "failed to list gateway instances: status code: 500, message: internal server error",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, req.URL.String(), fmt.Sprintf("/api/v1/clusters/%s/instances", tt.clusterID))
+				assert.Equal(t, req.Header.Get("Authorization"), "Bearer test-token")
+
+				rw.WriteHeader(tt.code)
+				if tt.body != "" {
+					write, err := rw.Write([]byte(tt.body))
+					assert.NoError(t, err, "send mock response")
+					assert.Equal(t, len(tt.body), write, "write mock response")
+				}
+			}))
+
+			defer server.Close()
+
+			api, err := newClient(server.URL, "test-token", false)
+			assert.NoError(t, err, "checking new cloud api client")
+
+			instances, err := api.ListGatewayInstances(tt.clusterID)
+
+			if tt.wantErr {
+				assert.Contains(t, err.Error(), tt.errReason, "checking error reason")
+			} else {
+				assert.NoError(t, err, "checking error")
+				assert.Equal(t, tt.want, instances, "check the gateway instances")
+			}
+		})
+	}
+}
+
 func TestGetCloudLuaModule(t *testing.T) {
 	testCases := []struct {
-		name        string
-		errorReason string
-		code        int
-		body        string
+		name         string
+		errorReason  string
+		etag         string
+		code         int
+		body         string
+		checksumCode int
+		checksumBody string
+		want         *CloudLuaModule
 	}{
 		{
 			name:        "bad code 400",
@@ -329,15 +566,59 @@ func TestGetCloudLuaModule(t *testing.T) {
 			code:        http.StatusBadRequest,
 		},
 		{
-			name: "success",
-			code: http.StatusOK,
-			body: "the lua module",
+			name:         "success",
+			code:         http.StatusOK,
+			body:         "the lua module",
+			checksumCode: http.StatusNotFound,
+			want: &CloudLuaModule{
+				Data: []byte("the lua module"),
+				ETag: `"v2"`,
+			},
+		},
+		{
+			name:         "success with checksum",
+			code:         http.StatusOK,
+			body:         "the lua module",
+			checksumCode: http.StatusOK,
+			checksumBody: "ABCDEF  cloud_module.tar.gz\n",
+			want: &CloudLuaModule{
+				Data:     []byte("the lua module"),
+				ETag:     `"v2"`,
+				Checksum: "abcdef",
+			},
+		},
+		{
+			name:         "bad checksum code 500",
+			errorReason:  "unexpected response code: 500, message: internal error",
+			code:         http.StatusOK,
+			body:         "the lua module",
+			checksumCode: http.StatusInternalServerError,
+			checksumBody: "internal error",
+		},
+		{
+			name: "not modified",
+			etag: `"v1"`,
+			code: http.StatusNotModified,
+			want: &CloudLuaModule{
+				NotModified: true,
+				ETag:        `"v1"`,
+			},
 		},
 	}
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if strings.HasSuffix(req.URL.Path, ".sha256") {
+					rw.WriteHeader(tc.checksumCode)
+					_, err := rw.Write([]byte(tc.checksumBody))
+					assert.NoError(t, err, "send mock response")
+					return
+				}
+				assert.Equal(t, tc.etag, req.Header.Get("If-None-Match"), "checking If-None-Match header")
+				if tc.code == http.StatusOK {
+					rw.Header().Set("ETag", `"v2"`)
+				}
 				rw.WriteHeader(tc.code)
 				if tc.body != "" {
 					write, err := rw.Write([]byte(tc.body))
@@ -348,19 +629,20 @@ func TestGetCloudLuaModule(t *testing.T) {
 
 			defer server.Close()
 
-			err := os.Setenv(consts.Api7CloudLuaModuleURL, server.URL+"/")
+			err := os.Setenv(consts.Api7CloudLuaModuleURL, server.URL+"/cloud_module.tar.gz")
 			assert.NoError(t, err, "checking env setup")
 
 			api, err := newClient(server.URL, "test-token", false)
 			assert.NoError(t, err, "checking new cloud api client")
 
-			data, err := api.GetCloudLuaModule()
+			module, err := api.GetCloudLuaModule(tc.etag)
 
 			if tc.errorReason != "" {
 				assert.Contains(t, err.Error(), tc.errorReason, "checking error reason")
 			} else {
 				assert.NoError(t, err, "checking error")
-				assert.Equal(t, tc.body, string(data), "check the lua module")
+				tc.want.URL = server.URL + "/cloud_module.tar.gz"
+				assert.Equal(t, tc.want, module, "check the lua module")
 			}
 		})
 	}
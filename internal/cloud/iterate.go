@@ -0,0 +1,138 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-go-sdk"
+)
+
+const (
+	// _iteratePageSize is the page size when walking through all the objects.
+	_iteratePageSize = 100
+)
+
+var (
+	// ErrStopIteration can be returned by the iteration callback to stop
+	// the iteration, it won't be returned by the Iterate methods.
+	ErrStopIteration = errors.New("stop iteration")
+)
+
+type iterator[T any] interface {
+	Next() (*T, error)
+}
+
+// iterate calls fn on each object of the iterator, pages are fetched lazily.
+func iterate[T any](kind string, iter iterator[T], err error, fn func(*T) error) error {
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s iterator", kind)
+	}
+	for {
+		obj, err := iter.Next()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get next %s", kind)
+		}
+		if obj == nil {
+			return nil
+		}
+		if err = fn(obj); err != nil {
+			if err == ErrStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Collect calls the iterate function and collects all the objects.
+func Collect[T any](iterate func(fn func(*T) error) error) ([]*T, error) {
+	var objs []*T
+	err := iterate(func(obj *T) error {
+		objs = append(objs, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+func firstPage() *cloud.Pagination {
+	return &cloud.Pagination{
+		Page:     1,
+		PageSize: _iteratePageSize,
+	}
+}
+
+func (a *api) IterateClusters(orgID cloud.ID, filter *cloud.Filter, fn func(*cloud.Cluster) error) error {
+	iter, err := a.sdk.ListClusters(context.TODO(), &cloud.ResourceListOptions{
+		Organization: &cloud.Organization{
+			ID: orgID,
+		},
+		Pagination: firstPage(),
+		Filter:     filter,
+	})
+	return iterate[cloud.Cluster]("cluster", iter, err, fn)
+}
+
+func (a *api) IterateServices(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.Application) error) error {
+	iter, err := a.sdk.ListApplications(context.TODO(), &cloud.ResourceListOptions{
+		Cluster: &cloud.Cluster{
+			ID: clusterID,
+		},
+		Pagination: firstPage(),
+		Filter:     filter,
+	})
+	return iterate[cloud.Application]("service", iter, err, fn)
+}
+
+func (a *api) IterateRoutes(clusterID cloud.ID, appID cloud.ID, filter *cloud.Filter, fn func(*cloud.API) error) error {
+	iter, err := a.sdk.ListAPIs(context.TODO(), &cloud.ResourceListOptions{
+		Cluster: &cloud.Cluster{
+			ID: clusterID,
+		},
+		Application: &cloud.Application{
+			ID: appID,
+		},
+		Pagination: firstPage(),
+		Filter:     filter,
+	})
+	return iterate[cloud.API]("route", iter, err, fn)
+}
+
+func (a *api) IterateConsumers(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.Consumer) error) error {
+	iter, err := a.sdk.ListConsumers(context.TODO(), &cloud.ResourceListOptions{
+		Cluster: &cloud.Cluster{
+			ID: clusterID,
+		},
+		Pagination: firstPage(),
+		Filter:     filter,
+	})
+	return iterate[cloud.Consumer]("consumer", iter, err, fn)
+}
+
+func (a *api) IterateSSL(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.CertificateDetails) error) error {
+	iter, err := a.sdk.ListCertificates(context.TODO(), &cloud.ResourceListOptions{
+		Cluster: &cloud.Cluster{
+			ID: clusterID,
+		},
+		Pagination: firstPage(),
+		Filter:     filter,
+	})
+	return iterate[cloud.CertificateDetails]("ssl", iter, err, fn)
+}
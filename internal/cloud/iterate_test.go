@@ -0,0 +1,84 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterateServices(t *testing.T) {
+	pages := map[string]string{
+		"1": `[{"id": "1", "name": "users"}, {"id": "2", "name": "orders"}]`,
+		"2": `[{"id": "3", "name": "payments"}]`,
+	}
+
+	tests := []struct {
+		name       string
+		filter     *sdk.Filter
+		stopAfter  int
+		wantSearch string
+		wantNames  []string
+	}{
+		{
+			name:      "walk through all pages",
+			wantNames: []string{"users", "orders", "payments"},
+		},
+		{
+			name:       "search on server side",
+			filter:     &sdk.Filter{Search: "users"},
+			wantSearch: "users",
+			wantNames:  []string{"users", "orders", "payments"},
+		},
+		{
+			name:      "stop iteration",
+			stopAfter: 1,
+			wantNames: []string{"users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, tt.wantSearch, req.URL.Query().Get("search"), "check search condition")
+				list, ok := pages[req.URL.Query().Get("page")]
+				if !ok {
+					list = "[]"
+				}
+				_, err := rw.Write([]byte(fmt.Sprintf(`{"payload": {"list": %s}, "status": {"code": 0, "message": "OK"}}`, list)))
+				assert.NoError(t, err, "send mock response")
+			}))
+			defer server.Close()
+
+			api, err := newClient(server.URL, "test-token", false)
+			assert.NoError(t, err, "checking new cloud api client")
+
+			var names []string
+			err = api.IterateServices(1, tt.filter, func(app *sdk.Application) error {
+				names = append(names, app.Name)
+				if tt.stopAfter > 0 && len(names) == tt.stopAfter {
+					return ErrStopIteration
+				}
+				return nil
+			})
+			assert.NoError(t, err, "checking error")
+			assert.Equal(t, tt.wantNames, names, "checking service names")
+		})
+	}
+}
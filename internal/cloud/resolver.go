@@ -0,0 +1,180 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-go-sdk"
+)
+
+var (
+	// ErrNotFound means no object has the given name.
+	ErrNotFound = errors.New("not found")
+	// ErrAmbiguousName means more than one object have the given name.
+	ErrAmbiguousName = errors.New("ambiguous name, please use the ID instead")
+
+	_resolver *Resolver
+)
+
+// Resolver resolves object names to IDs through the list APIs. Lookups are
+// cached, so an object is looked up at most once per invocation.
+type Resolver struct {
+	api            API
+	cache          map[string]cloud.ID
+	defaultCluster *cloud.Cluster
+}
+
+// NewResolver creates a resolver which looks up objects through the api.
+func NewResolver(api API) *Resolver {
+	return &Resolver{
+		api:   api,
+		cache: make(map[string]cloud.ID),
+	}
+}
+
+// DefaultResolver returns the resolver of the DefaultClient, it's shared in
+// the whole invocation.
+func DefaultResolver() *Resolver {
+	if _resolver == nil || _resolver.api != DefaultClient {
+		_resolver = NewResolver(DefaultClient)
+	}
+	return _resolver
+}
+
+// DefaultCluster returns the default cluster, it's fetched only once.
+func (r *Resolver) DefaultCluster() (*cloud.Cluster, error) {
+	if r.defaultCluster == nil {
+		cluster, err := r.api.GetDefaultCluster()
+		if err != nil {
+			return nil, err
+		}
+		r.defaultCluster = cluster
+	}
+	return r.defaultCluster, nil
+}
+
+// ResolveDefault is like Resolve, but it resolves clusters in the default
+// organization, and other objects in the default cluster.
+func (r *Resolver) ResolveDefault(kind string, name string, serviceID cloud.ID) (cloud.ID, error) {
+	if kind == "cluster" {
+		org, err := r.api.GetDefaultOrganization()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get the default organization")
+		}
+		return r.ResolveCluster(org.ID, name)
+	}
+	cluster, err := r.DefaultCluster()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get the default cluster")
+	}
+	return r.Resolve(cluster.ID, kind, name, serviceID)
+}
+
+// ResolveCluster returns the ID of the cluster with the given name in the
+// organization.
+func (r *Resolver) ResolveCluster(orgID cloud.ID, name string) (cloud.ID, error) {
+	return resolve(r, fmt.Sprintf("cluster/%s/%s", orgID, name), "cluster", name,
+		func(filter *cloud.Filter, fn func(*cloud.Cluster) error) error {
+			return r.api.IterateClusters(orgID, filter, fn)
+		},
+		func(c *cloud.Cluster) (cloud.ID, bool) { return c.ID, c.Name == name },
+	)
+}
+
+// Resolve returns the ID of the object with the given kind and name in the
+// cluster, kind can be "service", "route", "consumer" and "ssl". The name of
+// a SSL object is one of its SNIs, and the serviceID is only required for
+// routes.
+func (r *Resolver) Resolve(clusterID cloud.ID, kind string, name string, serviceID cloud.ID) (cloud.ID, error) {
+	key := fmt.Sprintf("%s/%s/%s/%s", kind, clusterID, serviceID, name)
+	switch kind {
+	case "service":
+		return resolve(r, key, kind, name,
+			func(filter *cloud.Filter, fn func(*cloud.Application) error) error {
+				return r.api.IterateServices(clusterID, filter, fn)
+			},
+			func(s *cloud.Application) (cloud.ID, bool) { return s.ID, s.Name == name },
+		)
+	case "route":
+		if serviceID == 0 {
+			return 0, errors.New("service is required to resolve the route name")
+		}
+		return resolve(r, key, kind, name,
+			func(filter *cloud.Filter, fn func(*cloud.API) error) error {
+				return r.api.IterateRoutes(clusterID, serviceID, filter, fn)
+			},
+			func(route *cloud.API) (cloud.ID, bool) { return route.ID, route.Name == name },
+		)
+	case "consumer":
+		return resolve(r, key, kind, name,
+			func(filter *cloud.Filter, fn func(*cloud.Consumer) error) error {
+				return r.api.IterateConsumers(clusterID, filter, fn)
+			},
+			func(c *cloud.Consumer) (cloud.ID, bool) { return c.ID, c.Name == name },
+		)
+	case "ssl":
+		return resolve(r, key, kind, name,
+			func(filter *cloud.Filter, fn func(*cloud.CertificateDetails) error) error {
+				// The search condition doesn't apply to SNIs.
+				return r.api.IterateSSL(clusterID, nil, fn)
+			},
+			func(c *cloud.CertificateDetails) (cloud.ID, bool) {
+				for _, sni := range c.SNIs {
+					if sni == name {
+						return c.ID, true
+					}
+				}
+				return c.ID, false
+			},
+		)
+	default:
+		return 0, fmt.Errorf("resolving %s by name is not supported", kind)
+	}
+}
+
+func resolve[T any](r *Resolver, key, kind, name string,
+	iterate func(filter *cloud.Filter, fn func(*T) error) error,
+	match func(*T) (cloud.ID, bool),
+) (cloud.ID, error) {
+	if id, ok := r.cache[key]; ok {
+		return id, nil
+	}
+
+	var ids []string
+	var id cloud.ID
+	err := iterate(&cloud.Filter{Search: name}, func(obj *T) error {
+		if objID, ok := match(obj); ok {
+			id = objID
+			ids = append(ids, objID.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to look up %s \"%s\"", kind, name)
+	}
+	switch len(ids) {
+	case 0:
+		return 0, errors.Wrapf(ErrNotFound, "%s \"%s\"", kind, name)
+	case 1:
+		r.cache[key] = id
+		return id, nil
+	default:
+		return 0, errors.Wrapf(ErrAmbiguousName, "%s \"%s\" matches %d objects (%s)", kind, name, len(ids), strings.Join(ids, ", "))
+	}
+}
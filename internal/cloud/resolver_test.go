@@ -0,0 +1,79 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func iterateServices(services ...*sdk.Application) func(sdk.ID, *sdk.Filter, func(*sdk.Application) error) error {
+	return func(_ sdk.ID, _ *sdk.Filter, fn func(*sdk.Application) error) error {
+		for _, svc := range services {
+			if err := fn(svc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestResolver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	api := NewMockAPI(ctrl)
+	resolver := NewResolver(api)
+
+	api.EXPECT().IterateServices(sdk.ID(1), &sdk.Filter{Search: "users"}, gomock.Any()).DoAndReturn(iterateServices(
+		&sdk.Application{ID: 2, ApplicationSpec: sdk.ApplicationSpec{Name: "users"}},
+		&sdk.Application{ID: 3, ApplicationSpec: sdk.ApplicationSpec{Name: "users-v2"}},
+	)).Times(1)
+	for i := 0; i < 2; i++ {
+		id, err := resolver.Resolve(1, "service", "users", 0)
+		assert.NoError(t, err, "check error")
+		assert.Equal(t, sdk.ID(2), id, "check service id")
+	}
+
+	api.EXPECT().IterateServices(sdk.ID(1), &sdk.Filter{Search: "orders"}, gomock.Any()).DoAndReturn(iterateServices(
+		&sdk.Application{ID: 4, ApplicationSpec: sdk.ApplicationSpec{Name: "orders"}},
+		&sdk.Application{ID: 5, ApplicationSpec: sdk.ApplicationSpec{Name: "orders"}},
+	))
+	_, err := resolver.Resolve(1, "service", "orders", 0)
+	assert.True(t, errors.Is(err, ErrAmbiguousName), "check if the name is ambiguous")
+	assert.Contains(t, err.Error(), `service "orders" matches 2 objects (4, 5)`, "check error message")
+
+	api.EXPECT().IterateSSL(sdk.ID(1), nil, gomock.Any()).DoAndReturn(
+		func(_ sdk.ID, _ *sdk.Filter, fn func(*sdk.CertificateDetails) error) error {
+			return fn(&sdk.CertificateDetails{ID: 6, SNIs: []string{"a.com", "b.com"}})
+		},
+	)
+	id, err := resolver.Resolve(1, "ssl", "b.com", 0)
+	assert.NoError(t, err, "check error")
+	assert.Equal(t, sdk.ID(6), id, "check ssl id")
+
+	api.EXPECT().IterateConsumers(sdk.ID(1), &sdk.Filter{Search: "jack"}, gomock.Any()).Return(nil)
+	_, err = resolver.Resolve(1, "consumer", "jack", 0)
+	assert.True(t, errors.Is(err, ErrNotFound), "check if the consumer is not found")
+	assert.EqualError(t, err, `consumer "jack": not found`, "check error message")
+
+	_, err = resolver.Resolve(1, "route", "list-users", 0)
+	assert.EqualError(t, err, "service is required to resolve the route name", "check error message")
+
+	_, err = resolver.Resolve(1, "plugin", "cors", 0)
+	assert.EqualError(t, err, "resolving plugin by name is not supported", "check error message")
+}
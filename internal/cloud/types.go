@@ -60,6 +60,22 @@ const (
 	HELM   StartupConfigType = "helm"
 )
 
+// CloudLuaModule is the Cloud Lua module package.
+type CloudLuaModule struct {
+	// Data is the package content (in the tar.gz format), it's empty if
+	// the module is not modified.
+	Data []byte
+	// NotModified indicates the module is not modified since the given ETag.
+	NotModified bool
+	// ETag is the entity tag of the package.
+	ETag string
+	// URL is the address where the module is downloaded from.
+	URL string
+	// Checksum is the SHA-256 checksum published along with the module
+	// (at <URL>.sha256), it's empty if the checksum is not published.
+	Checksum string
+}
+
 // API warp API7 Cloud REST API
 type API interface {
 	// Me returns the current user information
@@ -68,16 +84,30 @@ type API interface {
 	ListClusters(orgID cloud.ID, limit int, skip int) ([]*cloud.Cluster, error)
 	// GetTLSBundle gets the tls bundle used to communicate with API7 Cloud. returns the cluster with the given ID
 	GetTLSBundle(clusterID cloud.ID) (*cloud.TLSBundle, error)
-	// GetCloudLuaModule returns the Cloud Lua code (in the tar.gz format)
-	GetCloudLuaModule() ([]byte, error)
+	// GetCloudLuaModule returns the Cloud Lua code (in the tar.gz format), the
+	// module won't be downloaded again if its ETag is still the given one.
+	GetCloudLuaModule(etag string) (*CloudLuaModule, error)
 	// GetStartupConfig gets the startup configuration from API7 Cloud for deploy APISIX by specify config type.
 	GetStartupConfig(clusterID cloud.ID, configType StartupConfigType) (string, error)
-	// GetDefaultOrganization returns the default organization for the current user.
+	// GetDefaultOrganization returns the selected organization, or the first
+	// organization of the current user if no organization is selected.
 	GetDefaultOrganization() (*cloud.Organization, error)
-	// GetDefaultCluster returns the default cluster for the current organization.
+	// SelectOrganization selects the organization by name or ID, it'll be
+	// returned by GetDefaultOrganization, and clusters are looked up in it.
+	SelectOrganization(org string)
+	// ListOrganizations returns all the organizations of the current user.
+	ListOrganizations() ([]*cloud.Organization, error)
+	// GetDefaultCluster returns the selected cluster, or the first cluster of
+	// the current organization if no cluster is selected.
 	GetDefaultCluster() (*cloud.Cluster, error)
+	// SelectCluster selects the cluster by name or ID, it'll be returned by
+	// GetDefaultCluster.
+	SelectCluster(cluster string)
 	// GetClusterDetail returns the detail cluster for the specify cluster.
 	GetClusterDetail(clusterID cloud.ID) (*cloud.Cluster, error)
+	// ListGatewayInstances returns all the gateway instances (ever) connected
+	// to the cluster.
+	ListGatewayInstances(clusterID cloud.ID) ([]cloud.GatewayInstance, error)
 	// GetSSL returns the detail of the Certificate (SSL) object.
 	GetSSL(clusterID, sslID cloud.ID) (*cloud.CertificateDetails, error)
 	// DeleteSSL deletes the specified SSL object.
@@ -121,6 +151,22 @@ type API interface {
 	CreateRoute(clusterID cloud.ID, api *cloud.API) (*cloud.API, error)
 	// UpdateRoute return the configuration after the route update
 	UpdateRoute(clusterID cloud.ID, api *cloud.API) (*cloud.API, error)
+	// IterateClusters calls fn on each cluster in the organization, pages are
+	// fetched lazily, and the iteration stops once fn returns an error. The
+	// filter is optional, it filters out objects on the server side.
+	IterateClusters(orgID cloud.ID, filter *cloud.Filter, fn func(*cloud.Cluster) error) error
+	// IterateServices calls fn on each service in the cluster, pages are
+	// fetched lazily, and the iteration stops once fn returns an error.
+	IterateServices(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.Application) error) error
+	// IterateRoutes calls fn on each route of the service, pages are
+	// fetched lazily, and the iteration stops once fn returns an error.
+	IterateRoutes(clusterID cloud.ID, appID cloud.ID, filter *cloud.Filter, fn func(*cloud.API) error) error
+	// IterateConsumers calls fn on each consumer in the cluster, pages are
+	// fetched lazily, and the iteration stops once fn returns an error.
+	IterateConsumers(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.Consumer) error) error
+	// IterateSSL calls fn on each SSL object in the cluster, pages are
+	// fetched lazily, and the iteration stops once fn returns an error.
+	IterateSSL(clusterID cloud.ID, filter *cloud.Filter, fn func(*cloud.CertificateDetails) error) error
 }
 
 type api struct {
@@ -130,6 +176,12 @@ type api struct {
 	accessToken       string
 	httpClient        *http.Client
 	cloudLuaModuleURL *url.URL
+	// org is the name or ID of the selected organization.
+	org         string
+	selectedOrg *cloud.Organization
+	// cluster is the name or ID of the selected cluster.
+	cluster         string
+	selectedCluster *cloud.Cluster
 }
 
 var (
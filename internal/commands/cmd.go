@@ -17,6 +17,7 @@ package commands
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -76,3 +77,21 @@ func (c *cmd) Execute(ctx context.Context) error {
 	}
 	return err
 }
+
+func (c *cmd) Stream(ctx context.Context, stdout, stderr io.Writer) error {
+	defer func() {
+		c.args = nil
+	}()
+	if c.dryrun {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Env = os.Environ()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, c.name)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ package commands
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -95,6 +96,20 @@ func (mr *MockCmdMockRecorder) Run(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockCmd)(nil).Run), ctx)
 }
 
+// Stream mocks base method.
+func (m *MockCmd) Stream(ctx context.Context, stdout, stderr io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, stdout, stderr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockCmdMockRecorder) Stream(ctx, stdout, stderr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockCmd)(nil).Stream), ctx, stdout, stderr)
+}
+
 // String mocks base method.
 func (m *MockCmd) String() string {
 	m.ctrl.T.Helper()
@@ -17,6 +17,7 @@ package commands
 import (
 	"bytes"
 	"context"
+	"io"
 )
 
 // Cmd is the command constructor and runner.
@@ -29,6 +30,10 @@ type Cmd interface {
 	Run(ctx context.Context) (string, string, error)
 	// Execute launches the command and return error, and print stdout and stderr to console.
 	Execute(ctx context.Context) error
+	// Stream launches the command and writes the stdout and stderr to the
+	// given writers as soon as they're produced, it's useful for the long
+	// running commands like "docker logs --follow".
+	Stream(ctx context.Context, stdout, stderr io.Writer) error
 }
 
 // Cmd wraps the os/exec.Cmd object.
@@ -15,6 +15,7 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
@@ -63,3 +64,19 @@ func TestCmdDryRun(t *testing.T) {
 	assert.Empty(t, stdout, "check cmd stdout")
 	assert.Empty(t, stderr, "check cmd stderr")
 }
+
+func TestCmdStream(t *testing.T) {
+	cmd := New("sh", false)
+	cmd.AppendArgs("-c", "echo hello; echo world >&2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err := cmd.Stream(ctx, stdout, stderr)
+	assert.Nil(t, err, "check cmd stream error")
+	assert.Equal(t, "hello\n", stdout.String(), "check cmd stdout")
+	assert.Equal(t, "world\n", stderr.String(), "check cmd stderr")
+	assert.Equal(t, "sh ", cmd.String(), "check cmd args are reset")
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package container abstracts the container runtimes which are compatible
+// with the docker CLI, i.e. Docker, Podman and containerd (by nerdctl).
+package container
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/api7/cloud-cli/internal/commands"
+)
+
+// Runtime is a container runtime with a docker compatible CLI.
+type Runtime string
+
+const (
+	// Docker is the Docker runtime.
+	Docker Runtime = "docker"
+	// Podman is the Podman runtime, it's rootless if it's not run by root.
+	Podman Runtime = "podman"
+	// Nerdctl is the containerd runtime driven by nerdctl, it's rootless if
+	// it's not run by root.
+	Nerdctl Runtime = "nerdctl"
+)
+
+var (
+	// _unprivilegedPortStartFile contains the first port that can be bound
+	// by unprivileged users.
+	_unprivilegedPortStartFile = "/proc/sys/net/ipv4/ip_unprivileged_port_start"
+	_geteuid                   = os.Geteuid
+)
+
+// Runtimes returns the names of all the supported runtimes.
+func Runtimes() []string {
+	return []string{string(Docker), string(Podman), string(Nerdctl)}
+}
+
+// ParseRuntime parses the runtime name, an empty name means Docker.
+func ParseRuntime(name string) (Runtime, error) {
+	switch Runtime(name) {
+	case "", Docker:
+		return Docker, nil
+	case Podman, Nerdctl:
+		return Runtime(name), nil
+	default:
+		return "", fmt.Errorf("invalid container runtime %s, should be one of: %s", name, strings.Join(Runtimes(), ", "))
+	}
+}
+
+// Command creates the command of the runtime, the cliPath overrides the
+// default command name if it's not empty.
+func (r Runtime) Command(cliPath string, dryrun bool) commands.Cmd {
+	if cliPath == "" {
+		cliPath = string(r)
+	}
+	return commands.New(cliPath, dryrun)
+}
+
+// Rootless checks if the runtime runs containers without the root privilege.
+func (r Runtime) Rootless() bool {
+	return r != Docker && _geteuid() != 0
+}
+
+// BindMount returns the arguments to bind mount the source to the target.
+// The shared option decides the SELinux label on Podman, the source can be
+// shared by several containers if it's true, or it's private to the
+// container.
+func (r Runtime) BindMount(source, target string, readonly, shared bool) []string {
+	switch r {
+	case Podman:
+		opts := []string{"Z"}
+		if shared {
+			opts = []string{"z"}
+		}
+		if readonly {
+			opts = append([]string{"ro"}, opts...)
+		}
+		return []string{"--volume", source + ":" + target + ":" + strings.Join(opts, ",")}
+	case Nerdctl:
+		volume := source + ":" + target
+		if readonly {
+			volume += ":ro"
+		}
+		return []string{"--volume", volume}
+	default:
+		mount := "type=bind,source=" + source + ",target=" + target
+		if readonly {
+			mount += ",readonly"
+		}
+		return []string{"--mount", mount}
+	}
+}
+
+// ReplaceBindMountSource replaces the source of the bind mount to the
+// target, both the --mount and the --volume syntax are supported.
+func ReplaceBindMountSource(args []string, target, source string) []string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if (arg == "--volume" || arg == "-v") && i+1 < len(args) {
+			fields := strings.Split(args[i+1], ":")
+			if len(fields) >= 2 && fields[1] == target {
+				fields[0] = source
+				args[i+1] = strings.Join(fields, ":")
+			}
+			i++
+			continue
+		}
+		if !strings.Contains(arg, "target="+target) {
+			continue
+		}
+		fields := strings.Split(arg, ",")
+		for j, field := range fields {
+			if strings.HasPrefix(field, "source=") {
+				fields[j] = "source=" + source
+			}
+		}
+		args[i] = strings.Join(fields, ",")
+	}
+	return args
+}
+
+// CheckHostPort checks if the host port can be published, rootless runtimes
+// cannot publish the privileged ports.
+func (r Runtime) CheckHostPort(port int) error {
+	if !r.Rootless() {
+		return nil
+	}
+	start := 1024
+	if data, err := os.ReadFile(_unprivilegedPortStartFile); err == nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			start = value
+		}
+	}
+	if port < start {
+		return fmt.Errorf("rootless %s cannot publish the privileged port %d, please use a port not less than %d, or allow it by: sysctl net.ipv4.ip_unprivileged_port_start=%d", r, port, start, port)
+	}
+	return nil
+}
+
+// QualifyImage returns the fully qualified image name, since Podman might
+// ask which registry to pull the short name image from.
+func (r Runtime) QualifyImage(image string) string {
+	if r != Podman {
+		return image
+	}
+	if i := strings.IndexByte(image, '/'); i > 0 {
+		domain := image[:i]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			return image
+		}
+	} else if i < 0 {
+		image = "library/" + image
+	}
+	return "docker.io/" + image
+}
+
+// StateFormat returns the inspect format template which prints the state and
+// the health status (if any) of the container.
+func (r Runtime) StateFormat() string {
+	if r == Nerdctl {
+		// nerdctl doesn't report the health status.
+		return "{{.State.Status}}"
+	}
+	return "{{.State.Status}} {{if .State.Health}}{{.State.Health.Status}}{{end}}"
+}
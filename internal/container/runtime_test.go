@@ -0,0 +1,151 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRuntime(t *testing.T) {
+	for name, want := range map[string]Runtime{
+		"":        Docker,
+		"docker":  Docker,
+		"podman":  Podman,
+		"nerdctl": Nerdctl,
+	} {
+		runtime, err := ParseRuntime(name)
+		assert.NoError(t, err, "parse runtime %s", name)
+		assert.Equal(t, want, runtime, "parse runtime %s", name)
+	}
+
+	_, err := ParseRuntime("crio")
+	assert.EqualError(t, err, "invalid container runtime crio, should be one of: docker, podman, nerdctl")
+}
+
+func TestBindMount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		runtime  Runtime
+		readonly bool
+		shared   bool
+		want     []string
+	}{
+		{
+			name:     "docker",
+			runtime:  Docker,
+			readonly: true,
+			want:     []string{"--mount", "type=bind,source=/src,target=/dst,readonly"},
+		},
+		{
+			name:    "docker read write",
+			runtime: Docker,
+			want:    []string{"--mount", "type=bind,source=/src,target=/dst"},
+		},
+		{
+			name:     "podman private",
+			runtime:  Podman,
+			readonly: true,
+			want:     []string{"--volume", "/src:/dst:ro,Z"},
+		},
+		{
+			name:     "podman shared",
+			runtime:  Podman,
+			readonly: true,
+			shared:   true,
+			want:     []string{"--volume", "/src:/dst:ro,z"},
+		},
+		{
+			name:    "podman read write",
+			runtime: Podman,
+			want:    []string{"--volume", "/src:/dst:Z"},
+		},
+		{
+			name:     "nerdctl",
+			runtime:  Nerdctl,
+			readonly: true,
+			shared:   true,
+			want:     []string{"--volume", "/src:/dst:ro"},
+		},
+		{
+			name:    "nerdctl read write",
+			runtime: Nerdctl,
+			want:    []string{"--volume", "/src:/dst"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.runtime.BindMount("/src", "/dst", tc.readonly, tc.shared))
+		})
+	}
+}
+
+func TestReplaceBindMountSource(t *testing.T) {
+	args := []string{
+		"--detach",
+		"--mount", "type=bind,source=/tmp/config.yaml,target=/usr/local/apisix/conf/config.yaml,readonly",
+		"--volume", "/tmp/apisix.uid:/usr/local/apisix/conf/apisix.uid:ro,z",
+		"-v", "/tmp/cache:/usr/local/apisix/conf/apisix.data",
+	}
+	args = ReplaceBindMountSource(args, "/usr/local/apisix/conf/config.yaml", "/new/config.yaml")
+	args = ReplaceBindMountSource(args, "/usr/local/apisix/conf/apisix.uid", "/new/apisix.uid")
+	args = ReplaceBindMountSource(args, "/usr/local/apisix/conf/apisix.data", "/new/cache")
+	assert.Equal(t, []string{
+		"--detach",
+		"--mount", "type=bind,source=/new/config.yaml,target=/usr/local/apisix/conf/config.yaml,readonly",
+		"--volume", "/new/apisix.uid:/usr/local/apisix/conf/apisix.uid:ro,z",
+		"-v", "/new/cache:/usr/local/apisix/conf/apisix.data",
+	}, args)
+}
+
+func TestCheckHostPort(t *testing.T) {
+	defer func(geteuid func() int, file string) {
+		_geteuid = geteuid
+		_unprivilegedPortStartFile = file
+	}(_geteuid, _unprivilegedPortStartFile)
+
+	_unprivilegedPortStartFile = filepath.Join(t.TempDir(), "ip_unprivileged_port_start")
+	assert.NoError(t, os.WriteFile(_unprivilegedPortStartFile, []byte("1024\n"), 0644), "write port start")
+
+	_geteuid = func() int { return 0 }
+	assert.NoError(t, Podman.CheckHostPort(80), "root podman can publish the privileged port")
+
+	_geteuid = func() int { return 1000 }
+	assert.NoError(t, Docker.CheckHostPort(80), "docker is not rootless")
+	assert.NoError(t, Podman.CheckHostPort(8080), "rootless podman can publish the unprivileged port")
+	assert.EqualError(t, Nerdctl.CheckHostPort(80), "rootless nerdctl cannot publish the privileged port 80, please use a port not less than 1024, or allow it by: sysctl net.ipv4.ip_unprivileged_port_start=80")
+
+	assert.NoError(t, os.WriteFile(_unprivilegedPortStartFile, []byte("80\n"), 0644), "write port start")
+	assert.NoError(t, Podman.CheckHostPort(80), "the privileged ports are allowed")
+}
+
+func TestQualifyImage(t *testing.T) {
+	for image, want := range map[string]string{
+		"apache/apisix:2.15.0-centos":       "docker.io/apache/apisix:2.15.0-centos",
+		"nginx":                             "docker.io/library/nginx",
+		"docker.io/apache/apisix":           "docker.io/apache/apisix",
+		"quay.io/apisix/apisix:3.2.0":       "quay.io/apisix/apisix:3.2.0",
+		"localhost/apisix:dev":              "localhost/apisix:dev",
+		"registry.local:5000/apisix:2.15.0": "registry.local:5000/apisix:2.15.0",
+	} {
+		assert.Equal(t, want, Podman.QualifyImage(image), "qualify image %s", image)
+		assert.Equal(t, image, Docker.QualifyImage(image), "docker keeps the image %s", image)
+		assert.Equal(t, image, Nerdctl.QualifyImage(image), "nerdctl keeps the image %s", image)
+	}
+}
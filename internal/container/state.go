@@ -0,0 +1,103 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/commands"
+)
+
+// ErrNotFound means the container doesn't exist.
+var ErrNotFound = errors.New("container is not found")
+
+// State is the state of a container.
+type State struct {
+	// Status is the container status, e.g. running, restarting and exited.
+	Status string
+	// Health is the health status, it's empty if the container has no
+	// health check, or it's run by nerdctl which doesn't report it.
+	Health string
+}
+
+// ParseState parses the output of the inspect command with the format
+// returned by StateFormat.
+func ParseState(stdout string) *State {
+	fields := strings.Fields(stdout)
+	state := &State{}
+	if len(fields) > 0 {
+		state.Status = fields[0]
+	}
+	if len(fields) > 1 {
+		state.Health = fields[1]
+	}
+	return state
+}
+
+// InspectState inspects the state of the container by the cmd, which should
+// be created by the Command method of the runtime. ErrNotFound is returned if
+// the container doesn't exist.
+func (r Runtime) InspectState(ctx context.Context, cmd commands.Cmd, name string) (*State, error) {
+	cmd.AppendArgs("inspect", "--format", r.StateFormat(), name)
+	stdout, stderr, err := cmd.Run(ctx)
+	if err != nil {
+		// The error messages are "No such object" on Docker, while they're
+		// in lower case on Podman and nerdctl.
+		if strings.Contains(strings.ToLower(stderr), "no such") {
+			return nil, ErrNotFound
+		}
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			return nil, errors.New(stderr)
+		}
+		return nil, err
+	}
+	return ParseState(stdout), nil
+}
+
+// Running checks if the container is running.
+func (s *State) Running() bool {
+	return s.Status == "running"
+}
+
+// Healthy checks if the container is running and healthy, the container
+// without the health status is healthy as long as it's running.
+func (s *State) Healthy() bool {
+	return s.Running() && (s.Health == "" || s.Health == "healthy")
+}
+
+// Starting checks if the container is running but the health check is not
+// passed yet.
+func (s *State) Starting() bool {
+	return s.Running() && s.Health == "starting"
+}
+
+// Exited checks if the container has stopped and won't be restarted.
+func (s *State) Exited() bool {
+	return s.Status == "exited" || s.Status == "dead"
+}
+
+// String returns the status, and the health status if it's not healthy.
+func (s *State) String() string {
+	if s.Status == "" {
+		return "unknown"
+	}
+	if s.Health == "" || s.Health == "healthy" {
+		return s.Status
+	}
+	return s.Status + " (" + s.Health + ")"
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/commands"
+)
+
+func TestParseState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stdout   string
+		state    string
+		healthy  bool
+		starting bool
+		exited   bool
+	}{
+		{name: "running", stdout: "running \n", state: "running", healthy: true},
+		{name: "healthy", stdout: "running healthy\n", state: "running", healthy: true},
+		{name: "starting", stdout: "running starting\n", state: "running (starting)", starting: true},
+		{name: "unhealthy", stdout: "running unhealthy\n", state: "running (unhealthy)"},
+		{name: "restarting", stdout: "restarting\n", state: "restarting"},
+		{name: "exited", stdout: "exited \n", state: "exited", exited: true},
+		{name: "dead", stdout: "dead\n", state: "dead", exited: true},
+		{name: "empty", stdout: "", state: "unknown"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			state := ParseState(tc.stdout)
+			assert.Equal(t, tc.state, state.String(), "check the state")
+			assert.Equal(t, tc.healthy, state.Healthy(), "check if it's healthy")
+			assert.Equal(t, tc.starting, state.Starting(), "check if it's starting")
+			assert.Equal(t, tc.exited, state.Exited(), "check if it has exited")
+		})
+	}
+}
+
+func TestInspectState(t *testing.T) {
+	testCases := []struct {
+		name        string
+		runtime     Runtime
+		stdout      string
+		stderr      string
+		err         error
+		state       string
+		expectedErr string
+	}{
+		{
+			name:    "docker",
+			runtime: Docker,
+			stdout:  "running healthy\n",
+			state:   "running",
+		},
+		{
+			name:    "nerdctl",
+			runtime: Nerdctl,
+			stdout:  "exited\n",
+			state:   "exited",
+		},
+		{
+			name:        "not found",
+			runtime:     Podman,
+			stderr:      "Error: no such container apisix\n",
+			err:         errors.New("exit status 125"),
+			expectedErr: "container is not found",
+		},
+		{
+			name:        "other errors",
+			runtime:     Docker,
+			stderr:      "Cannot connect to the Docker daemon\n",
+			err:         errors.New("exit status 1"),
+			expectedErr: "Cannot connect to the Docker daemon",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := commands.NewMockCmd(gomock.NewController(t))
+			cmd.EXPECT().AppendArgs("inspect", "--format", tc.runtime.StateFormat(), "apisix")
+			cmd.EXPECT().Run(gomock.Any()).Return(tc.stdout, tc.stderr, tc.err)
+
+			state, err := tc.runtime.InspectState(context.Background(), cmd, "apisix")
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr, "check the error")
+				return
+			}
+			assert.NoError(t, err, "check the error")
+			assert.Equal(t, tc.state, state.String(), "check the state")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+)
+
+var (
+	// ErrRedacted means the object cannot be written to API7 Cloud as
+	// the SSL certificate or private key is redacted.
+	ErrRedacted = errors.New("certificate and private key are redacted")
+)
+
+// Create creates the object on API7 Cloud, the ID of the object will
+// be filled with the one of the created object.
+func Create(api cloud.API, clusterID sdk.ID, obj *Object) (interface{}, error) {
+	if obj.Redacted() {
+		return nil, ErrRedacted
+	}
+	var (
+		created interface{}
+		id      sdk.ID
+		err     error
+	)
+	switch obj.Kind {
+	case KindService:
+		var svc *sdk.Application
+		if svc, err = api.CreateService(clusterID, obj.Service); err == nil {
+			created, id = svc, svc.ID
+		}
+	case KindRoute:
+		var route *sdk.API
+		if route, err = api.CreateRoute(clusterID, obj.Route); err == nil {
+			created, id = route, route.ID
+		}
+	case KindConsumer:
+		var consumer *sdk.Consumer
+		if consumer, err = api.CreateConsumer(clusterID, obj.Consumer); err == nil {
+			created, id = consumer, consumer.ID
+		}
+	case KindSSL:
+		var ssl *sdk.CertificateDetails
+		if ssl, err = api.CreateSSL(clusterID, obj.SSL); err == nil {
+			created, id = ssl, ssl.ID
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", obj)
+	}
+	obj.SetID(id)
+	return created, nil
+}
+
+// Update updates the object on API7 Cloud, the ID of the object
+// should be set.
+func Update(api cloud.API, clusterID sdk.ID, obj *Object) (interface{}, error) {
+	if obj.Redacted() {
+		return nil, ErrRedacted
+	}
+	var (
+		updated interface{}
+		err     error
+	)
+	switch obj.Kind {
+	case KindService:
+		updated, err = api.UpdateService(clusterID, obj.Service)
+	case KindRoute:
+		updated, err = api.UpdateRoute(clusterID, obj.Route)
+	case KindConsumer:
+		updated, err = api.UpdateConsumer(clusterID, obj.Consumer)
+	case KindSSL:
+		updated, err = api.UpdateSSL(clusterID, obj.SSL)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update %s", obj)
+	}
+	return updated, nil
+}
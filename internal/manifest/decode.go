@@ -0,0 +1,276 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// _idFields are the fields which carry API7 Cloud IDs, the SDK only
+	// accepts IDs in string format, so numeric values are converted.
+	_idFields = []string{"id", "app_id", "cluster_id"}
+)
+
+// Load loads objects from the given files, directories or tar.gz archives.
+// Files in directories and archives are loaded if they have the .yaml, .yml
+// or .json extension. "-" means the standard input.
+func Load(paths ...string) ([]*Object, error) {
+	var objects []*Object
+	for _, path := range paths {
+		if path == "-" {
+			objs, err := Decode(os.Stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read manifest")
+		}
+		if isArchive(path) {
+			objs, err := loadArchive(path)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+		files := []string{path}
+		if info.IsDir() {
+			if files, err = listManifestFiles(path); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			objs, err := loadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+		}
+	}
+	return objects, nil
+}
+
+// Decode decodes objects from the reader, the content can be one or
+// multiple YAML documents (separated by "---") or JSON, each document
+// is either an object or a list of objects.
+func Decode(r io.Reader, source string) ([]*Object, error) {
+	var (
+		objects []*Object
+		index   int
+	)
+
+	decoder := yaml.NewDecoder(r)
+	for {
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, errors.Wrapf(err, "failed to decode %s", source)
+		}
+
+		var docs []interface{}
+		switch v := doc.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			docs = v
+		default:
+			docs = []interface{}{v}
+		}
+
+		for _, d := range docs {
+			index++
+			m, ok := d.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("%s#%d: object should be a mapping", source, index)
+			}
+			obj, err := decodeObject(m)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s#%d", source, index)
+			}
+			obj.Source = fmt.Sprintf("%s#%d", source, index)
+			objects = append(objects, obj)
+		}
+	}
+}
+
+// SortByDependency sorts objects so that the depended objects are
+// in front of the others, the original order is kept for objects of
+// the same kind.
+func SortByDependency(objects []*Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return _kindOrder[objects[i].Kind] < _kindOrder[objects[j].Kind]
+	})
+}
+
+func decodeObject(m map[string]interface{}) (obj *Object, err error) {
+	defer func() {
+		// The SDK panics when decoding IDs which are not strings.
+		if r := recover(); r != nil {
+			obj = nil
+			err = errors.Errorf("invalid object: %v, note IDs should be quoted strings", r)
+		}
+	}()
+
+	kind, _ := m["kind"].(string)
+	kind = strings.ToLower(kind)
+	if _, ok := _kindOrder[kind]; !ok {
+		if kind == "" {
+			return nil, errors.New("kind is required")
+		}
+		return nil, errors.Errorf("unsupported kind %q", kind)
+	}
+	delete(m, "kind")
+
+	obj = &Object{Kind: kind}
+	if kind == KindRoute {
+		if name, ok := m["service"].(string); ok {
+			obj.ServiceName = name
+		}
+		delete(m, "service")
+	}
+
+	for _, field := range _idFields {
+		v, ok := m[field]
+		if !ok {
+			continue
+		}
+		if v == nil {
+			delete(m, field)
+		} else if _, isString := v.(string); !isString {
+			m[field] = fmt.Sprint(v)
+		}
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode object")
+	}
+
+	var target interface{}
+	switch kind {
+	case KindService:
+		target = &obj.Service
+	case KindRoute:
+		target = &obj.Route
+	case KindConsumer:
+		target = &obj.Consumer
+	case KindSSL:
+		target = &obj.SSL
+	}
+	if err = json.Unmarshal(data, target); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", kind)
+	}
+	// Keep the JSON representation so that numbers are compared
+	// with the live objects in the same type.
+	if err = json.Unmarshal(data, &obj.fields); err != nil {
+		return nil, errors.Wrap(err, "failed to decode fields")
+	}
+	return obj, nil
+}
+
+func loadFile(filename string) ([]*Object, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+	defer f.Close()
+
+	return Decode(f, filename)
+}
+
+func loadArchive(filename string) ([]*Object, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read archive")
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read archive %s", filename)
+	}
+	defer gr.Close()
+
+	var objects []*Object
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read archive %s", filename)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isManifestFile(hdr.Name) {
+			continue
+		}
+		objs, err := Decode(tr, filename+":"+hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, objs...)
+	}
+}
+
+func isArchive(filename string) bool {
+	return strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".tgz")
+}
+
+func isManifestFile(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func listManifestFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if isManifestFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to walk directory %s", dir)
+	}
+	return files, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		content     string
+		kinds       []string
+		names       []string
+		errorReason string
+	}{
+		{
+			name: "multiple yaml documents",
+			content: `
+kind: route
+name: list-users
+service: users
+paths:
+- path: /users
+  path_type: Exact
+---
+kind: Service
+name: users
+path_prefix: /v1
+upstreams:
+- scheme: http
+  targets:
+  - host: 10.0.0.1
+    port: 80
+    weight: 100
+---
+kind: consumer
+name: jack
+`,
+			kinds: []string{KindService, KindRoute, KindConsumer},
+			names: []string{"users", "list-users", "jack"},
+		},
+		{
+			name:    "json list",
+			content: `[{"kind": "consumer", "name": "jack", "id": 123}, {"kind": "service", "name": "users"}]`,
+			kinds:   []string{KindService, KindConsumer},
+			names:   []string{"users", "jack"},
+		},
+		{
+			name:        "missing kind",
+			content:     "name: users\n",
+			errorReason: "test#1: kind is required",
+		},
+		{
+			name:        "unknown kind",
+			content:     "kind: users\n---\nkind: plugin\n",
+			errorReason: `test#1: unsupported kind "users"`,
+		},
+		{
+			name:        "invalid document",
+			content:     "- foo\n",
+			errorReason: "test#1: object should be a mapping",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := Decode(strings.NewReader(tc.content), "test")
+			if tc.errorReason != "" {
+				assert.NotNil(t, err, "check if err is not nil")
+				assert.Contains(t, err.Error(), tc.errorReason, "check error reason")
+				return
+			}
+			assert.Nil(t, err, "check if err is nil")
+
+			SortByDependency(objects)
+			var (
+				kinds []string
+				names []string
+			)
+			for _, obj := range objects {
+				kinds = append(kinds, obj.Kind)
+				names = append(names, obj.Name())
+			}
+			assert.Equal(t, tc.kinds, kinds, "check kinds")
+			assert.Equal(t, tc.names, names, "check names")
+		})
+	}
+}
+
+func TestDecodeRoute(t *testing.T) {
+	t.Parallel()
+
+	objects, err := Decode(strings.NewReader("kind: route\nname: foo\nservice: users\nid: 123\n"), "test")
+	assert.Nil(t, err, "check if err is nil")
+	assert.Len(t, objects, 1, "check objects count")
+	assert.Equal(t, "users", objects[0].ServiceName, "check service name")
+	assert.Equal(t, sdk.ID(123), objects[0].ID(), "check id")
+	assert.Equal(t, "test#1", objects[0].Source, "check source")
+	assert.Equal(t, "route/foo", objects[0].String(), "check reference")
+}
+
+func TestUnchanged(t *testing.T) {
+	t.Parallel()
+
+	live := &sdk.Application{
+		ID: 1,
+		ApplicationSpec: sdk.ApplicationSpec{
+			Name:       "users",
+			PathPrefix: "/v1",
+			Hosts:      []string{"a.com"},
+			Labels:     []string{"team=payments"},
+		},
+		Status: sdk.Normal,
+	}
+
+	testCases := []struct {
+		name      string
+		content   string
+		unchanged bool
+	}{
+		{
+			name:      "subset of the live object",
+			content:   "kind: service\nname: users\nhosts: [a.com]\n",
+			unchanged: true,
+		},
+		{
+			name:      "different scalar",
+			content:   "kind: service\nname: users\npath_prefix: /v2\n",
+			unchanged: false,
+		},
+		{
+			name:      "different list length",
+			content:   "kind: service\nname: users\nhosts: [a.com, b.com]\n",
+			unchanged: false,
+		},
+		{
+			name:      "field missing in live object",
+			content:   "kind: service\nname: users\ndescription: user service\n",
+			unchanged: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := Decode(strings.NewReader(tc.content), "test")
+			assert.Nil(t, err, "check if err is nil")
+			unchanged, err := Unchanged(objects[0], live)
+			assert.Nil(t, err, "check if err is nil")
+			assert.Equal(t, tc.unchanged, unchanged, "check unchanged")
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+var (
+	// _serverManagedFields are the fields maintained by API7 Cloud,
+	// they are ignored when comparing objects.
+	_serverManagedFields = []string{
+		"id",
+		"cluster_id",
+		"app_id",
+		"status",
+		"created_at",
+		"updated_at",
+		"available_cert_ids",
+		"canary_release_id",
+		"canary_upstream_version_list",
+	}
+)
+
+// Diff returns the unified diff between the live object and the manifest,
+// the live object can be nil if it doesn't exist. Fields which are not
+// specified in the manifest keep the live values, and server-managed fields
+// are stripped. An empty string is returned if there are no differences.
+func Diff(obj *Object, live interface{}) (string, error) {
+	liveView := map[string]interface{}{}
+	if live != nil {
+		var err error
+		if liveView, err = toView(live); err != nil {
+			return "", err
+		}
+	}
+
+	desired, err := desiredFields(obj, live)
+	if err != nil {
+		return "", err
+	}
+	desiredView := merge(copyView(liveView), desired)
+
+	from, err := yaml.Marshal(liveView)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode live object")
+	}
+	to, err := yaml.Marshal(desiredView)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode local object")
+	}
+	if live == nil {
+		from = nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(from)),
+		B:        splitLines(string(to)),
+		FromFile: "live/" + obj.String(),
+		ToFile:   "local/" + obj.String(),
+		Context:  3,
+	})
+}
+
+// desiredFields returns the fields specified in the manifest, fields
+// which cannot be compared directly (e.g. the SSL certificate) are
+// converted to the live representation.
+func desiredFields(obj *Object, live interface{}) (map[string]interface{}, error) {
+	fields := copyView(obj.fields)
+	stripServerManagedFields(fields)
+	if obj.Kind != KindSSL {
+		return fields, nil
+	}
+
+	for _, field := range _sslContentFields {
+		delete(fields, field)
+	}
+	if obj.Redacted() {
+		return fields, nil
+	}
+	cert, err := utils.ParseCertificate([]byte(obj.SSL.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	snis := make([]interface{}, 0)
+	for _, sni := range utils.CertificateSNIs(cert) {
+		snis = append(snis, sni)
+	}
+	fields["snis"] = snis
+	fields["serial_number"] = cert.SerialNumber.Text(10)
+	if details, ok := live.(*sdk.CertificateDetails); ok {
+		if sameSerialNumber(details.SerialNumber, cert.SerialNumber.Text(10), cert.SerialNumber.Text(16)) {
+			// Keep the live format as they are the same number.
+			fields["serial_number"] = details.SerialNumber
+		}
+	}
+	return fields, nil
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func toView(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode live object")
+	}
+	var view map[string]interface{}
+	if err = json.Unmarshal(data, &view); err != nil {
+		return nil, errors.Wrap(err, "failed to decode live object")
+	}
+	stripServerManagedFields(view)
+	return view, nil
+}
+
+func stripServerManagedFields(view map[string]interface{}) {
+	for _, field := range _serverManagedFields {
+		delete(view, field)
+	}
+}
+
+// merge overrides the base with the patch recursively, lists are replaced
+// as a whole.
+func merge(base, patch map[string]interface{}) map[string]interface{} {
+	for k, v := range patch {
+		pm, ok := v.(map[string]interface{})
+		if !ok {
+			base[k] = v
+			continue
+		}
+		bm, ok := base[k].(map[string]interface{})
+		if !ok {
+			bm = map[string]interface{}{}
+		}
+		base[k] = merge(copyView(bm), pm)
+	}
+	return base
+}
+
+func copyView(view map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(view))
+	for k, v := range view {
+		c[k] = v
+	}
+	return c
+}
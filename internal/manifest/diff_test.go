@@ -0,0 +1,93 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	live := &sdk.Application{
+		ID:        1,
+		ClusterID: 2,
+		ApplicationSpec: sdk.ApplicationSpec{
+			Name:       "users",
+			PathPrefix: "/v1",
+			Hosts:      []string{"a.com"},
+		},
+		Status:    sdk.Normal,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name    string
+		content string
+		live    interface{}
+		diff    string
+	}{
+		{
+			name:    "no differences",
+			content: "kind: service\nid: \"1\"\nname: users\nhosts: [a.com]\n",
+			live:    live,
+		},
+		{
+			name:    "changed fields",
+			content: "kind: service\nname: users\nhosts: [a.com, b.com]\npath_prefix: /v2\n",
+			live:    live,
+			diff: `--- live/service/users
++++ local/service/users
+@@ -2,6 +2,7 @@
+ description: ""
+ hosts:
+     - a.com
++    - b.com
+ name: users
+-path_prefix: /v1
++path_prefix: /v2
+ upstreams: null
+`,
+		},
+		{
+			name:    "new object",
+			content: "kind: consumer\nname: jack\n",
+			diff: `--- live/consumer/jack
++++ local/consumer/jack
+@@ -0,0 +1 @@
++name: jack
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			objects, err := Decode(strings.NewReader(tc.content), "test")
+			assert.Nil(t, err, "check if err is nil")
+			diff, err := Diff(objects[0], tc.live)
+			assert.Nil(t, err, "check if err is nil")
+			assert.Equal(t, tc.diff, diff, "check diff")
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+)
+
+const (
+	// RedactedValue is the placeholder of the SSL certificate and private
+	// key in the exported manifests.
+	RedactedValue = "<redacted>"
+)
+
+var (
+	// _exportStrippedFields are the live fields which are not exported,
+	// IDs are kept so that objects can be matched when importing.
+	_exportStrippedFields = []string{
+		"cluster_id",
+		"status",
+		"created_at",
+		"updated_at",
+		"available_cert_ids",
+		"canary_release_id",
+		"canary_upstream_version_list",
+	}
+	// _exportSSLFields are the fields of SSL objects which are exported.
+	_exportSSLFields = []string{"id", "labels", "type", "snis"}
+	// _exportDirs are the directories of each kind in the export.
+	_exportDirs = map[string]string{
+		KindService:  "services",
+		KindRoute:    "routes",
+		KindConsumer: "consumers",
+		KindSSL:      "ssl",
+	}
+)
+
+// Document is an exported object.
+type Document struct {
+	// Kind is the object kind.
+	Kind string
+	// Path is the relative file path of the document, e.g. services/123.yaml.
+	Path string
+	// Data is the manifest content.
+	Data []byte
+}
+
+// Export fetches all the services, routes, consumers and SSL objects of
+// the cluster and encodes them to documents which can be loaded by Load.
+// API7 Cloud never returns the SSL certificate and private key, so they
+// are always redacted.
+func Export(api cloud.API, clusterID sdk.ID) ([]*Document, error) {
+	var docs []*Document
+
+	services, err := cloud.Collect(func(fn func(*sdk.Application) error) error {
+		return api.IterateServices(clusterID, nil, fn)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list services")
+	}
+	for _, svc := range services {
+		doc, err := newDocument(KindService, svc.ID, svc, nil)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+
+		routes, err := cloud.Collect(func(fn func(*sdk.API) error) error {
+			return api.IterateRoutes(clusterID, svc.ID, nil, fn)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list routes of service %s", svc.ID)
+		}
+		for _, route := range routes {
+			doc, err := newDocument(KindRoute, route.ID, route, nil)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	consumers, err := cloud.Collect(func(fn func(*sdk.Consumer) error) error {
+		return api.IterateConsumers(clusterID, nil, fn)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list consumers")
+	}
+	for _, consumer := range consumers {
+		doc, err := newDocument(KindConsumer, consumer.ID, consumer, nil)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	certs, err := cloud.Collect(func(fn func(*sdk.CertificateDetails) error) error {
+		return api.IterateSSL(clusterID, nil, fn)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ssl")
+	}
+	for _, cert := range certs {
+		doc, err := newDocument(KindSSL, cert.ID, cert, func(fields map[string]interface{}) map[string]interface{} {
+			ssl := map[string]interface{}{
+				"certificate": RedactedValue,
+				"private_key": RedactedValue,
+			}
+			for _, field := range _exportSSLFields {
+				if v, ok := fields[field]; ok {
+					ssl[field] = v
+				}
+			}
+			return ssl
+		})
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// WriteDirectory writes the documents to the directory, one file per document.
+func WriteDirectory(dir string, docs []*Document) error {
+	for _, doc := range docs {
+		filename := filepath.Join(dir, filepath.FromSlash(doc.Path))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create directory for %s", filename)
+		}
+		if err := os.WriteFile(filename, doc.Data, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", filename)
+		}
+	}
+	return nil
+}
+
+// WriteArchive writes the documents to a tar.gz archive.
+func WriteArchive(filename string, docs []*Document) (err error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create archive")
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "failed to close archive")
+		}
+	}()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+	for _, doc := range docs {
+		hdr := &tar.Header{
+			Name:    doc.Path,
+			Mode:    0600,
+			Size:    int64(len(doc.Data)),
+			ModTime: now,
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return errors.Wrapf(err, "failed to write %s to archive", doc.Path)
+		}
+		if _, err = tw.Write(doc.Data); err != nil {
+			return errors.Wrapf(err, "failed to write %s to archive", doc.Path)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close archive")
+	}
+	if err = gw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close archive")
+	}
+	return nil
+}
+
+func newDocument(kind string, id sdk.ID, obj interface{}, transform func(map[string]interface{}) map[string]interface{}) (*Document, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode %s %s", kind, id)
+	}
+	var fields map[string]interface{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s %s", kind, id)
+	}
+	for _, field := range _exportStrippedFields {
+		delete(fields, field)
+	}
+	if transform != nil {
+		fields = transform(fields)
+	}
+
+	body, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode %s %s", kind, id)
+	}
+	return &Document{
+		Kind: kind,
+		Path: path.Join(_exportDirs[kind], id.String()+".yaml"),
+		Data: append([]byte("kind: "+kind+"\n"), body...),
+	}, nil
+}
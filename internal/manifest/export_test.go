@@ -0,0 +1,123 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/testutils"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	api := cloud.NewMockAPI(ctrl)
+
+	api.EXPECT().IterateServices(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+		&sdk.Application{
+			ID:              2,
+			ClusterID:       1,
+			ApplicationSpec: sdk.ApplicationSpec{Name: "users", PathPrefix: "/v1"},
+			Status:          sdk.Normal,
+		},
+		&sdk.Application{
+			ID:              6,
+			ApplicationSpec: sdk.ApplicationSpec{Name: "orders"},
+		},
+	))
+	api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(2), nil, gomock.Any()).DoAndReturn(testutils.IterateRoutes(
+		&sdk.API{
+			ID:      3,
+			AppID:   2,
+			APISpec: sdk.APISpec{Name: "list-users", Methods: []string{"GET"}},
+		},
+	))
+	api.EXPECT().IterateRoutes(sdk.ID(1), sdk.ID(6), nil, gomock.Any()).Return(nil)
+	api.EXPECT().IterateConsumers(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+		&sdk.Consumer{ID: 4, Name: "jack"},
+	))
+	api.EXPECT().IterateSSL(sdk.ID(1), nil, gomock.Any()).DoAndReturn(testutils.Iterate(
+		&sdk.CertificateDetails{
+			ID:           5,
+			SNIs:         []string{"a.com"},
+			SerialNumber: "1234",
+			Labels:       []string{"prod"},
+			Type:         "Server",
+		},
+	))
+
+	docs, err := Export(api, 1)
+	assert.Nil(t, err, "check if err is nil")
+	assert.Len(t, docs, 5, "check documents count")
+
+	paths := make(map[string]string)
+	for _, doc := range docs {
+		paths[doc.Path] = string(doc.Data)
+	}
+	assert.Equal(t, `kind: service
+active: 0
+description: ""
+hosts: null
+id: "2"
+name: users
+path_prefix: /v1
+upstreams: null
+`, paths["services/2.yaml"], "check service document")
+	assert.Equal(t, `kind: ssl
+certificate: <redacted>
+id: "5"
+labels:
+    - prod
+private_key: <redacted>
+snis:
+    - a.com
+type: Server
+`, paths["ssl/5.yaml"], "check ssl document")
+	assert.Contains(t, paths, "routes/3.yaml", "check route document")
+	assert.Contains(t, paths, "consumers/4.yaml", "check consumer document")
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	assert.Nil(t, WriteArchive(archive, docs), "write archive")
+	dir := t.TempDir()
+	assert.Nil(t, WriteDirectory(dir, docs), "write directory")
+
+	for _, path := range []string{archive, dir} {
+		objects, err := Load(path)
+		assert.Nil(t, err, "check if err is nil")
+		assert.Len(t, objects, len(docs), "check objects count")
+
+		SortByDependency(objects)
+		var route, ssl *Object
+		for _, obj := range objects {
+			switch obj.Kind {
+			case KindRoute:
+				route = obj
+			case KindSSL:
+				ssl = obj
+			}
+		}
+		assert.Equal(t, sdk.ID(2), route.Route.AppID, "check route app id")
+		assert.Equal(t, "list-users", route.Name(), "check route name")
+		assert.True(t, ssl.Redacted(), "check if ssl is redacted")
+		assert.Equal(t, sdk.ID(5), ssl.ID(), "check ssl id")
+	}
+}
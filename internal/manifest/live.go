@@ -0,0 +1,292 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/utils"
+)
+
+var (
+	// ErrServiceNotFound means the service referred by the route doesn't exist.
+	ErrServiceNotFound = errors.New("service not found")
+
+	// _sslContentFields are the SSL fields which are never returned by
+	// API7 Cloud, so they cannot be compared with the live objects.
+	_sslContentFields = []string{"certificate", "private_key", "ca_certificate"}
+)
+
+// ResolveServiceID fills the app_id field of the route object with the ID
+// of the service referred by the ServiceName field.
+func ResolveServiceID(api cloud.API, clusterID sdk.ID, obj *Object) error {
+	if obj.Kind != KindRoute || obj.Route.AppID != 0 {
+		return nil
+	}
+	if obj.ServiceName == "" {
+		return errors.New("either app_id or service should be specified for route")
+	}
+
+	services, err := cloud.Collect(func(fn func(*sdk.Application) error) error {
+		return api.IterateServices(clusterID, nil, fn)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list services")
+	}
+
+	var matched []*sdk.Application
+	for _, svc := range services {
+		if svc.Name == obj.ServiceName {
+			matched = append(matched, svc)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return errors.Wrap(ErrServiceNotFound, obj.ServiceName)
+	case 1:
+		obj.Route.AppID = matched[0].ID
+		return nil
+	default:
+		return errors.Errorf("service name %s is ambiguous, %d services are found, please specify app_id", obj.ServiceName, len(matched))
+	}
+}
+
+// FindLive returns the live object on API7 Cloud corresponding to the
+// manifest object, nil is returned if the object doesn't exist. Objects
+// are matched by ID if it's specified, or by name (by SNIs for SSL),
+// and the ID of the object will be filled once it's matched.
+func FindLive(api cloud.API, clusterID sdk.ID, obj *Object) (interface{}, error) {
+	if id := obj.ID(); id != 0 {
+		return getLive(api, clusterID, obj, id)
+	}
+
+	var (
+		matched []interface{}
+		ids     []sdk.ID
+	)
+	switch obj.Kind {
+	case KindService:
+		services, err := cloud.Collect(func(fn func(*sdk.Application) error) error {
+			return api.IterateServices(clusterID, nil, fn)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list services")
+		}
+		for _, svc := range services {
+			if svc.Name == obj.Service.Name {
+				matched = append(matched, svc)
+				ids = append(ids, svc.ID)
+			}
+		}
+	case KindRoute:
+		routes, err := cloud.Collect(func(fn func(*sdk.API) error) error {
+			return api.IterateRoutes(clusterID, obj.Route.AppID, nil, fn)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list routes")
+		}
+		for _, route := range routes {
+			if route.Name == obj.Route.Name {
+				matched = append(matched, route)
+				ids = append(ids, route.ID)
+			}
+		}
+	case KindConsumer:
+		consumers, err := cloud.Collect(func(fn func(*sdk.Consumer) error) error {
+			return api.IterateConsumers(clusterID, nil, fn)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list consumers")
+		}
+		for _, consumer := range consumers {
+			if consumer.Name == obj.Consumer.Name {
+				matched = append(matched, consumer)
+				ids = append(ids, consumer.ID)
+			}
+		}
+	case KindSSL:
+		snis, err := sslSNIs(obj)
+		if err != nil {
+			return nil, err
+		}
+		certs, err := cloud.Collect(func(fn func(*sdk.CertificateDetails) error) error {
+			return api.IterateSSL(clusterID, nil, fn)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list ssl")
+		}
+		for _, c := range certs {
+			if sameSet(c.SNIs, snis) {
+				matched = append(matched, c)
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, nil
+	case 1:
+		obj.SetID(ids[0])
+		return matched[0], nil
+	default:
+		return nil, errors.Errorf("%s is ambiguous, %d objects are matched, please specify the id", obj, len(matched))
+	}
+}
+
+// Unchanged checks whether the live object is consistent with the manifest,
+// only fields specified in the manifest are compared.
+func Unchanged(obj *Object, live interface{}) (bool, error) {
+	fields := copyView(obj.fields)
+	stripServerManagedFields(fields)
+
+	if obj.Kind == KindSSL && !obj.Redacted() {
+		details, ok := live.(*sdk.CertificateDetails)
+		if !ok {
+			return false, errors.Errorf("unexpected live ssl object %T", live)
+		}
+		cert, err := utils.ParseCertificate([]byte(obj.SSL.Certificate))
+		if err != nil {
+			return false, err
+		}
+		if !sameSerialNumber(details.SerialNumber, cert.SerialNumber.Text(10), cert.SerialNumber.Text(16)) {
+			return false, nil
+		}
+	}
+	if obj.Kind == KindSSL {
+		for _, field := range _sslContentFields {
+			delete(fields, field)
+		}
+	}
+
+	data, err := json.Marshal(live)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to encode live object")
+	}
+	var liveFields interface{}
+	if err = json.Unmarshal(data, &liveFields); err != nil {
+		return false, errors.Wrap(err, "failed to decode live object")
+	}
+	return contains(liveFields, fields), nil
+}
+
+// sslSNIs returns the SNIs of the SSL object, they are parsed from the
+// certificate, or taken from the snis field if the certificate is redacted.
+func sslSNIs(obj *Object) ([]string, error) {
+	if obj.Redacted() {
+		var snis []string
+		list, _ := obj.fields["snis"].([]interface{})
+		for _, sni := range list {
+			if s, ok := sni.(string); ok {
+				snis = append(snis, s)
+			}
+		}
+		if len(snis) == 0 {
+			return nil, errors.New("certificate is redacted, either id or snis should be specified")
+		}
+		return snis, nil
+	}
+	cert, err := utils.ParseCertificate([]byte(obj.SSL.Certificate))
+	if err != nil {
+		return nil, err
+	}
+	return utils.CertificateSNIs(cert), nil
+}
+
+func getLive(api cloud.API, clusterID sdk.ID, obj *Object, id sdk.ID) (interface{}, error) {
+	var (
+		live interface{}
+		err  error
+	)
+	switch obj.Kind {
+	case KindService:
+		live, err = api.GetService(clusterID, id)
+	case KindRoute:
+		live, err = api.GetRoute(clusterID, obj.Route.AppID, id)
+	case KindConsumer:
+		live, err = api.GetConsumer(clusterID, id)
+	case KindSSL:
+		live, err = api.GetSSL(clusterID, id)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", obj)
+	}
+	return live, nil
+}
+
+// contains checks whether the desired value is a subset of the live value.
+func contains(live, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		for k, v := range d {
+			if !contains(l[k], v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		if len(l) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !contains(l[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return live == desired
+	}
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string(nil), a...)
+	y := append([]string(nil), b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSerialNumber(live string, candidates ...string) bool {
+	live = strings.ToLower(strings.ReplaceAll(live, ":", ""))
+	for _, c := range candidates {
+		if live == strings.ToLower(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	sdk "github.com/api7/cloud-go-sdk"
+)
+
+const (
+	// KindService is the kind of API7 Cloud services (applications).
+	KindService = "service"
+	// KindRoute is the kind of API7 Cloud routes (APIs).
+	KindRoute = "route"
+	// KindConsumer is the kind of API7 Cloud consumers.
+	KindConsumer = "consumer"
+	// KindSSL is the kind of API7 Cloud certificates.
+	KindSSL = "ssl"
+)
+
+var (
+	// _kindOrder is the dependency order of kinds, services should
+	// be handled before routes as routes refer to them.
+	_kindOrder = map[string]int{
+		KindService:  0,
+		KindRoute:    1,
+		KindConsumer: 2,
+		KindSSL:      3,
+	}
+)
+
+// Object is a resource definition in a manifest, only the field
+// corresponding to the Kind is set.
+type Object struct {
+	// Kind is the resource kind, it's one of service, route,
+	// consumer and ssl.
+	Kind string
+	// Source describes where the object comes from, e.g. services.yaml#2.
+	Source string
+
+	Service  *sdk.Application
+	Route    *sdk.API
+	Consumer *sdk.Consumer
+	SSL      *sdk.Certificate
+
+	// ServiceName is the name of the service that the route belongs to,
+	// it's used to find the service when the route doesn't specify
+	// the app_id field.
+	ServiceName string
+
+	// fields are the fields specified in the manifest, they are used to
+	// check whether the live object is consistent with the manifest.
+	fields map[string]interface{}
+}
+
+// ID returns the resource ID, it's zero if the manifest doesn't specify it.
+func (o *Object) ID() sdk.ID {
+	switch o.Kind {
+	case KindService:
+		return o.Service.ID
+	case KindRoute:
+		return o.Route.ID
+	case KindConsumer:
+		return o.Consumer.ID
+	case KindSSL:
+		return o.SSL.ID
+	}
+	return 0
+}
+
+// SetID sets the resource ID.
+func (o *Object) SetID(id sdk.ID) {
+	switch o.Kind {
+	case KindService:
+		o.Service.ID = id
+	case KindRoute:
+		o.Route.ID = id
+	case KindConsumer:
+		o.Consumer.ID = id
+	case KindSSL:
+		o.SSL.ID = id
+	}
+}
+
+// Name returns the resource name, SSL objects don't have names so the
+// ID is used for them, or the source if the ID is unknown yet.
+func (o *Object) Name() string {
+	switch o.Kind {
+	case KindService:
+		return o.Service.Name
+	case KindRoute:
+		return o.Route.Name
+	case KindConsumer:
+		return o.Consumer.Name
+	}
+	if o.ID() == 0 {
+		return o.Source
+	}
+	return o.ID().String()
+}
+
+// String returns the object reference in the format of kind/name.
+func (o *Object) String() string {
+	return o.Kind + "/" + o.Name()
+}
+
+// Redacted checks whether the SSL certificate or private key is redacted,
+// which is the case for exported SSL objects.
+func (o *Object) Redacted() bool {
+	return o.Kind == KindSSL && (o.SSL.Certificate == RedactedValue || o.SSL.PrivateKey == RedactedValue)
+}
+
+// Spec returns the resource specification.
+func (o *Object) Spec() interface{} {
+	switch o.Kind {
+	case KindService:
+		return o.Service
+	case KindRoute:
+		return o.Route
+	case KindConsumer:
+		return o.Consumer
+	case KindSSL:
+		return o.SSL
+	}
+	return nil
+}
@@ -16,8 +16,13 @@ package options
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/api7/cloud-go-sdk"
+
+	"github.com/api7/cloud-cli/internal/selector"
 )
 
 var (
@@ -33,16 +38,42 @@ type Options struct {
 	DryRun bool
 	// Profile is the name of the profile to use.
 	Profile string
+	// Org is the name or ID of the organization to use, it overrides the
+	// organization of the profile.
+	Org string
+	// Cluster is the name or ID of the cluster to use, it overrides the
+	// cluster of the profile.
+	Cluster string
+	// Output is the output format of resources, e.g. json, yaml, table.
+	Output string
 	// Deploy contains the options for the deploy command.
 	Deploy DeployOptions
 	// Stop contains the options for the stop command.
 	Stop StopOptions
+	// Status contains the options for the status command.
+	Status StatusOptions
+	// Logs contains the options for the logs command.
+	Logs LogsOptions
+	// Upgrade contains the options for the upgrade command.
+	Upgrade UpgradeOptions
+	// Rollback contains the options for the rollback command.
+	Rollback RollbackOptions
+	// Module contains the options for the module command.
+	Module ModuleOptions
 	// Debug contains the options for the debug command.
 	Debug DebugOptions
 	// Resource contains the options for the resource command.
 	Resource ResourceOptions
 	// Configure contains the options for the configure command.
 	Configure ConfigureOptions
+	// Apply contains the options for the apply command.
+	Apply ApplyOptions
+	// Diff contains the options for the diff command.
+	Diff DiffOptions
+	// Export contains the options for the export command.
+	Export ExportOptions
+	// Import contains the options for the import command.
+	Import ImportOptions
 }
 
 // DeployOptions contains options for the deploy command.
@@ -57,6 +88,17 @@ type DeployOptions struct {
 	APISIXInstanceID string `validate:"min=1 max=128"`
 	// APISIXConfigFile is the path to the APISIX configuration file.
 	APISIXConfigFile string
+	// ModuleBundle is the path to the cloud lua module bundle (in the tar.gz
+	// format), it'll be used instead of downloading from API7 Cloud.
+	ModuleBundle string
+	// ModuleChecksum is the expected SHA-256 checksum of the cloud lua
+	// module, either the downloaded one or the bundle.
+	ModuleChecksum string
+	// Wait indicates whether to wait until APISIX is ready and registered
+	// to API7 Cloud after it's deployed.
+	Wait bool
+	// Timeout is the maximum duration to wait for APISIX to be ready.
+	Timeout time.Duration
 	// Docker contains the options for the deploy docker command.
 	Docker DockerDeployOptions
 	// Bare contains the options for the bare metal deployment command.
@@ -73,13 +115,31 @@ type DockerDeployOptions struct {
 	DockerRunArgs []string
 	// DockerCLIPath is the filepath of the docker command.
 	DockerCLIPath string
+	// Runtime is the container runtime, one of docker, podman and nerdctl.
+	Runtime string
 	// Specify the host port for HTTP
 	HTTPHostPort int
 	// Specify the host port for HTTPS
 	HTTPSHostPort int
+	// HTTPPort is the port that APISIX listens on for HTTP in the container.
+	HTTPPort int
+	// HTTPSPort is the port that APISIX listens on for HTTPS in the container.
+	HTTPSPort int
+	// Ports contains the additional port mappings in the format of
+	// host:container[/protocol], e.g. 9100:9100/udp.
+	Ports []string
 	// Specify the filesystem path of the host directory to mount into the container for
 	// saving the APISIX local configuration cache.
 	LocalCacheBindPath string
+	// ComposeOut is the path to write the Docker Compose file to, the
+	// container won't be run by docker run if it's specified.
+	ComposeOut string
+	// ComposeUp controls whether to run the compose file by
+	// docker compose up, it only works with ComposeOut.
+	ComposeUp bool
+	// Replicas is the number of APISIX containers to deploy, the host
+	// ports are incremented for each one.
+	Replicas int
 }
 
 // Validate validates the docker deploy options.
@@ -92,6 +152,30 @@ func (o *DockerDeployOptions) Validate() error {
 		return errors.New("invalid https host port")
 	}
 
+	if o.HTTPPort <= 0 || o.HTTPPort > 65535 {
+		return errors.New("invalid http port")
+	}
+
+	if o.HTTPSPort <= 0 || o.HTTPSPort > 65535 {
+		return errors.New("invalid https port")
+	}
+
+	if o.HTTPPort == o.HTTPSPort {
+		return errors.New("http port and https port should be different")
+	}
+
+	if o.ComposeUp && o.ComposeOut == "" {
+		return errors.New("--compose-up should be used with --compose-out")
+	}
+
+	if o.Replicas < 1 {
+		return errors.New("invalid replicas, it should be at least 1")
+	}
+
+	if o.Replicas > 1 && o.ComposeOut != "" {
+		return errors.New("--replicas cannot be used with --compose-out")
+	}
+
 	return nil
 }
 
@@ -115,6 +199,49 @@ type KubernetesDeployOptions struct {
 	HelmCLIPath string
 	// LocalCachePVC is the PVC for saving the local configuration cache.
 	LocalCachePVC string
+	// ChartVersion pins the version of the APISIX Helm chart, the latest
+	// version will be used if it's empty.
+	ChartVersion string
+	// Mode is the way to deploy APISIX, it's either KubernetesModeHelm
+	// or KubernetesModeManifests.
+	Mode string
+	// RenderOnly controls whether to print the manifests instead of
+	// applying them, it only works in the KubernetesModeManifests mode.
+	RenderOnly bool
+	// OutputDir is the directory to write the kustomization to, nothing
+	// will be deployed if it's specified.
+	OutputDir string
+}
+
+const (
+	// KubernetesModeHelm deploys APISIX with the APISIX Helm chart.
+	KubernetesModeHelm = "helm"
+	// KubernetesModeManifests deploys APISIX with the native Kubernetes
+	// manifests rendered by the Cloud CLI.
+	KubernetesModeManifests = "manifests"
+)
+
+// Validate validates the kubernetes deploy options.
+func (o *KubernetesDeployOptions) Validate() error {
+	switch o.Mode {
+	case KubernetesModeHelm:
+		if o.RenderOnly {
+			return fmt.Errorf("--render-only is only available in the %s mode", KubernetesModeManifests)
+		}
+	case KubernetesModeManifests:
+		if o.ChartVersion != "" {
+			return fmt.Errorf("--version is only available in the %s mode", KubernetesModeHelm)
+		}
+		if o.LocalCachePVC != "" {
+			return fmt.Errorf("--local-cache-pvc is only available in the %s mode", KubernetesModeHelm)
+		}
+		if o.RenderOnly && o.OutputDir != "" {
+			return errors.New("--render-only and --output-dir cannot be used together")
+		}
+	default:
+		return fmt.Errorf("invalid mode %s, should be one of: %s, %s", o.Mode, KubernetesModeHelm, KubernetesModeManifests)
+	}
+	return nil
 }
 
 // StopOptions contains options for the stop command.
@@ -132,10 +259,131 @@ type StopOptions struct {
 	Kubernetes KubernetesStopOptions
 }
 
+// UpgradeOptions contains options for the upgrade command.
+type UpgradeOptions struct {
+	// Name is the identifier of the deployment to upgrade.
+	Name string
+	// Docker contains options for the upgrade docker command.
+	Docker DockerUpgradeOptions
+}
+
+// DockerUpgradeOptions contains options for the upgrade docker command.
+type DockerUpgradeOptions struct {
+	// APISIXImage is the name of the new APISIX image.
+	APISIXImage string
+	// DockerCLIPath is the filepath of the docker command.
+	DockerCLIPath string
+	// Timeout is the duration to wait for the new container to be ready.
+	Timeout time.Duration
+}
+
+// Validate validates the docker upgrade options.
+func (o *DockerUpgradeOptions) Validate() error {
+	if o.APISIXImage == "" {
+		return errors.New("--apisix-image is required")
+	}
+	if o.Timeout <= 0 {
+		return errors.New("--timeout should be positive")
+	}
+	return nil
+}
+
+// RollbackOptions contains options for the rollback command.
+type RollbackOptions struct {
+	// Name is the identifier of the deployment to roll back.
+	Name string
+	// Kubernetes contains options for the rollback kubernetes command.
+	Kubernetes KubernetesRollbackOptions
+}
+
+// KubernetesRollbackOptions contains options for the rollback kubernetes command.
+type KubernetesRollbackOptions struct {
+	// Namespace is the name space of kubernetes
+	Namespace string
+	// Revision is the Helm release revision to roll back to, 0 means
+	// the previous revision.
+	Revision int
+	// HelmCLIPath is the filepath of the helm command.
+	HelmCLIPath string
+}
+
+// Validate validates the kubernetes rollback options.
+func (o *KubernetesRollbackOptions) Validate() error {
+	if o.Revision < 0 {
+		return errors.New("--revision should not be negative")
+	}
+	return nil
+}
+
+// StatusOptions contains options for the status command.
+type StatusOptions struct {
+	// DockerCLIPath is the filepath of the docker command.
+	DockerCLIPath string
+	// KubectlCLIPath is the filepath of the kubectl command.
+	KubectlCLIPath string
+}
+
+// LogsOptions contains options for the logs command.
+type LogsOptions struct {
+	// Name is an identifier of the deployment.
+	// It'll be container name if deploy on Docker;
+	// It'll be the Helm release name if deploy on Kubernetes;
+	// It'll be noop if deploy on Bare metal.
+	Name string
+	// Follow controls whether to stream the new logs.
+	Follow bool
+	// Since only shows the logs newer than the relative duration, all the
+	// logs are shown if it's zero.
+	Since time.Duration
+	// Grep is the regular expression to filter the log lines.
+	Grep string
+	// Tail is the number of lines to show from the end of the logs, all
+	// the lines are shown if it's negative.
+	Tail int
+	// Docker contains the options for the logs docker command.
+	Docker DockerLogsOptions
+	// Kubernetes contains the options for the logs kubernetes command.
+	Kubernetes KubernetesLogsOptions
+}
+
+// Validate validates the logs options.
+func (o *LogsOptions) Validate() error {
+	if o.Since < 0 {
+		return errors.New("--since should not be negative")
+	}
+	if _, err := regexp.Compile(o.Grep); err != nil {
+		return fmt.Errorf("invalid --grep option: %s", err)
+	}
+	return nil
+}
+
+// DockerLogsOptions contains options for the logs docker command.
+type DockerLogsOptions struct {
+	// DockerCLIPath is the filepath of the docker command.
+	DockerCLIPath string
+	// Runtime is the container runtime, the recorded one of the deployment
+	// is used if it's empty.
+	Runtime string
+}
+
+// KubernetesLogsOptions contains options for the logs kubernetes command.
+type KubernetesLogsOptions struct {
+	// Namespace is the name space of kubernetes
+	Namespace string
+	// KubectlCLIPath is the filepath of the kubectl command.
+	KubectlCLIPath string
+}
+
 // DockerStopOptions contains options for the stop docker command.
 type DockerStopOptions struct {
 	// DockerCLIPath is the filepath of the docker command.
 	DockerCLIPath string
+	// Runtime is the container runtime, the recorded one of the deployment
+	// is used if it's empty.
+	Runtime string
+	// AllReplicas controls whether to stop all the replicas deployed by
+	// deploy docker --replicas, Name is the one used to deploy them.
+	AllReplicas bool
 }
 
 // BareDeployOptions contains options for the bare metal deployment command.
@@ -163,6 +411,13 @@ type KubernetesStopOptions struct {
 	HelmCLIPath string
 }
 
+// ModuleOptions contains options for the module command.
+type ModuleOptions struct {
+	// Checksum is the expected SHA-256 checksum of the cloud lua module
+	// to pull.
+	Checksum string
+}
+
 // DebugOptions contains options for `cloud-cli debug` command.
 type DebugOptions struct {
 	// ShowConfig contains options for `cloud-cli debug show-config` command.
@@ -173,6 +428,11 @@ type DebugOptions struct {
 type DebugShowConfigOptions struct {
 	// ID is the API7 Cloud resource id.
 	ID cloud.ID
+	// Name is the API7 Cloud resource name, it's resolved to the ID.
+	Name string
+	// Service is the service name of the api, it's required when showing
+	// the config of an api by name.
+	Service string
 }
 
 // ConfigureOptions contains options for `cloud-cli configure` command
@@ -212,6 +472,11 @@ type ResourceCreateOptions struct {
 type ResourceUpdateOptions struct {
 	// ID specifies the resource ID.
 	ID string
+	// Name specifies the resource name, it's resolved to the ID.
+	Name string
+	// Service specifies the service name of the route, it's required when
+	// updating route by name.
+	Service string
 	// Kind specifies the kind of resource.
 	Kind string
 	// SSL specifies the SSL create options.
@@ -279,13 +544,52 @@ type ResourceListOptions struct {
 	Skip int
 	// Specify the ID of service
 	ServiceID string
+	// All indicates listing all the resources, Limit and Skip are ignored.
+	All bool
+	// Selector is the label selector, like "team=payments,env in (prod,staging)".
+	Selector string
+	// FieldSelector is the field selector, like "name=users,status=normal".
+	FieldSelector string
 }
 
 // ResourceDeleteOptions contains options for `cloud-cli resource delete` command.
 type ResourceDeleteOptions struct {
-	Kind      string
-	ID        string
+	Kind string
+	// IDs are the ids of the resources to delete.
+	IDs []string
+	// Names are the names of the resources to delete.
+	Names     []string
 	ServiceID string
+	// Service is the service name of the routes to delete.
+	Service string
+	// Selector is the label selector of the resources to delete.
+	Selector string
+	// All indicates deleting all the resources of the kind.
+	All bool
+	// Cascade indicates deleting the routes of the services as well.
+	Cascade bool
+	// Yes indicates skipping the confirmation prompt.
+	Yes bool
+}
+
+// Validate validates the resource delete options.
+func (o *ResourceDeleteOptions) Validate() error {
+	targets := 0
+	for _, set := range []bool{len(o.IDs) > 0 || len(o.Names) > 0, o.Selector != "", o.All} {
+		if set {
+			targets++
+		}
+	}
+	if targets == 0 {
+		return errors.New("one of --id/--name, --selector and --all is required")
+	}
+	if targets > 1 {
+		return errors.New("--id/--name, --selector and --all are mutually exclusive")
+	}
+	if _, err := selector.Parse(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %s", err)
+	}
+	return nil
 }
 
 // ResourceGetOptions contains options for `cloud-cli resource get` command.
@@ -294,8 +598,12 @@ type ResourceGetOptions struct {
 	Kind string
 	// Specify the ID of resource
 	ID string
+	// Name is the name of resource, it's resolved to the ID.
+	Name string
 	// Specify the service ID of resource
 	ServiceID string
+	// Service is the service name of resource, it's resolved to the ID.
+	Service string
 }
 
 // Validate validates the docker deploy options.
@@ -308,5 +616,91 @@ func (o *ResourceListOptions) Validate() error {
 		return errors.New("invalid skip number")
 	}
 
+	if _, err := selector.Parse(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %s", err)
+	}
+
+	if _, err := selector.ParseFields(o.FieldSelector); err != nil {
+		return fmt.Errorf("invalid --field-selector: %s", err)
+	}
+
+	return nil
+}
+
+// ApplyOptions contains options for `cloud-cli apply` command.
+type ApplyOptions struct {
+	// Filenames are the manifest files or directories, "-" means the standard input.
+	Filenames []string
+}
+
+// Validate validates the ApplyOptions.
+func (o *ApplyOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return errors.New("--filename is required")
+	}
+	return nil
+}
+
+// DiffOptions contains options for `cloud-cli diff` command.
+type DiffOptions struct {
+	// Filenames are the manifest files or directories, "-" means the standard input.
+	Filenames []string
+}
+
+// Validate validates the DiffOptions.
+func (o *DiffOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return errors.New("--filename is required")
+	}
+	return nil
+}
+
+// ExportOptions contains options for `cloud-cli export` command.
+type ExportOptions struct {
+	// Directory is the directory to write the objects, one file per object.
+	Directory string
+	// Archive is the tar.gz file to write the objects.
+	Archive string
+}
+
+// Validate validates the ExportOptions.
+func (o *ExportOptions) Validate() error {
+	if o.Directory == "" && o.Archive == "" {
+		return errors.New("either --directory or --archive is required")
+	}
+	if o.Directory != "" && o.Archive != "" {
+		return errors.New("--directory and --archive are mutually exclusive")
+	}
 	return nil
 }
+
+const (
+	// OnConflictSkip means skipping the objects which already exist.
+	OnConflictSkip = "skip"
+	// OnConflictOverwrite means overwriting the objects which already exist.
+	OnConflictOverwrite = "overwrite"
+	// OnConflictFail means aborting the import if any object already exists.
+	OnConflictFail = "fail"
+)
+
+// ImportOptions contains options for `cloud-cli import` command.
+type ImportOptions struct {
+	// Filenames are the exported directories, archives or manifest files.
+	Filenames []string
+	// OnConflict specifies how to handle the objects which already exist,
+	// optional values are skip, overwrite and fail.
+	OnConflict string
+}
+
+// Validate validates the ImportOptions.
+func (o *ImportOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return errors.New("--filename is required")
+	}
+	switch o.OnConflict {
+	case OnConflictSkip, OnConflictOverwrite, OnConflictFail:
+		return nil
+	default:
+		return fmt.Errorf("invalid --on-conflict value %q, should be one of skip, overwrite and fail", o.OnConflict)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+)
+
+// Column is a column of the table output.
+type Column struct {
+	// Header is the column header.
+	Header string
+	// Wide indicates the column is only shown in the wide output.
+	Wide bool
+	// Value extracts the cell value from a resource.
+	Value func(obj interface{}) string
+}
+
+// TableDefinition describes how a kind of resource is rendered in the
+// table, wide and name output formats.
+type TableDefinition struct {
+	// Columns are the table columns.
+	Columns []Column
+	// Name returns the identifier of the resource, it's used by the name
+	// output format.
+	Name func(obj interface{}) string
+}
+
+var (
+	_tableDefinitions = map[string]TableDefinition{
+		"cluster": {
+			Columns: []Column{
+				NewColumn("ID", false, func(c *sdk.Cluster) string { return c.ID.String() }),
+				NewColumn("Name", false, func(c *sdk.Cluster) string { return c.Name }),
+				NewColumn("Status", false, func(c *sdk.Cluster) string { return strconv.Itoa(int(c.Status)) }),
+				NewColumn("Domain", false, func(c *sdk.Cluster) string { return c.Domain }),
+				NewColumn("Organization ID", true, func(c *sdk.Cluster) string { return c.OrganizationID.String() }),
+				NewColumn("Region ID", true, func(c *sdk.Cluster) string { return c.RegionID.String() }),
+				NewColumn("Created At", true, func(c *sdk.Cluster) string { return formatTime(c.CreatedAt) }),
+			},
+			Name: name(func(c *sdk.Cluster) string { return c.ID.String() }),
+		},
+		"organization": {
+			Columns: []Column{
+				NewColumn("ID", false, func(o *sdk.Organization) string { return o.ID.String() }),
+				NewColumn("Name", false, func(o *sdk.Organization) string { return o.Name }),
+				NewColumn("Plan ID", false, func(o *sdk.Organization) string { return o.PlanID.String() }),
+				NewColumn("Plan Expire Time", true, func(o *sdk.Organization) string { return formatTime(o.PlanExpireTime) }),
+				NewColumn("Owner ID", true, func(o *sdk.Organization) string { return o.OwnerID }),
+				NewColumn("Created At", true, func(o *sdk.Organization) string { return formatTime(o.CreatedAt) }),
+			},
+			Name: name(func(o *sdk.Organization) string { return o.ID.String() }),
+		},
+		"service": {
+			Columns: []Column{
+				NewColumn("ID", false, func(s *sdk.Application) string { return s.ID.String() }),
+				NewColumn("Name", false, func(s *sdk.Application) string { return s.Name }),
+				NewColumn("Path Prefix", false, func(s *sdk.Application) string { return s.PathPrefix }),
+				NewColumn("Hosts", false, func(s *sdk.Application) string { return join(s.Hosts) }),
+				NewColumn("Status", false, func(s *sdk.Application) string { return entityStatus(s.Status) }),
+				NewColumn("Protocols", true, func(s *sdk.Application) string { return join(s.Protocols) }),
+				NewColumn("Upstreams", true, func(s *sdk.Application) string { return strconv.Itoa(len(s.Upstreams)) }),
+				NewColumn("Labels", true, func(s *sdk.Application) string { return join(s.Labels) }),
+				NewColumn("Updated At", true, func(s *sdk.Application) string { return formatTime(s.UpdatedAt) }),
+			},
+			Name: name(func(s *sdk.Application) string { return s.ID.String() }),
+		},
+		"route": {
+			Columns: []Column{
+				NewColumn("ID", false, func(r *sdk.API) string { return r.ID.String() }),
+				NewColumn("Name", false, func(r *sdk.API) string { return r.Name }),
+				NewColumn("Service ID", false, func(r *sdk.API) string { return r.AppID.String() }),
+				NewColumn("Methods", false, func(r *sdk.API) string { return join(r.Methods) }),
+				NewColumn("Paths", false, func(r *sdk.API) string { return routePaths(r) }),
+				NewColumn("Status", true, func(r *sdk.API) string { return entityStatus(r.Status) }),
+				NewColumn("Strip Path Prefix", true, func(r *sdk.API) string { return strconv.FormatBool(r.StripPathPrefix) }),
+				NewColumn("Labels", true, func(r *sdk.API) string { return join(r.Labels) }),
+				NewColumn("Updated At", true, func(r *sdk.API) string { return formatTime(r.UpdatedAt) }),
+			},
+			Name: name(func(r *sdk.API) string { return r.ID.String() }),
+		},
+		"consumer": {
+			Columns: []Column{
+				NewColumn("ID", false, func(c *sdk.Consumer) string { return c.ID.String() }),
+				NewColumn("Name", false, func(c *sdk.Consumer) string { return c.Name }),
+				NewColumn("Description", false, func(c *sdk.Consumer) string { return c.Description }),
+				NewColumn("Labels", true, func(c *sdk.Consumer) string { return join(c.Labels) }),
+				NewColumn("Credentials", true, func(c *sdk.Consumer) string { return strconv.Itoa(len(c.Credentials)) }),
+				NewColumn("Plugins", true, func(c *sdk.Consumer) string { return strconv.Itoa(len(c.Plugins)) }),
+			},
+			Name: name(func(c *sdk.Consumer) string { return c.ID.String() }),
+		},
+		"ssl": {
+			Columns: []Column{
+				NewColumn("ID", false, func(c *sdk.CertificateDetails) string { return c.ID.String() }),
+				NewColumn("SNIs", false, func(c *sdk.CertificateDetails) string { return join(c.SNIs) }),
+				NewColumn("Type", false, func(c *sdk.CertificateDetails) string { return c.Type }),
+				NewColumn("Not After", false, func(c *sdk.CertificateDetails) string { return formatTime(c.NotAfter) }),
+				NewColumn("Status", true, func(c *sdk.CertificateDetails) string { return entityStatus(c.Status) }),
+				NewColumn("Not Before", true, func(c *sdk.CertificateDetails) string { return formatTime(c.NotBefore) }),
+				NewColumn("Subject", true, func(c *sdk.CertificateDetails) string { return c.Subject }),
+				NewColumn("Issuer", true, func(c *sdk.CertificateDetails) string { return c.Issuer }),
+				NewColumn("Labels", true, func(c *sdk.CertificateDetails) string { return join(c.Labels) }),
+			},
+			Name: name(func(c *sdk.CertificateDetails) string { return c.ID.String() }),
+		},
+	}
+)
+
+// RegisterTableDefinition registers the table definition for a kind of
+// resource, the existing one will be replaced.
+func RegisterTableDefinition(kind string, def TableDefinition) {
+	_tableDefinitions[kind] = def
+}
+
+// NewColumn creates a Column whose value is extracted from resources of type T,
+// "-" will be shown for resources of other types.
+func NewColumn[T any](header string, wide bool, value func(T) string) Column {
+	return Column{
+		Header: header,
+		Wide:   wide,
+		Value: func(obj interface{}) string {
+			v, ok := obj.(T)
+			if !ok {
+				return "-"
+			}
+			return value(v)
+		},
+	}
+}
+
+func name[T any](value func(T) string) func(obj interface{}) string {
+	return func(obj interface{}) string {
+		v, ok := obj.(T)
+		if !ok {
+			return "-"
+		}
+		return value(v)
+	}
+}
+
+func join(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func entityStatus(status sdk.EntityStatus) string {
+	switch status {
+	case sdk.Uninitialized:
+		return "Uninitialized"
+	case sdk.Normal:
+		return "Normal"
+	case sdk.Deleted:
+		return "Deleted"
+	default:
+		return strconv.Itoa(int(status))
+	}
+}
+
+func routePaths(r *sdk.API) string {
+	paths := make([]string, 0, len(r.Paths))
+	for _, p := range r.Paths {
+		paths = append(paths, p.Path)
+	}
+	return join(paths)
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// jsonPath is a parsed JSONPath template, it supports a subset of the
+// kubectl JSONPath syntax: literal text, quoted strings like {"\n"}, and
+// expressions like {.name}, {.paths[0].path} and {[*].id}.
+type jsonPath struct {
+	segments []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	// text is the literal text, it's used when steps is nil.
+	text  string
+	steps []jsonPathStep
+}
+
+type jsonPathStep struct {
+	field string
+	// isIndex indicates the step is an array index rather than a field.
+	isIndex bool
+	// index is the array index, negative values count from the end.
+	index int
+	// all indicates all the array elements are selected.
+	all bool
+}
+
+func parseJSONPath(tpl string) (*jsonPath, error) {
+	var (
+		jp   jsonPath
+		rest = tpl
+	)
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			jp.segments = append(jp.segments, jsonPathSegment{text: rest})
+			break
+		}
+		if start > 0 {
+			jp.segments = append(jp.segments, jsonPathSegment{text: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, errors.Errorf("unclosed expression in %q", tpl)
+		}
+		expr := strings.TrimSpace(rest[start+1 : start+end])
+		rest = rest[start+end+1:]
+
+		if strings.HasPrefix(expr, "\"") {
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid string literal %s", expr)
+			}
+			jp.segments = append(jp.segments, jsonPathSegment{text: text})
+			continue
+		}
+
+		steps, err := parseJSONPathSteps(expr)
+		if err != nil {
+			return nil, err
+		}
+		jp.segments = append(jp.segments, jsonPathSegment{steps: steps})
+	}
+	return &jp, nil
+}
+
+func parseJSONPathSteps(expr string) ([]jsonPathStep, error) {
+	expr = strings.TrimPrefix(expr, "$")
+	steps := []jsonPathStep{}
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			n := strings.IndexAny(expr, ".[")
+			if n < 0 {
+				n = len(expr)
+			}
+			if n > 0 {
+				steps = append(steps, jsonPathStep{field: expr[:n]})
+			}
+			expr = expr[n:]
+		case '[':
+			n := strings.IndexByte(expr, ']')
+			if n < 0 {
+				return nil, errors.Errorf("unclosed index in %q", expr)
+			}
+			idx := strings.TrimSpace(expr[1:n])
+			expr = expr[n+1:]
+			if idx == "*" {
+				steps = append(steps, jsonPathStep{isIndex: true, all: true})
+				continue
+			}
+			i, err := strconv.Atoi(idx)
+			if err != nil {
+				return nil, errors.Errorf("invalid index %q", idx)
+			}
+			steps = append(steps, jsonPathStep{isIndex: true, index: i})
+		default:
+			return nil, errors.Errorf("unexpected character %q in expression", expr[0])
+		}
+	}
+	return steps, nil
+}
+
+func (jp *jsonPath) execute(data interface{}) (string, error) {
+	var sb strings.Builder
+	for _, seg := range jp.segments {
+		if seg.steps == nil {
+			sb.WriteString(seg.text)
+			continue
+		}
+		values := []interface{}{data}
+		for _, step := range seg.steps {
+			var next []interface{}
+			for _, v := range values {
+				results, err := step.apply(v)
+				if err != nil {
+					return "", err
+				}
+				next = append(next, results...)
+			}
+			values = next
+		}
+		texts := make([]string, 0, len(values))
+		for _, v := range values {
+			texts = append(texts, formatJSONPathValue(v))
+		}
+		sb.WriteString(strings.Join(texts, " "))
+	}
+	return sb.String(), nil
+}
+
+func (step jsonPathStep) apply(v interface{}) ([]interface{}, error) {
+	if !step.isIndex {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s is not found", step.field)
+		}
+		value, ok := m[step.field]
+		if !ok {
+			return nil, errors.Errorf("%s is not found", step.field)
+		}
+		return []interface{}{value}, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("index applied on a non-array value")
+	}
+	if step.all {
+		return list, nil
+	}
+	idx := step.index
+	if idx < 0 {
+		idx += len(list)
+	}
+	if idx < 0 || idx >= len(list) {
+		return nil, errors.Errorf("array index %d out of bounds", step.index)
+	}
+	return []interface{}{list[idx]}, nil
+}
+
+func formatJSONPathValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/options"
+)
+
+const (
+	// FormatJSON prints resources as indented JSON.
+	FormatJSON = "json"
+	// FormatYAML prints resources as YAML.
+	FormatYAML = "yaml"
+	// FormatTable prints resources as an ASCII table with the essential columns.
+	FormatTable = "table"
+	// FormatWide prints resources as an ASCII table with all the columns.
+	FormatWide = "wide"
+	// FormatName prints the kind and the identifier of resources, one per line.
+	FormatName = "name"
+	// FormatJSONPath prints the fields selected by a JSONPath template.
+	FormatJSONPath = "jsonpath"
+	// FormatGoTemplate prints resources by a Go template.
+	FormatGoTemplate = "go-template"
+)
+
+// Printer prints API7 Cloud resources in a specific format.
+type Printer interface {
+	// Print prints the object (a single resource or a slice of resources)
+	// of the given kind to the writer.
+	Print(w io.Writer, kind string, obj interface{}) error
+}
+
+// NewPrinter creates a Printer according to the format, the format can be
+// json, yaml, table, wide, name, jsonpath=<template> or go-template=<template>.
+func NewPrinter(format string) (Printer, error) {
+	name, tpl, _ := strings.Cut(format, "=")
+	switch name {
+	case FormatJSON:
+		return &jsonPrinter{}, nil
+	case FormatYAML:
+		return &yamlPrinter{}, nil
+	case FormatTable:
+		return &tablePrinter{}, nil
+	case FormatWide:
+		return &tablePrinter{wide: true}, nil
+	case FormatName:
+		return &namePrinter{}, nil
+	case FormatJSONPath:
+		if tpl == "" {
+			return nil, errors.New("jsonpath template is required, e.g. jsonpath={.id}")
+		}
+		expr, err := parseJSONPath(tpl)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse jsonpath template")
+		}
+		return &jsonPathPrinter{expr: expr}, nil
+	case FormatGoTemplate:
+		if tpl == "" {
+			return nil, errors.New("go-template is required, e.g. go-template={{.id}}")
+		}
+		t, err := template.New("output").Parse(tpl)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse go-template")
+		}
+		return &goTemplatePrinter{tpl: t}, nil
+	default:
+		return nil, errors.Errorf("unknown output format %q, should be one of json, yaml, table, wide, name, jsonpath=<template>, go-template=<template>", format)
+	}
+}
+
+// PrintResource prints the object of the given kind to stdout, the format is
+// decided by the --output option, defaultFormat will be used if it's empty.
+func PrintResource(kind string, obj interface{}, defaultFormat string) {
+	format := options.Global.Output
+	if format == "" {
+		format = defaultFormat
+	}
+	printer, err := NewPrinter(format)
+	if err != nil {
+		Errorf(err.Error())
+		return
+	}
+	if err = printer.Print(os.Stdout, kind, obj); err != nil {
+		Errorf("Failed to print %s: %s", kind, err.Error())
+	}
+}
+
+type jsonPrinter struct{}
+
+func (p *jsonPrinter) Print(w io.Writer, _ string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+type yamlPrinter struct{}
+
+func (p *yamlPrinter) Print(w io.Writer, _ string, obj interface{}) error {
+	// Go through JSON first so that the field names respect the json tags
+	// of the API7 Cloud SDK types.
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type tablePrinter struct {
+	wide bool
+}
+
+func (p *tablePrinter) Print(w io.Writer, kind string, obj interface{}) error {
+	def, ok := _tableDefinitions[kind]
+	if !ok {
+		return errors.Errorf("table output is not supported for %s", kind)
+	}
+
+	var columns []Column
+	for _, c := range def.Columns {
+		if c.Wide && !p.wide {
+			continue
+		}
+		columns = append(columns, c)
+	}
+
+	table := tablewriter.NewWriter(w)
+	headers := make([]string, 0, len(columns))
+	for _, c := range columns {
+		headers = append(headers, c.Header)
+	}
+	table.SetHeader(headers)
+
+	for _, item := range items(obj) {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, c.Value(item))
+		}
+		table.Append(row)
+	}
+	table.Render()
+	return nil
+}
+
+type namePrinter struct{}
+
+func (p *namePrinter) Print(w io.Writer, kind string, obj interface{}) error {
+	def, ok := _tableDefinitions[kind]
+	if !ok || def.Name == nil {
+		return errors.Errorf("name output is not supported for %s", kind)
+	}
+	for _, item := range items(obj) {
+		if _, err := fmt.Fprintf(w, "%s/%s\n", kind, def.Name(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonPathPrinter struct {
+	expr *jsonPath
+}
+
+func (p *jsonPathPrinter) Print(w io.Writer, _ string, obj interface{}) error {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	text, err := p.expr.execute(generic)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, text)
+	return err
+}
+
+type goTemplatePrinter struct {
+	tpl *template.Template
+}
+
+func (p *goTemplatePrinter) Print(w io.Writer, _ string, obj interface{}) error {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+	if err = p.tpl.Execute(w, generic); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// toGeneric converts the object to the generic JSON representation
+// (map[string]interface{}, []interface{} and scalars).
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// items flattens the object to a list of resources.
+func items(obj interface{}) []interface{} {
+	if obj == nil {
+		return nil
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{obj}
+	}
+	list := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		list = append(list, v.Index(i).Interface())
+	}
+	return list
+}
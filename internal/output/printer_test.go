@@ -0,0 +1,168 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrinter(t *testing.T) {
+	t.Parallel()
+
+	services := []*sdk.Application{
+		{
+			ID: 1,
+			ApplicationSpec: sdk.ApplicationSpec{
+				Name:       "foo",
+				PathPrefix: "/foo",
+				Hosts:      []string{"a.com", "b.com"},
+				Labels:     []string{"team=payments"},
+			},
+			Status: sdk.Normal,
+		},
+		{
+			ID: 2,
+			ApplicationSpec: sdk.ApplicationSpec{
+				Name:       "bar",
+				PathPrefix: "/bar",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		format      string
+		kind        string
+		obj         interface{}
+		output      string
+		errorReason string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			kind:   "service",
+			obj:    &sdk.Consumer{ID: 1, Name: "jack"},
+			output: "{\n\t\"id\": \"1\",\n\t\"name\": \"jack\",\n\t\"description\": \"\"\n}\n",
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			kind:   "consumer",
+			obj:    &sdk.Consumer{ID: 1, Name: "jack", Labels: []string{"a"}},
+			output: "description: \"\"\nid: \"1\"\nlabels:\n    - a\nname: jack\n",
+		},
+		{
+			name:   "table",
+			format: "table",
+			kind:   "service",
+			obj:    services,
+			output: `
++----+------+-------------+-------------+---------------+
+| ID | NAME | PATH PREFIX |    HOSTS    |    STATUS     |
++----+------+-------------+-------------+---------------+
+|  1 | foo  | /foo        | a.com,b.com | Normal        |
+|  2 | bar  | /bar        | -           | Uninitialized |
++----+------+-------------+-------------+---------------+
+`,
+		},
+		{
+			name:   "wide table with single object",
+			format: "wide",
+			kind:   "consumer",
+			obj:    &sdk.Consumer{ID: 1, Name: "jack", Labels: []string{"a", "b"}},
+			output: `
++----+------+-------------+--------+-------------+---------+
+| ID | NAME | DESCRIPTION | LABELS | CREDENTIALS | PLUGINS |
++----+------+-------------+--------+-------------+---------+
+|  1 | jack |             | a,b    |           0 |       0 |
++----+------+-------------+--------+-------------+---------+
+`,
+		},
+		{
+			name:   "name",
+			format: "name",
+			kind:   "service",
+			obj:    services,
+			output: "service/1\nservice/2\n",
+		},
+		{
+			name:   "jsonpath",
+			format: `jsonpath={[*].name}{"\n"}{[0].hosts[1]}`,
+			kind:   "service",
+			obj:    services,
+			output: "foo bar\nb.com\n",
+		},
+		{
+			name:   "go-template",
+			format: "go-template={{range .}}{{.id}}:{{.path_prefix}};{{end}}",
+			kind:   "service",
+			obj:    services,
+			output: "1:/foo;2:/bar;\n",
+		},
+		{
+			name:        "unknown format",
+			format:      "xml",
+			kind:        "service",
+			obj:         services,
+			errorReason: `unknown output format "xml"`,
+		},
+		{
+			name:        "table for unknown kind",
+			format:      "table",
+			kind:        "plugin",
+			obj:         services,
+			errorReason: "table output is not supported for plugin",
+		},
+		{
+			name:        "jsonpath without template",
+			format:      "jsonpath",
+			kind:        "service",
+			obj:         services,
+			errorReason: "jsonpath template is required",
+		},
+		{
+			name:        "jsonpath with missing field",
+			format:      "jsonpath={[0].foo}",
+			kind:        "service",
+			obj:         services,
+			errorReason: "foo is not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.NewBuffer(nil)
+			printer, err := NewPrinter(tc.format)
+			if err == nil {
+				err = printer.Print(buf, tc.kind, tc.obj)
+			}
+			if tc.errorReason != "" {
+				assert.NotNil(t, err, "check if err is not nil")
+				assert.Contains(t, err.Error(), tc.errorReason, "check error reason")
+				return
+			}
+			assert.Nil(t, err, "check if err is nil")
+			assert.Equal(t, strings.TrimLeft(tc.output, "\n"), buf.String(), "check output")
+		})
+	}
+}
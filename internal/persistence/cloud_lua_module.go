@@ -18,59 +18,417 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 
 	"github.com/api7/cloud-cli/internal/cloud"
+	"github.com/api7/cloud-cli/internal/output"
 )
 
-// SaveCloudLuaModule downloads the cloud lua module and unzip and untar it,
+const (
+	_cloudLuaModulePackage  = "cloud_module.tar.gz"
+	_cloudLuaModuleMetadata = "metadata.yaml"
+)
+
+var (
+	// _cloudLuaModuleRootRegex restricts the top-level directory of the
+	// cloud lua module, so that other files in the HomeDir won't be replaced.
+	_cloudLuaModuleRootRegex = regexp.MustCompile(`^cloud_lua_module[-_.a-zA-Z0-9]*$`)
+	// _cloudLuaModuleMaxEntries limits the number of entries in the cloud lua module.
+	_cloudLuaModuleMaxEntries = 4096
+	// _cloudLuaModuleMaxFileSize limits the size of a single file in the cloud lua module.
+	_cloudLuaModuleMaxFileSize int64 = 16 << 20
+	// _cloudLuaModuleMaxSize limits the total size of the extracted cloud lua module.
+	_cloudLuaModuleMaxSize int64 = 64 << 20
+)
+
+// CachedCloudLuaModule is a cloud lua module package in the local cache.
+type CachedCloudLuaModule struct {
+	// Checksum is the SHA-256 checksum of the package, it's also the key of
+	// the package in the cache.
+	Checksum string `json:"checksum" yaml:"checksum"`
+	// ETag is the entity tag of the package when it was downloaded.
+	ETag string `json:"etag,omitempty" yaml:"etag,omitempty"`
+	// Source is the URL or the bundle file where the package comes from.
+	Source string `json:"source" yaml:"source"`
+	// Size is the size of the package in bytes.
+	Size int64 `json:"size" yaml:"size"`
+	// Verified indicates whether the package was verified with a published
+	// or user specified checksum.
+	Verified bool `json:"verified" yaml:"verified"`
+	// PulledAt is the last time the package was pulled or imported.
+	PulledAt time.Time `json:"pulled_at" yaml:"pulled_at"`
+}
+
+// Path returns the filepath of the package.
+func (m *CachedCloudLuaModule) Path() string {
+	return filepath.Join(cloudLuaModuleCacheDir, m.Checksum, _cloudLuaModulePackage)
+}
+
+// ShortChecksum returns the first 12 characters of the checksum.
+func (m *CachedCloudLuaModule) ShortChecksum() string {
+	if len(m.Checksum) > 12 {
+		return m.Checksum[:12]
+	}
+	return m.Checksum
+}
+
+// ListCloudLuaModules lists the cached cloud lua modules, the latest pulled
+// one is the first.
+func ListCloudLuaModules() ([]*CachedCloudLuaModule, error) {
+	entries, err := os.ReadDir(cloudLuaModuleCacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read cloud lua module cache")
+	}
+
+	var modules []*CachedCloudLuaModule
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cloudLuaModuleCacheDir, entry.Name(), _cloudLuaModuleMetadata))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "failed to read cloud lua module metadata")
+		}
+		var module CachedCloudLuaModule
+		if err = yaml.Unmarshal(data, &module); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode cloud lua module metadata %s", entry.Name())
+		}
+		modules = append(modules, &module)
+	}
+	sort.SliceStable(modules, func(i, j int) bool {
+		return modules[i].PulledAt.After(modules[j].PulledAt)
+	})
+	return modules, nil
+}
+
+// PullCloudLuaModule pulls the cloud lua module to the local cache, it won't
+// be downloaded again if the latest cached one is not modified. The package
+// will be verified with the checksum published along with it, and the given
+// checksum if it's not empty. It also returns whether the package was
+// downloaded.
+func PullCloudLuaModule(checksum string) (*CachedCloudLuaModule, bool, error) {
+	modules, err := ListCloudLuaModules()
+	if err != nil {
+		return nil, false, err
+	}
+	var etag string
+	if len(modules) > 0 {
+		etag = modules[0].ETag
+	}
+
+	pkg, err := cloud.DefaultClient.GetCloudLuaModule(etag)
+	if err == nil && pkg.NotModified && pkg.URL != modules[0].Source {
+		// The ETag belongs to another URL, download it again.
+		pkg, err = cloud.DefaultClient.GetCloudLuaModule("")
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to get cloud lua module")
+	}
+	if pkg.NotModified {
+		latest := modules[0]
+		if checksum != "" && checksum != latest.Checksum {
+			return nil, false, fmt.Errorf("checksum mismatch: expected %s, got %s", checksum, latest.Checksum)
+		}
+		return latest, false, nil
+	}
+
+	for _, expected := range []string{pkg.Checksum, checksum} {
+		if err = verifyChecksum(pkg.Data, expected); err != nil {
+			return nil, false, errors.Wrap(err, "failed to verify cloud lua module")
+		}
+	}
+	module, err := cacheCloudLuaModule(pkg.Data, &CachedCloudLuaModule{
+		ETag:     pkg.ETag,
+		Source:   pkg.URL,
+		Verified: pkg.Checksum != "" || checksum != "",
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return module, true, nil
+}
+
+// ImportCloudLuaModule imports the cloud lua module bundle (in the tar.gz
+// format) to the local cache, it'll be verified if the checksum is not empty.
+func ImportCloudLuaModule(bundle string, checksum string) (*CachedCloudLuaModule, error) {
+	data, err := os.ReadFile(bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cloud lua module bundle")
+	}
+	if err = verifyChecksum(data, checksum); err != nil {
+		return nil, errors.Wrap(err, "failed to verify cloud lua module bundle")
+	}
+	source, err := filepath.Abs(bundle)
+	if err != nil {
+		source = bundle
+	}
+	return cacheCloudLuaModule(data, &CachedCloudLuaModule{
+		Source:   source,
+		Verified: checksum != "",
+	})
+}
+
+// VerifyCloudLuaModule checks whether the cached package is intact.
+func VerifyCloudLuaModule(module *CachedCloudLuaModule) error {
+	data, err := os.ReadFile(module.Path())
+	if err != nil {
+		return errors.Wrap(err, "failed to read cloud lua module")
+	}
+	if err = verifyChecksum(data, module.Checksum); err != nil {
+		return errors.Wrapf(err, "cloud lua module %s is corrupted", module.ShortChecksum())
+	}
+	return nil
+}
+
+func verifyChecksum(data []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := sha256Hex(data); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheCloudLuaModule saves the package and its metadata to the cache.
+func cacheCloudLuaModule(data []byte, module *CachedCloudLuaModule) (*CachedCloudLuaModule, error) {
+	module.Checksum = sha256Hex(data)
+	module.Size = int64(len(data))
+	module.PulledAt = time.Now()
+
+	dir := filepath.Join(cloudLuaModuleCacheDir, module.Checksum)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create cloud lua module cache")
+	}
+	if err := writeFileAtomically(module.Path(), data, 0644); err != nil {
+		return nil, errors.Wrap(err, "failed to save cloud lua module")
+	}
+	metadata, err := yaml.Marshal(module)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode cloud lua module metadata")
+	}
+	if err = writeFileAtomically(filepath.Join(dir, _cloudLuaModuleMetadata), metadata, 0644); err != nil {
+		return nil, errors.Wrap(err, "failed to save cloud lua module metadata")
+	}
+	return module, nil
+}
+
+func writeFileAtomically(filename string, data []byte, perm os.FileMode) error {
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// SaveCloudLuaModule prepares the cloud lua module and unzip and untar it,
 // finally it'll be saved to the filesystem and the directory will be returned.
-func SaveCloudLuaModule() (string, error) {
-	data, err := cloud.DefaultClient.GetCloudLuaModule()
+// The module is imported from the bundle if it's specified, or it'll be
+// pulled to the local cache, and the latest cached one is used if it cannot
+// be pulled. The module is verified with the checksum if it's not empty, and
+// a warning is printed if the module cannot be verified at all.
+func SaveCloudLuaModule(bundle string, checksum string) (string, error) {
+	var (
+		module *CachedCloudLuaModule
+		err    error
+	)
+	if bundle != "" {
+		if module, err = ImportCloudLuaModule(bundle, checksum); err != nil {
+			return "", err
+		}
+	} else {
+		if module, _, err = PullCloudLuaModule(checksum); err != nil {
+			modules, listErr := ListCloudLuaModules()
+			if listErr != nil || len(modules) == 0 {
+				return "", err
+			}
+			module = modules[0]
+			if checksum != "" && checksum != module.Checksum {
+				return "", errors.Wrapf(err, "the cached cloud lua module %s doesn't match the checksum %s", module.ShortChecksum(), checksum)
+			}
+			output.Warnf("%s, the cached cloud lua module %s is used", err, module.ShortChecksum())
+		}
+	}
+	if !module.Verified && checksum == "" {
+		output.Warnf("The cloud lua module %s was not verified, as its checksum was neither published nor specified", module.ShortChecksum())
+	}
+
+	if err = VerifyCloudLuaModule(module); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(module.Path())
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get cloud lua module")
+		return "", errors.Wrap(err, "failed to read cloud lua module")
 	}
-	tempReader, err := gzip.NewReader(bytes.NewBuffer(data))
+	return extractCloudLuaModule(data)
+}
+
+// extractCloudLuaModule extracts the cloud lua module to a temporary
+// directory, and then moves it to the HomeDir, so that the module in use is
+// either the old one or the new one, but never a partially extracted one.
+func extractCloudLuaModule(data []byte) (string, error) {
+	tempDir, err := os.MkdirTemp(HomeDir, ".cloud_lua_module-")
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create gzip reader")
+		return "", errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	root, err := untarCloudLuaModule(data, tempDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to extract cloud lua module")
 	}
-	defer tempReader.Close()
 
-	var entryDir string
+	target := filepath.Join(HomeDir, root)
+	// The old module is moved to the temporary directory and will be removed
+	// along with it.
+	backup := filepath.Join(tempDir, ".old")
+	if err = os.Rename(target, backup); err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrap(err, "failed to move the old cloud lua module")
+	}
+	if err = os.Rename(filepath.Join(tempDir, root), target); err != nil {
+		_ = os.Rename(backup, target)
+		return "", errors.Wrap(err, "failed to move the cloud lua module")
+	}
+	return target, nil
+}
 
-	reader := tar.NewReader(tempReader)
+// untarCloudLuaModule untars the cloud lua module to the dir and returns the
+// name of its top-level directory. Entries out of the top-level directory
+// are skipped, and it fails if there are unsafe entries, like the ones with
+// paths out of the dir, links and devices.
+func untarCloudLuaModule(data []byte, dir string) (string, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create gzip reader")
+	}
+	defer gzipReader.Close()
+
+	var (
+		root    string
+		entries int
+		size    int64
+	)
+	reader := tar.NewReader(gzipReader)
 	for {
 		hdr, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			if err == io.EOF {
-				if entryDir == "" {
-					entryDir = HomeDir
-				}
-				return entryDir, nil
-			}
 			return "", errors.Wrap(err, "failed to read tar")
 		}
-		if hdr.Typeflag == tar.TypeDir {
-			dir := filepath.Join(HomeDir, hdr.Name)
-			if entryDir == "" {
-				entryDir = dir
+		if entries++; entries > _cloudLuaModuleMaxEntries {
+			return "", fmt.Errorf("too many entries, the limit is %d", _cloudLuaModuleMaxEntries)
+		}
+
+		name, err := sanitizeTarEntryName(hdr.Name)
+		if err != nil {
+			return "", err
+		}
+		if name == "" {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeDir && hdr.Typeflag != tar.TypeReg {
+			return "", fmt.Errorf("unsupported entry %s (type %q)", hdr.Name, hdr.Typeflag)
+		}
+
+		top, _, nested := strings.Cut(name, "/")
+		if !nested && hdr.Typeflag == tar.TypeReg {
+			output.Verbosef("Skipped %s in the cloud lua module", hdr.Name)
+			continue
+		}
+		if root == "" {
+			if !_cloudLuaModuleRootRegex.MatchString(top) {
+				return "", fmt.Errorf("unexpected top-level directory %s", top)
 			}
-			if err := os.MkdirAll(dir, 0755); err != nil {
+			root = top
+		} else if top != root {
+			return "", fmt.Errorf("multiple top-level directories %s and %s", root, top)
+		}
+
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if hdr.Typeflag == tar.TypeDir {
+			if err = os.MkdirAll(filename, 0755); err != nil {
 				return "", errors.Wrap(err, "failed to create dir")
 			}
-		} else if hdr.Typeflag == tar.TypeReg {
-			filename := filepath.Join(HomeDir, hdr.Name)
-			buffer, err := io.ReadAll(reader)
-			if err != nil {
-				return "", errors.Wrap(err, "failed to read tar")
-			}
-			if err := os.WriteFile(filename, buffer, 0644); err != nil {
-				return "", errors.Wrap(err, "failed to save file")
-			}
+			continue
+		}
+
+		if hdr.Size > _cloudLuaModuleMaxFileSize {
+			return "", fmt.Errorf("file %s (%d bytes) exceeds the size limit (%d bytes)", hdr.Name, hdr.Size, _cloudLuaModuleMaxFileSize)
+		}
+		if size += hdr.Size; size > _cloudLuaModuleMaxSize {
+			return "", fmt.Errorf("the extracted size exceeds the limit (%d bytes)", _cloudLuaModuleMaxSize)
 		}
+		if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return "", errors.Wrap(err, "failed to create dir")
+		}
+		// Only the executable bits are respected, so that the files are
+		// never writable by others.
+		var mode os.FileMode = 0644
+		if hdr.Mode&0111 != 0 {
+			mode = 0755
+		}
+		if err = writeTarEntry(reader, filename, hdr.Size, mode); err != nil {
+			return "", errors.Wrap(err, "failed to save file")
+		}
+	}
+
+	if root == "" {
+		return "", errors.New("no cloud lua module was found")
+	}
+	return root, nil
+}
+
+// sanitizeTarEntryName cleans the entry name and makes sure it's a relative
+// path inside the extraction directory. An empty name is returned if the
+// entry is the extraction directory itself.
+func sanitizeTarEntryName(name string) (string, error) {
+	if strings.Contains(name, "\\") || path.IsAbs(name) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("unsafe entry %s", name)
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("unsafe entry %s", name)
+	}
+	return cleaned, nil
+}
+
+func writeTarEntry(reader io.Reader, filename string, size int64, mode os.FileMode) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.CopyN(file, reader, size); err != nil {
+		file.Close()
+		return err
 	}
+	return file.Close()
 }
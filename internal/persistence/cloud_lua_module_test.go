@@ -0,0 +1,409 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/cloud"
+)
+
+const _testCloudLuaModuleURL = "https://example.com/cloud_module.tar.gz"
+
+type tarEntry struct {
+	hdr  tar.Header
+	body string
+}
+
+func mockTarGz(t *testing.T, entries []tarEntry) []byte {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		hdr := entry.hdr
+		if hdr.Typeflag == tar.TypeReg && hdr.Size == 0 {
+			hdr.Size = int64(len(entry.body))
+		}
+		assert.NoError(t, tw.WriteHeader(&hdr), "write header")
+		if entry.body != "" {
+			_, err := tw.Write([]byte(entry.body))
+			assert.NoError(t, err, "write file")
+		}
+	}
+	assert.NoError(t, tw.Close(), "close tar writer")
+	assert.NoError(t, gw.Close(), "close gzip writer")
+	return buf.Bytes()
+}
+
+func mockCloudLuaModulePackage(t *testing.T, content string) []byte {
+	return mockTarGz(t, []tarEntry{
+		{hdr: tar.Header{Name: "cloud_lua_module/", Typeflag: tar.TypeDir, Mode: 0755}},
+		{hdr: tar.Header{Name: "cloud_lua_module/cloud.ljbc", Typeflag: tar.TypeReg, Mode: 0644}, body: content},
+	})
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPullCloudLuaModule(t *testing.T) {
+	v1 := mockCloudLuaModulePackage(t, "v1")
+	v2 := mockCloudLuaModulePackage(t, "v2")
+
+	testCases := []struct {
+		name        string
+		checksum    string
+		cached      []byte
+		errorReason string
+		pulled      bool
+		want        []byte
+		mockFn      func(api *cloud.MockAPI)
+	}{
+		{
+			name:   "pull without cache",
+			pulled: true,
+			want:   v1,
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule("").Return(&cloud.CloudLuaModule{
+					Data:     v1,
+					ETag:     `"v1"`,
+					URL:      _testCloudLuaModuleURL,
+					Checksum: checksumOf(v1),
+				}, nil)
+			},
+		},
+		{
+			name:   "not modified",
+			cached: v1,
+			want:   v1,
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule(`"v1"`).Return(&cloud.CloudLuaModule{
+					NotModified: true,
+					ETag:        `"v1"`,
+					URL:         _testCloudLuaModuleURL,
+				}, nil)
+			},
+		},
+		{
+			name:   "modified",
+			cached: v1,
+			pulled: true,
+			want:   v2,
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule(`"v1"`).Return(&cloud.CloudLuaModule{
+					Data: v2,
+					ETag: `"v2"`,
+					URL:  _testCloudLuaModuleURL,
+				}, nil)
+			},
+		},
+		{
+			name:        "published checksum mismatch",
+			errorReason: "failed to verify cloud lua module: checksum mismatch: expected " + checksumOf(v1),
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule("").Return(&cloud.CloudLuaModule{
+					Data:     v2,
+					URL:      _testCloudLuaModuleURL,
+					Checksum: checksumOf(v1),
+				}, nil)
+			},
+		},
+		{
+			name:        "specified checksum mismatch",
+			checksum:    "abcdef",
+			cached:      v1,
+			errorReason: "checksum mismatch: expected abcdef, got " + checksumOf(v1),
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule(`"v1"`).Return(&cloud.CloudLuaModule{
+					NotModified: true,
+					ETag:        `"v1"`,
+					URL:         _testCloudLuaModuleURL,
+				}, nil)
+			},
+		},
+		{
+			name:        "get cloud lua module failed",
+			errorReason: "failed to get cloud lua module: mock error",
+			mockFn: func(api *cloud.MockAPI) {
+				api.EXPECT().GetCloudLuaModule("").Return(nil, errors.New("mock error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			HomeDir = t.TempDir()
+			assert.NoError(t, Init(), "init persistence")
+
+			if tc.cached != nil {
+				_, err := cacheCloudLuaModule(tc.cached, &CachedCloudLuaModule{
+					ETag:   `"v1"`,
+					Source: _testCloudLuaModuleURL,
+				})
+				assert.NoError(t, err, "prepare cache")
+			}
+
+			api := cloud.NewMockAPI(gomock.NewController(t))
+			tc.mockFn(api)
+			cloud.DefaultClient = api
+
+			module, pulled, err := PullCloudLuaModule(tc.checksum)
+			if tc.errorReason != "" {
+				assert.Error(t, err, "checking error")
+				assert.Contains(t, err.Error(), tc.errorReason, "checking error reason")
+				return
+			}
+			assert.NoError(t, err, "checking error")
+			assert.Equal(t, tc.pulled, pulled, "checking pulled")
+			assert.Equal(t, checksumOf(tc.want), module.Checksum, "checking checksum")
+			assert.NoError(t, VerifyCloudLuaModule(module), "verify cached module")
+
+			modules, err := ListCloudLuaModules()
+			assert.NoError(t, err, "list cached modules")
+			assert.Equal(t, module.Checksum, modules[0].Checksum, "the pulled one should be the first")
+		})
+	}
+}
+
+func TestImportAndVerifyCloudLuaModule(t *testing.T) {
+	HomeDir = t.TempDir()
+	assert.NoError(t, Init(), "init persistence")
+
+	data := mockCloudLuaModulePackage(t, "bundle")
+	bundle := filepath.Join(t.TempDir(), "cloud_module.tar.gz")
+	assert.NoError(t, os.WriteFile(bundle, data, 0644), "write bundle")
+
+	_, err := ImportCloudLuaModule(bundle, "abcdef")
+	assert.Contains(t, err.Error(), "failed to verify cloud lua module bundle: checksum mismatch", "checking error reason")
+
+	module, err := ImportCloudLuaModule(bundle, checksumOf(data))
+	assert.NoError(t, err, "import bundle")
+	assert.True(t, module.Verified, "checking verified")
+	assert.Equal(t, bundle, module.Source, "checking source")
+	assert.Equal(t, int64(len(data)), module.Size, "checking size")
+	assert.NoError(t, VerifyCloudLuaModule(module), "verify cached module")
+
+	assert.NoError(t, os.WriteFile(module.Path(), []byte("corrupted"), 0644), "corrupt cached module")
+	err = VerifyCloudLuaModule(module)
+	assert.Contains(t, err.Error(), "cloud lua module "+module.ShortChecksum()+" is corrupted", "checking error reason")
+}
+
+func TestSaveCloudLuaModule(t *testing.T) {
+	HomeDir = t.TempDir()
+	assert.NoError(t, Init(), "init persistence")
+
+	data := mockCloudLuaModulePackage(t, "cached")
+	_, err := cacheCloudLuaModule(data, &CachedCloudLuaModule{
+		ETag:   `"v1"`,
+		Source: _testCloudLuaModuleURL,
+	})
+	assert.NoError(t, err, "prepare cache")
+
+	api := cloud.NewMockAPI(gomock.NewController(t))
+	api.EXPECT().GetCloudLuaModule(`"v1"`).Return(nil, errors.New("mock error"))
+	cloud.DefaultClient = api
+
+	// The cached one is used as the cloud lua module cannot be pulled.
+	dir, err := SaveCloudLuaModule("", "")
+	assert.NoError(t, err, "save cloud lua module")
+	assert.Equal(t, filepath.Join(HomeDir, "cloud_lua_module"), dir, "checking module dir")
+	content, err := os.ReadFile(filepath.Join(dir, "cloud.ljbc"))
+	assert.NoError(t, err, "read module file")
+	assert.Equal(t, "cached", string(content), "checking module file")
+
+	// The cached one cannot be used if it doesn't match the checksum.
+	api.EXPECT().GetCloudLuaModule(`"v1"`).Return(nil, errors.New("mock error"))
+	_, err = SaveCloudLuaModule("", sha256Hex([]byte("other")))
+	assert.ErrorContains(t, err, "doesn't match the checksum", "save cloud lua module with checksum")
+
+	bundleData := mockCloudLuaModulePackage(t, "bundle")
+	bundle := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	assert.NoError(t, os.WriteFile(bundle, bundleData, 0644), "write bundle")
+	_, err = SaveCloudLuaModule(bundle, sha256Hex([]byte("other")))
+	assert.ErrorContains(t, err, "checksum mismatch", "save cloud lua module from bundle with wrong checksum")
+	dir, err = SaveCloudLuaModule(bundle, sha256Hex(bundleData))
+	assert.NoError(t, err, "save cloud lua module from bundle")
+	content, err = os.ReadFile(filepath.Join(dir, "cloud.ljbc"))
+	assert.NoError(t, err, "read module file")
+	assert.Equal(t, "bundle", string(content), "checking module file")
+}
+
+func TestExtractCloudLuaModule(t *testing.T) {
+	dir := tar.Header{Name: "cloud_lua_module/", Typeflag: tar.TypeDir, Mode: 0755}
+
+	testCases := []struct {
+		name        string
+		entries     []tarEntry
+		errorReason string
+		files       map[string]string
+	}{
+		{
+			name: "success",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "README", Typeflag: tar.TypeReg}, body: "skipped"},
+				{hdr: tar.Header{Name: "./cloud_lua_module/cloud.ljbc", Typeflag: tar.TypeReg, Mode: 0666}, body: "cloud"},
+				{hdr: tar.Header{Name: "cloud_lua_module/cloud/agent.ljbc", Typeflag: tar.TypeReg}, body: "agent"},
+			},
+			files: map[string]string{
+				"cloud.ljbc":       "cloud",
+				"cloud/agent.ljbc": "agent",
+			},
+		},
+		{
+			name: "path traversal",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/../../evil.ljbc", Typeflag: tar.TypeReg}, body: "evil"},
+			},
+			errorReason: "unsafe entry cloud_lua_module/../../evil.ljbc",
+		},
+		{
+			name: "absolute path",
+			entries: []tarEntry{
+				{hdr: tar.Header{Name: "/tmp/evil.ljbc", Typeflag: tar.TypeReg}, body: "evil"},
+			},
+			errorReason: "unsafe entry /tmp/evil.ljbc",
+		},
+		{
+			name: "symlink",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/cloud.ljbc", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+			},
+			errorReason: "unsupported entry cloud_lua_module/cloud.ljbc",
+		},
+		{
+			name: "hard link",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/cloud.ljbc", Typeflag: tar.TypeLink, Linkname: "../config"}},
+			},
+			errorReason: "unsupported entry cloud_lua_module/cloud.ljbc",
+		},
+		{
+			name: "unexpected top-level directory",
+			entries: []tarEntry{
+				{hdr: tar.Header{Name: "tls/", Typeflag: tar.TypeDir, Mode: 0755}},
+				{hdr: tar.Header{Name: "tls/1/tls.key", Typeflag: tar.TypeReg}, body: "evil"},
+			},
+			errorReason: "unexpected top-level directory tls",
+		},
+		{
+			name: "multiple top-level directories",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "apisix/evil.ljbc", Typeflag: tar.TypeReg}, body: "evil"},
+			},
+			errorReason: "multiple top-level directories cloud_lua_module and apisix",
+		},
+		{
+			name: "file too large",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/cloud.ljbc", Typeflag: tar.TypeReg}, body: strings.Repeat("a", 65)},
+			},
+			errorReason: "file cloud_lua_module/cloud.ljbc (65 bytes) exceeds the size limit (64 bytes)",
+		},
+		{
+			name: "total size too large",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/a.ljbc", Typeflag: tar.TypeReg}, body: strings.Repeat("a", 64)},
+				{hdr: tar.Header{Name: "cloud_lua_module/b.ljbc", Typeflag: tar.TypeReg}, body: strings.Repeat("b", 64)},
+			},
+			errorReason: "the extracted size exceeds the limit (100 bytes)",
+		},
+		{
+			name: "too many entries",
+			entries: []tarEntry{
+				{hdr: dir},
+				{hdr: tar.Header{Name: "cloud_lua_module/a/", Typeflag: tar.TypeDir, Mode: 0755}},
+				{hdr: tar.Header{Name: "cloud_lua_module/b/", Typeflag: tar.TypeDir, Mode: 0755}},
+				{hdr: tar.Header{Name: "cloud_lua_module/c/", Typeflag: tar.TypeDir, Mode: 0755}},
+				{hdr: tar.Header{Name: "cloud_lua_module/d/", Typeflag: tar.TypeDir, Mode: 0755}},
+			},
+			errorReason: "too many entries, the limit is 4",
+		},
+		{
+			name:        "empty",
+			errorReason: "no cloud lua module was found",
+		},
+	}
+
+	defer func(entries int, fileSize, size int64) {
+		_cloudLuaModuleMaxEntries = entries
+		_cloudLuaModuleMaxFileSize = fileSize
+		_cloudLuaModuleMaxSize = size
+	}(_cloudLuaModuleMaxEntries, _cloudLuaModuleMaxFileSize, _cloudLuaModuleMaxSize)
+	_cloudLuaModuleMaxEntries = 4
+	_cloudLuaModuleMaxFileSize = 64
+	_cloudLuaModuleMaxSize = 100
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			HomeDir = t.TempDir()
+			// The module extracted before should be kept if the extraction fails.
+			oldModule := filepath.Join(HomeDir, "cloud_lua_module", "old.ljbc")
+			assert.NoError(t, os.MkdirAll(filepath.Dir(oldModule), 0755), "create old module")
+			assert.NoError(t, os.WriteFile(oldModule, []byte("old"), 0644), "write old module")
+
+			moduleDir, err := extractCloudLuaModule(mockTarGz(t, tc.entries))
+			if tc.errorReason != "" {
+				assert.Error(t, err, "checking error")
+				assert.Contains(t, err.Error(), tc.errorReason, "checking error reason")
+				assert.FileExists(t, oldModule, "the old module should be kept")
+			} else {
+				if !assert.NoError(t, err, "checking error") {
+					return
+				}
+				assert.Equal(t, filepath.Join(HomeDir, "cloud_lua_module"), moduleDir, "checking module dir")
+				assert.NoFileExists(t, oldModule, "the old module should be replaced")
+				for name, content := range tc.files {
+					data, err := os.ReadFile(filepath.Join(moduleDir, name))
+					assert.NoError(t, err, "read file %s", name)
+					assert.Equal(t, content, string(data), "checking file %s", name)
+				}
+				info, err := os.Stat(filepath.Join(moduleDir, "cloud.ljbc"))
+				assert.NoError(t, err, "stat file")
+				assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "checking file mode")
+				assert.NoFileExists(t, filepath.Join(HomeDir, "README"), "files out of the module should be skipped")
+			}
+
+			// Nothing but the module should be left in the HomeDir.
+			entries, err := os.ReadDir(HomeDir)
+			assert.NoError(t, err, "read home dir")
+			assert.Len(t, entries, 1, "checking home dir entries")
+			assert.Equal(t, "cloud_lua_module", entries[0].Name(), "checking home dir entries")
+			_, err = os.Stat(filepath.Join(os.TempDir(), "evil.ljbc"))
+			assert.True(t, os.IsNotExist(err), "nothing should be written out of the home dir")
+		})
+	}
+}
@@ -107,5 +107,21 @@ func CheckConfigurationAndInitCloudClient() error {
 	if err := cloud.InitDefaultClient(profile.Address, profile.User.AccessToken, options.Global.Verbose); err != nil {
 		return fmt.Errorf("Failed to init api7 cloud client: %s", err)
 	}
+
+	org := options.Global.Org
+	if org == "" {
+		org = profile.Org
+	}
+	if org != "" {
+		cloud.DefaultClient.SelectOrganization(org)
+	}
+
+	cluster := options.Global.Cluster
+	if cluster == "" {
+		cluster = profile.Cluster
+	}
+	if cluster != "" {
+		cloud.DefaultClient.SelectCluster(cluster)
+	}
 	return nil
 }
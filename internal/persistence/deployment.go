@@ -0,0 +1,173 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"os"
+	"time"
+
+	sdk "github.com/api7/cloud-go-sdk"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api7/cloud-cli/internal/container"
+)
+
+// DeploymentTarget is the platform that an APISIX instance is deployed on.
+type DeploymentTarget string
+
+const (
+	// DeploymentDocker indicates the APISIX instance runs in a Docker container.
+	DeploymentDocker DeploymentTarget = "docker"
+	// DeploymentKubernetes indicates the APISIX instances are installed by Helm.
+	DeploymentKubernetes DeploymentTarget = "kubernetes"
+	// DeploymentBare indicates the APISIX instance runs on bare metal.
+	DeploymentBare DeploymentTarget = "bare"
+)
+
+// Deployment records an APISIX deployment made by the Cloud CLI.
+type Deployment struct {
+	// Name is the container name (on Docker), the helm release (on
+	// Kubernetes) or "apisix" (on bare metal).
+	Name string `json:"name" yaml:"name"`
+	// Target is where the APISIX instance is deployed.
+	Target DeploymentTarget `json:"target" yaml:"target"`
+	// Namespace is the Kubernetes namespace, it's empty for other targets.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Image is the APISIX image, or the APISIX version on bare metal.
+	Image string `json:"image" yaml:"image"`
+	// APISIXIDs are the IDs of the APISIX instances, there might be
+	// several instances on Kubernetes.
+	APISIXIDs []string `json:"apisix_ids,omitempty" yaml:"apisix_ids,omitempty"`
+	// Runtime is the container runtime (docker, podman or nerdctl) on
+	// Docker, it's empty for other targets.
+	Runtime string `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	// RunArgs are the arguments of the docker run command except the image,
+	// it's empty for other targets.
+	RunArgs []string `json:"run_args,omitempty" yaml:"run_args,omitempty"`
+	// ComposeFile is the Docker Compose file that runs the container if it's
+	// deployed by docker compose up, RunArgs is empty in this case.
+	ComposeFile string `json:"compose_file,omitempty" yaml:"compose_file,omitempty"`
+	// ReplicaOf is the deployment name that the container is a replica of,
+	// it's set if the containers are deployed by deploy docker --replicas.
+	ReplicaOf string `json:"replica_of,omitempty" yaml:"replica_of,omitempty"`
+	// ClusterID is the ID of the API7 Cloud cluster that APISIX connects to.
+	ClusterID sdk.ID `json:"cluster_id" yaml:"cluster_id"`
+	// ConfigHash is the SHA-256 checksum of the APISIX configuration (or
+	// the Helm values on Kubernetes).
+	ConfigHash string `json:"config_hash" yaml:"config_hash"`
+	// DeployedAt is the last time the deployment was made.
+	DeployedAt time.Time `json:"deployed_at" yaml:"deployed_at"`
+}
+
+type deploymentRegistry struct {
+	Deployments []*Deployment `yaml:"deployments"`
+}
+
+// LoadDeployments loads all the recorded deployments, an empty list is
+// returned if nothing has been deployed yet.
+func LoadDeployments() ([]*Deployment, error) {
+	data, err := os.ReadFile(deploymentRegistryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read deployment registry")
+	}
+	var registry deploymentRegistry
+	if err = yaml.Unmarshal(data, &registry); err != nil {
+		return nil, errors.Wrap(err, "failed to decode deployment registry")
+	}
+	return registry.Deployments, nil
+}
+
+// SaveDeployment records the deployment, the existing one with the same
+// target and name (and namespace) is replaced.
+func SaveDeployment(deployment *Deployment) error {
+	deployments, err := LoadDeployments()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, d := range deployments {
+		if d.matches(deployment.Target, deployment.Name, deployment.Namespace) {
+			deployments[i] = deployment
+			replaced = true
+		}
+	}
+	if !replaced {
+		deployments = append(deployments, deployment)
+	}
+	return saveDeployments(deployments)
+}
+
+// RemoveDeployment removes the deployment from the registry, it's not an
+// error if the deployment is not recorded.
+func RemoveDeployment(target DeploymentTarget, name, namespace string) error {
+	deployments, err := LoadDeployments()
+	if err != nil {
+		return err
+	}
+	kept := deployments[:0]
+	for _, d := range deployments {
+		if !d.matches(target, name, namespace) {
+			kept = append(kept, d)
+		}
+	}
+	if len(kept) == len(deployments) {
+		return nil
+	}
+	return saveDeployments(kept)
+}
+
+// DeploymentRuntime returns the specified container runtime, or the one
+// recorded for the Docker deployment, Docker is used if neither of them
+// exists.
+func DeploymentRuntime(runtime, name string) (container.Runtime, error) {
+	if runtime != "" {
+		return container.ParseRuntime(runtime)
+	}
+	deployments, err := LoadDeployments()
+	if err != nil {
+		return "", err
+	}
+	for _, d := range deployments {
+		if d.matches(DeploymentDocker, name, "") {
+			return container.ParseRuntime(d.Runtime)
+		}
+	}
+	return container.Docker, nil
+}
+
+func (d *Deployment) matches(target DeploymentTarget, name, namespace string) bool {
+	return d.Target == target && d.Name == name && d.Namespace == namespace
+}
+
+func saveDeployments(deployments []*Deployment) error {
+	data, err := yaml.Marshal(&deploymentRegistry{Deployments: deployments})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode deployment registry")
+	}
+	// Write to a temporary file first, so that the registry won't be
+	// corrupted if the CLI is interrupted.
+	tempFile := deploymentRegistryFile + ".tmp"
+	if err = os.WriteFile(tempFile, data, 0644); err != nil {
+		return errors.Wrap(err, "failed to save deployment registry")
+	}
+	if err = os.Rename(tempFile, deploymentRegistryFile); err != nil {
+		return errors.Wrap(err, "failed to save deployment registry")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/api7/cloud-cli/internal/container"
+)
+
+func TestDeploymentRegistry(t *testing.T) {
+	dir := t.TempDir()
+	deploymentRegistryFile = filepath.Join(dir, "deployments.yaml")
+
+	deployments, err := LoadDeployments()
+	assert.NoError(t, err, "load deployments from non-existent registry")
+	assert.Empty(t, deployments, "check deployments")
+
+	err = SaveDeployment(&Deployment{Name: "apisix", Target: DeploymentDocker, Image: "apache/apisix:2.15.0-centos", ClusterID: 1})
+	assert.NoError(t, err, "save docker deployment")
+	err = SaveDeployment(&Deployment{Name: "apisix", Target: DeploymentKubernetes, Namespace: "apisix", APISIXIDs: []string{"a", "b"}})
+	assert.NoError(t, err, "save kubernetes deployment")
+	err = SaveDeployment(&Deployment{Name: "apisix", Target: DeploymentDocker, Image: "apache/apisix:3.2.0-centos", ClusterID: 1})
+	assert.NoError(t, err, "redeploy docker deployment")
+
+	deployments, err = LoadDeployments()
+	assert.NoError(t, err, "load deployments")
+	assert.Len(t, deployments, 2, "check deployments")
+	assert.Equal(t, "apache/apisix:3.2.0-centos", deployments[0].Image, "check the replaced deployment")
+	assert.Equal(t, []string{"a", "b"}, deployments[1].APISIXIDs, "check apisix ids")
+
+	err = RemoveDeployment(DeploymentKubernetes, "apisix", "default")
+	assert.NoError(t, err, "remove non-existent deployment")
+	err = RemoveDeployment(DeploymentDocker, "apisix", "")
+	assert.NoError(t, err, "remove docker deployment")
+
+	deployments, err = LoadDeployments()
+	assert.NoError(t, err, "load deployments")
+	assert.Len(t, deployments, 1, "check deployments")
+	assert.Equal(t, DeploymentKubernetes, deployments[0].Target, "check the remaining deployment")
+
+	err = os.WriteFile(deploymentRegistryFile, []byte("deployments: {"), 0644)
+	assert.NoError(t, err, "corrupt registry")
+	_, err = LoadDeployments()
+	assert.ErrorContains(t, err, "failed to decode deployment registry", "load corrupted registry")
+}
+
+func TestDeploymentRuntime(t *testing.T) {
+	dir := t.TempDir()
+	deploymentRegistryFile = filepath.Join(dir, "deployments.yaml")
+
+	err := SaveDeployment(&Deployment{Name: "apisix", Target: DeploymentDocker, Runtime: "podman"})
+	assert.NoError(t, err, "save docker deployment")
+
+	runtime, err := DeploymentRuntime("", "apisix")
+	assert.NoError(t, err, "get the recorded runtime")
+	assert.Equal(t, container.Podman, runtime, "check the recorded runtime")
+
+	runtime, err = DeploymentRuntime("nerdctl", "apisix")
+	assert.NoError(t, err, "get the specified runtime")
+	assert.Equal(t, container.Nerdctl, runtime, "check the specified runtime")
+
+	runtime, err = DeploymentRuntime("", "unknown")
+	assert.NoError(t, err, "get the runtime of unknown deployment")
+	assert.Equal(t, container.Docker, runtime, "check the default runtime")
+
+	_, err = DeploymentRuntime("crio", "apisix")
+	assert.Error(t, err, "get an invalid runtime")
+}
@@ -34,6 +34,12 @@ type Profile struct {
 	Address string `json:"address" yaml:"address"`
 	// User is the user credential.
 	User User `json:"user" yaml:"user"`
+	// Org is the name or ID of the organization to use, the first
+	// organization of the user is used if it's empty.
+	Org string `json:"org,omitempty" yaml:"org,omitempty"`
+	// Cluster is the name or ID of the cluster to use, the first cluster
+	// of the organization is used if it's empty.
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
 }
 
 // CloudConfiguration is the configuration for the cloud cli.
@@ -91,11 +97,17 @@ var (
 	// APISIXConfigDir is the directory to store APISIX configuration file.
 	APISIXConfigDir string
 	configDir       string
+	// deploymentRegistryFile records the APISIX deployments.
+	deploymentRegistryFile string
+	// cloudLuaModuleCacheDir stores the pulled cloud lua modules.
+	cloudLuaModuleCacheDir string
 )
 
 // Init initializes the persistence context.
 func Init() error {
 	configDir = filepath.Join(HomeDir, "config")
+	deploymentRegistryFile = filepath.Join(HomeDir, "deployments.yaml")
+	cloudLuaModuleCacheDir = filepath.Join(HomeDir, "modules")
 
 	TLSDir = filepath.Join(HomeDir, "tls")
 	if err := os.MkdirAll(TLSDir, 0755); err != nil {
@@ -0,0 +1,223 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operator is the operator of a requirement.
+type Operator string
+
+const (
+	// Equals requires the key to have the value.
+	Equals = Operator("=")
+	// NotEquals requires the key to not have the value.
+	NotEquals = Operator("!=")
+	// In requires the key to have one of the values.
+	In = Operator("in")
+)
+
+// Requirement is a single condition of a selector, like "team=payments".
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Selector selects resources by their labels, all the requirements should
+// be satisfied. Labels are in the format of "key=value", a label without
+// "=" is treated as a key with the empty value.
+type Selector []Requirement
+
+// FieldSelector selects resources by their fields, all the requirements
+// should be satisfied.
+type FieldSelector []Requirement
+
+// Parse parses the label selector expression, requirements are separated
+// by comma, and each of them can be:
+//   - key=value
+//   - key!=value
+//   - key in (value1,value2)
+func Parse(expr string) (Selector, error) {
+	reqs, err := parse(expr, true)
+	if err != nil {
+		return nil, err
+	}
+	return Selector(reqs), nil
+}
+
+// _caseInsensitiveFields are the fields whose values are aliases like
+// "normal", they're lowercased so that "status=Normal" also works, the
+// representations of these fields passed to Matches should be lowercase.
+var _caseInsensitiveFields = map[string]struct{}{
+	"status": {},
+}
+
+// ParseFields parses the field selector expression, requirements are
+// separated by comma, and each of them can be "field=value" or
+// "field!=value".
+func ParseFields(expr string) (FieldSelector, error) {
+	reqs, err := parse(expr, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reqs {
+		if _, ok := _caseInsensitiveFields[reqs[i].Key]; ok {
+			for j := range reqs[i].Values {
+				reqs[i].Values[j] = strings.ToLower(reqs[i].Values[j])
+			}
+		}
+	}
+	return FieldSelector(reqs), nil
+}
+
+// Matches checks if the labels satisfy the selector.
+func (s Selector) Matches(labels []string) bool {
+	values := make(map[string][]string, len(labels))
+	for _, label := range labels {
+		key, value, _ := strings.Cut(label, "=")
+		key = strings.TrimSpace(key)
+		values[key] = append(values[key], strings.TrimSpace(value))
+	}
+	for _, req := range s {
+		if !req.matches(values[req.Key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches checks if the fields satisfy the field selector, a field may
+// have several representations, e.g., the status can be "50" or "normal".
+// Unknown fields never match.
+func (s FieldSelector) Matches(fields map[string][]string) bool {
+	for _, req := range s {
+		values, ok := fields[req.Key]
+		if !ok || !req.matches(values) {
+			return false
+		}
+	}
+	return true
+}
+
+// Value returns the value of the first equality requirement on the field.
+func (s FieldSelector) Value(field string) (string, bool) {
+	for _, req := range s {
+		if req.Key == field && req.Operator == Equals {
+			return req.Values[0], true
+		}
+	}
+	return "", false
+}
+
+func (r Requirement) matches(values []string) bool {
+	found := false
+	for _, value := range values {
+		for _, want := range r.Values {
+			if value == want {
+				found = true
+			}
+		}
+	}
+	if r.Operator == NotEquals {
+		return !found
+	}
+	return found
+}
+
+func parse(expr string, allowIn bool) ([]Requirement, error) {
+	var reqs []Requirement
+	for _, term := range split(expr) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		req, err := parseRequirement(term, allowIn)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// split splits the expression by comma, commas inside parentheses are
+// kept.
+func split(expr string) []string {
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, expr[start:])
+}
+
+func parseRequirement(term string, allowIn bool) (Requirement, error) {
+	if key, value, ok := strings.Cut(term, "!="); ok {
+		return newRequirement(term, key, NotEquals, value)
+	}
+	if key, value, ok := strings.Cut(term, "="); ok {
+		return newRequirement(term, key, Equals, strings.TrimPrefix(value, "="))
+	}
+	if allowIn {
+		if key, list, ok := strings.Cut(term, " in "); ok {
+			list = strings.TrimSpace(list)
+			if !strings.HasPrefix(list, "(") || !strings.HasSuffix(list, ")") {
+				return Requirement{}, fmt.Errorf("invalid requirement \"%s\": values should be enclosed in parentheses", term)
+			}
+			req := Requirement{
+				Key:      strings.TrimSpace(key),
+				Operator: In,
+			}
+			for _, value := range strings.Split(list[1:len(list)-1], ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					req.Values = append(req.Values, value)
+				}
+			}
+			if req.Key == "" || len(req.Values) == 0 {
+				return Requirement{}, fmt.Errorf("invalid requirement \"%s\"", term)
+			}
+			return req, nil
+		}
+	}
+	return Requirement{}, fmt.Errorf("invalid requirement \"%s\"", term)
+}
+
+func newRequirement(term, key string, op Operator, value string) (Requirement, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return Requirement{}, fmt.Errorf("invalid requirement \"%s\": empty key", term)
+	}
+	return Requirement{
+		Key:      key,
+		Operator: op,
+		Values:   []string{strings.TrimSpace(value)},
+	}, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 API7.ai, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelector(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expr      string
+		labels    []string
+		matched   bool
+		errReason string
+	}{
+		{
+			name:    "equals",
+			expr:    "team=payments",
+			labels:  []string{"team=payments", "prod"},
+			matched: true,
+		},
+		{
+			name:    "equals mismatched",
+			expr:    "team=payments",
+			labels:  []string{"team=orders"},
+			matched: false,
+		},
+		{
+			name:    "equals is case sensitive",
+			expr:    "team=Payments",
+			labels:  []string{"team=payments"},
+			matched: false,
+		},
+		{
+			name:    "not equals",
+			expr:    "team!=payments, env=prod",
+			labels:  []string{"team=orders", "env=prod"},
+			matched: true,
+		},
+		{
+			name:    "not equals with missing key",
+			expr:    "team!=payments",
+			matched: true,
+		},
+		{
+			name:    "in",
+			expr:    "team in (payments, orders),env=prod",
+			labels:  []string{"team=orders", "env=prod"},
+			matched: true,
+		},
+		{
+			name:    "in mismatched",
+			expr:    "team in (payments,orders)",
+			labels:  []string{"team=users"},
+			matched: false,
+		},
+		{
+			name:    "label without value",
+			expr:    "prod=",
+			labels:  []string{"prod"},
+			matched: true,
+		},
+		{
+			name:      "invalid in",
+			expr:      "team in payments",
+			errReason: "invalid requirement \"team in payments\": values should be enclosed in parentheses",
+		},
+		{
+			name:      "empty key",
+			expr:      "=payments",
+			errReason: "invalid requirement \"=payments\": empty key",
+		},
+		{
+			name:      "unknown operator",
+			expr:      "team",
+			errReason: "invalid requirement \"team\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if tc.errReason != "" {
+				assert.EqualError(t, err, tc.errReason, "check error")
+				return
+			}
+			assert.NoError(t, err, "check error")
+			assert.Equal(t, tc.matched, s.Matches(tc.labels), "check if labels are matched")
+		})
+	}
+}
+
+func TestFieldSelector(t *testing.T) {
+	fields := map[string][]string{
+		"name":   {"users"},
+		"status": {"50", "normal"},
+	}
+
+	s, err := ParseFields("name=users,status=Normal")
+	assert.NoError(t, err, "check error")
+	assert.True(t, s.Matches(fields), "check if fields are matched")
+
+	name, ok := s.Value("name")
+	assert.True(t, ok, "check if name is found")
+	assert.Equal(t, "users", name, "check name")
+
+	s, err = ParseFields("name=Users")
+	assert.NoError(t, err, "check error")
+	assert.False(t, s.Matches(fields), "check if names are compared exactly")
+
+	s, err = ParseFields("status!=50")
+	assert.NoError(t, err, "check error")
+	assert.False(t, s.Matches(fields), "check if fields are matched")
+
+	s, err = ParseFields("owner=jack")
+	assert.NoError(t, err, "check error")
+	assert.False(t, s.Matches(fields), "check if unknown fields are matched")
+
+	_, err = ParseFields("status in (50)")
+	assert.EqualError(t, err, "invalid requirement \"status in (50)\"", "check error")
+}
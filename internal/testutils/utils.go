@@ -18,8 +18,10 @@ package testutils
 import (
 	"testing"
 
+	sdk "github.com/api7/cloud-go-sdk"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/api7/cloud-cli/internal/cloud"
 	"github.com/api7/cloud-cli/internal/persistence"
 )
 
@@ -37,3 +39,31 @@ func PrepareFakeConfiguration(t *testing.T) {
 	})
 	assert.NoError(t, err, "prepare fake cloud configuration")
 }
+
+// Iterate returns a function which calls fn on each of the objects, it's
+// used to mock the cloud.API Iterate methods, e.g. IterateServices.
+func Iterate[T any](objs ...*T) func(sdk.ID, *sdk.Filter, func(*T) error) error {
+	return func(_ sdk.ID, _ *sdk.Filter, fn func(*T) error) error {
+		return iterate(objs, fn)
+	}
+}
+
+// IterateRoutes returns a function which calls fn on each of the routes,
+// it's used to mock the cloud.API IterateRoutes method.
+func IterateRoutes(routes ...*sdk.API) func(sdk.ID, sdk.ID, *sdk.Filter, func(*sdk.API) error) error {
+	return func(_ sdk.ID, _ sdk.ID, _ *sdk.Filter, fn func(*sdk.API) error) error {
+		return iterate(routes, fn)
+	}
+}
+
+func iterate[T any](objs []*T, fn func(*T) error) error {
+	for _, obj := range objs {
+		if err := fn(obj); err != nil {
+			if err == cloud.ErrStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -40,11 +40,10 @@ func GetDeploymentName(kubectl commands.Cmd) (string, error) {
 	return stdout, nil
 }
 
-// GetPodsNames get the pod names for APISIX instance
-func GetPodsNames(kubectl commands.Cmd) ([]string, error) {
-	deployOpts := options.Global.Deploy
-	kubectl.AppendArgs("get", "pods", "-n", deployOpts.Kubernetes.Namespace)
-	kubectl.AppendArgs("-l", fmt.Sprintf("app.kubernetes.io/instance=%s", deployOpts.Name))
+// GetPodsNames get the pod names of the APISIX release in the namespace
+func GetPodsNames(kubectl commands.Cmd, release, namespace string) ([]string, error) {
+	kubectl.AppendArgs("get", "pods", "-n", namespace)
+	kubectl.AppendArgs("-l", fmt.Sprintf("app.kubernetes.io/instance=%s", release))
 	kubectl.AppendArgs("-o", "jsonpath=\"{.items[*].metadata.name}\"")
 	stdout, err := runKubectl(kubectl)
 	if err != nil {
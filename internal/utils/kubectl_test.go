@@ -113,7 +113,7 @@ func TestGetPodsNames(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.mockFn(t, &tc)
-			rsp, err := GetPodsNames(tc.kubectl)
+			rsp, err := GetPodsNames(tc.kubectl, "apisix", "apisix")
 			if tc.errorReason != "" {
 				assert.Contains(t, err.Error(), tc.errorReason, "check error")
 			} else {
@@ -25,17 +25,38 @@ import (
 // CheckIfCertificateIsExpired checks whether the certificate is expired or not.
 // Note this function accepts the certificate as a byte array (in PEM format).
 func CheckIfCertificateIsExpired(data []byte) (bool, error) {
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return false, err
+	}
+	if cert.NotAfter.Before(time.Now()) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// ParseCertificate parses the certificate (in PEM format).
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {
-		return false, errors.New("failed to decode certificate from PEM")
+		return nil, errors.New("failed to decode certificate from PEM")
 	}
 
 	cert, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to parse certificate")
+		return nil, errors.Wrap(err, "failed to parse certificate")
 	}
-	if cert.NotAfter.Before(time.Now()) {
-		return true, nil
+	return cert, nil
+}
+
+// CertificateSNIs returns the SNIs of the certificate, the subject common
+// name is used if the certificate doesn't have DNS names.
+func CertificateSNIs(cert *x509.Certificate) []string {
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames
 	}
-	return false, nil
+	if cert.Subject.CommonName != "" {
+		return []string{cert.Subject.CommonName}
+	}
+	return nil
 }
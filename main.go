@@ -19,12 +19,21 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/api7/cloud-cli/cmd/apply"
 	"github.com/api7/cloud-cli/cmd/config"
 	"github.com/api7/cloud-cli/cmd/configure"
 	"github.com/api7/cloud-cli/cmd/debug"
 	"github.com/api7/cloud-cli/cmd/deploy"
+	"github.com/api7/cloud-cli/cmd/diff"
+	"github.com/api7/cloud-cli/cmd/export"
+	"github.com/api7/cloud-cli/cmd/importer"
+	"github.com/api7/cloud-cli/cmd/logs"
+	"github.com/api7/cloud-cli/cmd/module"
 	"github.com/api7/cloud-cli/cmd/resource"
+	"github.com/api7/cloud-cli/cmd/rollback"
+	"github.com/api7/cloud-cli/cmd/status"
 	"github.com/api7/cloud-cli/cmd/stop"
+	"github.com/api7/cloud-cli/cmd/upgrade"
 	"github.com/api7/cloud-cli/internal/options"
 	"github.com/api7/cloud-cli/internal/output"
 	"github.com/api7/cloud-cli/internal/utils"
@@ -39,13 +48,25 @@ func newCommand() *cobra.Command {
 	}
 	cmd.PersistentFlags().BoolVar(&options.Global.Verbose, "verbose", false, "Enable verbose output")
 	cmd.PersistentFlags().BoolVar(&options.Global.DryRun, "dry-run", false, "Enable dry run mode")
+	cmd.PersistentFlags().StringVar(&options.Global.Org, "org", "", "The name or ID of the organization to use, it overrides the organization of the profile")
+	cmd.PersistentFlags().StringVar(&options.Global.Cluster, "cluster", "", "The name or ID of the cluster to use, it overrides the cluster of the profile")
+	cmd.PersistentFlags().StringVarP(&options.Global.Output, "output", "o", "", "Output format, one of: json, yaml, table, wide, name, jsonpath=<template>, go-template=<template>")
 
 	cmd.AddCommand(deploy.NewCommand())
 	cmd.AddCommand(configure.NewCommand())
 	cmd.AddCommand(stop.NewStopCommand())
+	cmd.AddCommand(status.NewCommand())
+	cmd.AddCommand(logs.NewCommand())
+	cmd.AddCommand(upgrade.NewCommand())
+	cmd.AddCommand(rollback.NewCommand())
+	cmd.AddCommand(module.NewCommand())
 	cmd.AddCommand(debug.NewCommand())
 	cmd.AddCommand(config.NewCommand())
 	cmd.AddCommand(resource.NewCommand())
+	cmd.AddCommand(apply.NewCommand())
+	cmd.AddCommand(diff.NewCommand())
+	cmd.AddCommand(export.NewCommand())
+	cmd.AddCommand(importer.NewCommand())
 
 	return cmd
 }